@@ -1,61 +1,318 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"expvar"
+	"github.com/XSAM/otelsql"
 	"github.com/artback/mvp/internal/config"
+	"github.com/artback/mvp/pkg/alert"
 	"github.com/artback/mvp/pkg/api/graceful"
 	"github.com/artback/mvp/pkg/api/handler"
+	"github.com/artback/mvp/pkg/api/middleware/security/basic"
+	"github.com/artback/mvp/pkg/api/tlsconfig"
+	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/health"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/artback/mvp/pkg/metrics"
+	"github.com/artback/mvp/pkg/notify"
+	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/reload"
+	"github.com/artback/mvp/pkg/repository/postgres"
+	"github.com/artback/mvp/pkg/tracing"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/sirupsen/logrus"
 	flag "github.com/spf13/pflag"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"google.golang.org/grpc"
+	grpccredentials "google.golang.org/grpc/credentials"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"time"
 )
 
-const timeout = 5 * time.Second
-
 func main() {
-	host := flag.String("http-host", ":7070", "http host")
-	coins := flag.IntSlice("coins", []int{5, 10, 20, 50, 100}, "coins")
+	config.RegisterFlags(flag.CommandLine)
+	printConfig := flag.Bool("print-config", false, "print the configuration with its secrets redacted and exit")
 	flag.Parse()
 
-	c, err := config.LoadConfig()
+	c, err := config.Load(flag.CommandLine)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *printConfig {
+		if err := c.Redacted().WriteYAML(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	db, err := sql.Open("postgres", c.ConnectionString())
+	l, err := logger.New(os.Stderr, logger.Options{Format: c.Log.Format, Level: c.Log.Level})
 	if err != nil {
 		log.Fatal(err)
 	}
+	logger.SetDefault(l)
+	// lines of libraries using the standard logger get the format of ours
+	log.SetFlags(0)
+	log.SetOutput(l.WriterLevel(logrus.InfoLevel))
+
+	hasher := pass.Hasher{Scheme: pass.Bcrypt{Cost: c.Auth.Password.BcryptCost}}
+	if c.Auth.Password.Hash == "argon2id" {
+		argon2id := pass.DefaultArgon2id
+		argon2id.Time, argon2id.Memory, argon2id.Threads = c.Auth.Password.Argon2Time, c.Auth.Password.Argon2Memory, c.Auth.Password.Argon2Threads
+		hasher.Scheme = argon2id
+	}
+	var notifier notify.Notifier = notify.Log{}
+	if c.Notifier.Kind == "file" {
+		notifier = &notify.File{Path: c.Notifier.File}
+	}
+	passwordPolicy := pass.Policy{MinLength: c.Auth.Password.MinLength, MaxLength: c.Auth.Password.MaxLength}
+	if c.Auth.Password.Blocklist != "" {
+		if passwordPolicy.Blocklist, err = pass.LoadBlocklist(c.Auth.Password.Blocklist); err != nil {
+			l.Fatal(err)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Service:     "mvp",
+		Exporter:    c.Trace.Exporter,
+		Endpoint:    c.Trace.Endpoint,
+		Insecure:    c.Trace.Insecure,
+		Path:        c.Trace.File,
+		SampleRatio: c.Trace.SampleRatio,
+	})
+	if err != nil {
+		l.Fatal(err)
+	}
 
-	router, err := handler.HttpRouter(db, *coins)
+	// every query gets a span below the request running it
+	db, err := otelsql.Open("postgres", c.ConnectionString(), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
 	if err != nil {
-		log.Fatal(err)
+		l.Fatal(err)
 	}
+	db.SetMaxOpenConns(c.Database.MaxOpenConns)
+	db.SetMaxIdleConns(c.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(c.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(c.Database.ConnMaxIdleTime)
 
-	server := graceful.Server{
-		Server: &http.Server{
-			Addr:         *host,
-			Handler:      router,
-			ReadTimeout:  timeout,
-			WriteTimeout: timeout,
-		},
+	enforcer, watcher, err := newEnforcer(db, c.ConnectionString(), c.Auth.Model, c.Auth.Policy)
+	if err != nil {
+		l.Fatal(err)
+	}
+	// the stored rules stand for the file as it is now, only later edits of it are stored
+	policyFile := &policy.File{
+		Model:      c.Auth.Model,
+		Path:       c.Auth.Policy,
+		Repository: postgres.PolicyRepository{DB: db},
+		Enforcer:   enforcer,
+		Notifier:   watcher,
+	}
+	if err := policyFile.Applied(); err != nil {
+		l.Fatal(err)
+	}
+	coins, err := coin.NewSet(c.Coins)
+	if err != nil {
+		l.Fatal(err)
 	}
-	server.RegisterOnShutdown(func() {
-		err := db.Close()
+	// SIGHUP and POST /reload on the admin host read the settings again, the coins and the edited policy file
+	// are swapped in, everything else needs a restart
+	reloader := reload.New(func(ctx context.Context) error {
+		next, err := config.Load(flag.CommandLine)
 		if err != nil {
-			log.Printf("Database closed with: %v", err)
+			return err
+		}
+		if err := c.CheckReload(next); err != nil {
+			return err
+		}
+		changed, err := policyFile.Reload(ctx)
+		if err != nil {
+			return err
+		}
+		if err := coins.Replace(next.Coins); err != nil {
+			return err
+		}
+		logger.From(ctx).WithFields(logrus.Fields{"coins": next.Coins, "policy_changed": changed}).Info("settings reloaded")
+		return nil
+	})
+
+	authCache, err := basic.NewCache(c.Auth.CacheSize, c.Auth.CacheTTL)
+	if err != nil {
+		l.Fatal(err)
+	}
+	expvar.Publish("auth_cache", expvar.Func(authCache.Stats))
+	m := metrics.New(db)
+	m.AuthCache(authCache)
+	credentials, err := postgres.NewCredentialWatcher(db, c.ConnectionString(), authCache)
+	if err != nil {
+		l.Fatal(err)
+	}
+
+	bus := events.NewBus(nil)
+	eventWatcher, err := postgres.NewEventWatcher(db, c.ConnectionString(), bus)
+	if err != nil {
+		l.Fatal(err)
+	}
+	bus.Relay = eventWatcher
+
+	options := handler.Options{
+		Coins: coins,
+		Lockout: lockout.Policy{
+			MaxFailures: c.Auth.Lockout.MaxFailures,
+			BaseDelay:   c.Auth.Lockout.BaseDelay,
+			MaxDelay:    c.Auth.Lockout.MaxDelay,
+			LockoutFor:  c.Auth.Lockout.Duration,
+			Window:      c.Auth.Lockout.Window,
+		},
+		AuthCache:      authCache,
+		Invalidator:    credentials,
+		Hasher:         hasher,
+		PasswordPolicy: passwordPolicy,
+		Notifier:       notifier,
+		ResetTTL:       c.Auth.ResetTokenTTL,
+		Resets:         handler.NewResetQueue(),
+		PolicyNotifier: watcher,
+		Events:         bus,
+		RequestTimeout: c.HTTP.RequestTimeout,
+		Metrics:        m,
+		Logger:         l,
+		Drain:          &health.Drain{},
+	}
+	router, err := handler.HttpRouter(db, enforcer, options)
+	if err != nil {
+		l.Fatal(err)
+	}
+	tlsConfig, err := tlsconfig.New(tlsconfig.Options{
+		CertFile:       c.TLS.Cert,
+		KeyFile:        c.TLS.Key,
+		MinVersion:     c.TLS.MinVersion,
+		ClientCAFile:   c.TLS.ClientCA,
+		ReloadInterval: c.TLS.ReloadInterval,
+	})
+	if err != nil {
+		l.Fatal(err)
+	}
+
+	server := graceful.Server{OnSignal: options.Drain.Start, DrainDelay: c.Shutdown.DrainDelay, Timeout: c.Shutdown.Timeout}
+	api := &http.Server{
+		Addr:        c.HTTP.Host,
+		Handler:     router,
+		ReadTimeout: c.HTTP.ReadTimeout,
+		TLSConfig:   tlsConfig,
+		// no WriteTimeout, it would cut off event streams, RequestTimeout bounds the other requests
+	}
+	// open event streams would keep the shutdown waiting until it times out
+	api.RegisterOnShutdown(bus.Close)
+	server.Listen("http", graceful.HTTP{Server: api})
+
+	if c.Admin.Host != "" {
+		if !loopback(c.Admin.Host) {
+			l.WithField("admin_host", c.Admin.Host).Warn("admin host serves /metrics and /reload without authentication beyond loopback")
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", options.Metrics.Handler())
+		mux.Handle("/reload", reloader)
+		server.Listen("admin", graceful.HTTP{
+			Server: &http.Server{Addr: c.Admin.Host, Handler: mux, ReadTimeout: c.HTTP.ReadTimeout, WriteTimeout: c.HTTP.ReadTimeout},
+		})
+	}
+
+	if c.GRPC.Host != "" {
+		var opts []grpc.ServerOption
+		if tlsConfig != nil {
+			opts = append(opts, grpc.Creds(grpccredentials.NewTLS(tlsConfig)))
 		}
+		server.Listen("grpc", graceful.GRPC{Server: handler.GrpcServer(db, enforcer, options, opts...), Addr: c.GRPC.Host})
+	}
+
+	// the lease outlasts the client timeout, a delivery is only claimed again once its attempt surely ended
+	dispatcher := webhook.Dispatcher{
+		Repository: postgres.WebhookRepository{DB: db},
+		Client:     webhook.NewClient(c.Webhook.Timeout),
+		Retry:      webhook.Retry{MaxAttempts: c.Webhook.MaxAttempts, BaseDelay: c.Webhook.BaseDelay, MaxDelay: c.Webhook.MaxDelay},
+		Batch:      20,
+		Interval:   c.Webhook.Interval,
+		Lease:      2 * c.Webhook.Timeout,
+	}
+	sender := alert.Sender{
+		Repository: postgres.AlertRepository{DB: db},
+		Notifier:   notifier,
+		Batch:      20,
+		Interval:   c.Alert.Interval,
+	}
+	workers := logger.NewContext(context.Background(), l.WithField("component", "worker"))
+	server.Listen("webhooks", graceful.NewWorker(workers, dispatcher.Run))
+	server.Listen("alerts", graceful.NewWorker(workers, sender.Run))
+	server.Listen("reload", graceful.NewWorker(workers, reloader.OnHangup))
+	server.Listen("resets", graceful.NewWorker(workers, options.Resets.Run))
+
+	// the hooks run once nothing uses the database anymore, in this order
+	server.RegisterOnShutdown("watchers", func(context.Context) error {
+		eventWatcher.Close()
+		watcher.Close()
+		credentials.Close()
+		return nil
 	})
+	server.RegisterOnShutdown("database", func(context.Context) error {
+		return db.Close()
+	})
+	server.RegisterOnShutdown("tracing", shutdownTracing)
+
+	if err := server.Run(context.Background()); err != nil {
+		l.WithError(err).Error("shutdown failed")
+		os.Exit(1)
+	}
+}
+
+// loopback tells if host only listens on the loopback interface
+func loopback(host string) bool {
+	name, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return false
+	}
+	if name == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(name)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// newEnforcer loads the policy from the database and keeps it in sync with the other replicas.
+// An empty database is seeded with the policy file, by one replica when several start at once.
+func newEnforcer(db *sql.DB, connectionString, modelFile, policy string) (*casbin.SyncedEnforcer, *postgres.PolicyWatcher, error) {
+	adapter := postgres.PolicyAdapter{DB: db}
+	e, err := casbin.NewSyncedEnforcer(modelFile, adapter)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if err := server.ListenAndServe(); err != nil {
-		if !errors.Is(err, http.ErrServerClosed) {
-			log.Printf("HTTP server closed with: %v", err)
-			os.Exit(1)
+	if len(e.GetPolicy()) == 0 {
+		m, err := model.NewModelFromFile(modelFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := fileadapter.NewAdapter(policy).LoadPolicy(m); err != nil {
+			return nil, nil, err
+		}
+		if _, err := adapter.Seed(m); err != nil {
+			return nil, nil, err
 		}
+		if err := e.LoadPolicy(); err != nil {
+			return nil, nil, err
+		}
+	}
 
-		log.Print("HTTP server shut down")
+	watcher, err := postgres.NewPolicyWatcher(db, connectionString)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	return e, watcher, e.SetWatcher(watcher)
 }
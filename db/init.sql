@@ -1,9 +1,50 @@
 CREATE TABLE users
 (
-    username text primary key,
-    password text NOT NULL,
-    role     text DEFAULT 'buyer',
-    deposit  int  DEFAULT 0
+    username       text primary key,
+    password       text NOT NULL,
+    role           text    DEFAULT 'buyer',
+    deposit        int     DEFAULT 0,
+    locked         boolean DEFAULT false,
+    reset_required boolean DEFAULT false,
+    contact        text
+);
+
+CREATE TABLE password_resets
+(
+    token_hash text primary key,
+    username   text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    CONSTRAINT fk_username
+        FOREIGN KEY (username)
+            REFERENCES users (username) ON DELETE CASCADE
+);
+
+CREATE TABLE role_requests
+(
+    id         serial primary key,
+    username   text NOT NULL,
+    role       text NOT NULL,
+    status     text DEFAULT 'pending',
+    created_at timestamptz DEFAULT now(),
+    decided_by text,
+    decided_at timestamptz,
+    CONSTRAINT fk_username
+        FOREIGN KEY (username)
+            REFERENCES users (username) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX role_requests_pending ON role_requests (username) WHERE status = 'pending';
+
+CREATE TABLE audit_log
+(
+    id         serial primary key,
+    actor      text NOT NULL,
+    action     text NOT NULL,
+    target     text NOT NULL,
+    reason     text NOT NULL,
+    detail     text,
+    created_at timestamptz DEFAULT now()
 );
 
 
@@ -41,12 +82,16 @@ DECLARE
 BEGIN
     SELECT amount, price into inventory_amount,product_price from inventory where product_name = NEW.product_name;
     if NEW.amount > inventory_amount then
-        RAISE EXCEPTION 'amount is larger than inventory';
+        RAISE EXCEPTION 'amount is larger than inventory'
+            USING ERRCODE = 'MV001',
+                DETAIL = json_build_object('product', NEW.product_name, 'requested', NEW.amount, 'available', inventory_amount)::text;
     end if;
     SELECT deposit into user_deposit from users where username = NEW.username;
     NEW.price = product_price;
     if NEW.amount * NEW.price > user_deposit THEN
-        RAISE EXCEPTION 'cost is higher than deposit';
+        RAISE EXCEPTION 'cost is higher than deposit'
+            USING ERRCODE = 'MV002',
+                DETAIL = json_build_object('cost', NEW.amount * NEW.price, 'deposit', user_deposit)::text;
     end if;
 
     UPDATE inventory SET amount = amount - new.amount WHERE product_name = NEW.product_name;
@@ -64,11 +109,170 @@ EXECUTE PROCEDURE update_inventory();
 
 CREATE TABLE inventory
 (
-    id           serial primary key,
-    product_name text unique,
-    amount       INT,
-    price        int,
+    id                serial primary key,
+    product_name      text unique,
+    amount            INT,
+    price             int,
+    reorder_threshold int NOT NULL DEFAULT 0,
     CONSTRAINT fk_product_name
         FOREIGN KEY (product_name)
             REFERENCES products (name) on delete cascade
 );
+
+CREATE TABLE casbin_rule
+(
+    id    serial primary key,
+    ptype text NOT NULL,
+    v0    text NOT NULL DEFAULT '',
+    v1    text NOT NULL DEFAULT '',
+    v2    text NOT NULL DEFAULT '',
+    v3    text NOT NULL DEFAULT '',
+    v4    text NOT NULL DEFAULT '',
+    v5    text NOT NULL DEFAULT '',
+    CONSTRAINT casbin_rule_unique UNIQUE (ptype, v0, v1, v2, v3, v4, v5)
+);
+
+CREATE TABLE login_attempts
+(
+    key          text primary key,
+    failures     int NOT NULL,
+    last_failure timestamptz NOT NULL
+);
+
+
+CREATE TABLE outbox
+(
+    id         serial primary key,
+    seller_id  text NOT NULL,
+    kind       text NOT NULL,
+    data       jsonb NOT NULL,
+    created_at timestamptz DEFAULT now()
+);
+
+CREATE TABLE webhook_endpoints
+(
+    id         serial primary key,
+    seller_id  text NOT NULL,
+    url        text NOT NULL,
+    events     text[] NOT NULL DEFAULT '{}',
+    secret     text NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    CONSTRAINT fk_seller
+        FOREIGN KEY (seller_id)
+            REFERENCES users (username) ON DELETE CASCADE
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              serial primary key,
+    endpoint_id     int NOT NULL,
+    event_id        int NOT NULL,
+    status          text NOT NULL DEFAULT 'pending',
+    attempts        int NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT fk_endpoint
+        FOREIGN KEY (endpoint_id)
+            REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    CONSTRAINT fk_event
+        FOREIGN KEY (event_id)
+            REFERENCES outbox (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE webhook_attempts
+(
+    id           serial primary key,
+    delivery_id  int NOT NULL,
+    status_code  int,
+    error        text,
+    duration_ms  int NOT NULL,
+    attempted_at timestamptz NOT NULL,
+    CONSTRAINT fk_delivery
+        FOREIGN KEY (delivery_id)
+            REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+);
+
+CREATE TABLE stock_alerts
+(
+    id              serial primary key,
+    product_name    text NOT NULL,
+    seller_id       text NOT NULL,
+    kind            text NOT NULL,
+    remaining       int NOT NULL,
+    threshold       int NOT NULL,
+    status          text NOT NULL DEFAULT 'open',
+    notified_at     timestamptz,
+    created_at      timestamptz DEFAULT now(),
+    acknowledged_at timestamptz,
+    CONSTRAINT fk_product_name
+        FOREIGN KEY (product_name)
+            REFERENCES products (name) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX stock_alerts_open ON stock_alerts (product_name, kind) WHERE status = 'open';
+CREATE INDEX stock_alerts_unnotified ON stock_alerts (id) WHERE notified_at IS NULL;
+
+-- raise_stock_alert opens an alert unless the product has an open alert of its kind already,
+-- and tells the webhook endpoints of the seller about it
+CREATE FUNCTION raise_stock_alert(alert_product text, alert_seller text, alert_kind text, alert_remaining int,
+                                  alert_threshold int, alert_data jsonb) RETURNS void AS
+$raise_stock_alert$
+DECLARE
+    -- the webhook event kind of the alert, product.low_stock or product.sold_out
+    event_kind text := 'product.' || alert_kind;
+BEGIN
+    INSERT INTO stock_alerts (product_name, seller_id, kind, remaining, threshold)
+    VALUES (alert_product, alert_seller, alert_kind, alert_remaining, alert_threshold)
+    ON CONFLICT (product_name, kind) WHERE status = 'open' DO NOTHING;
+
+    WITH event AS (INSERT INTO outbox (seller_id, kind, data) VALUES (alert_seller, event_kind, alert_data) RETURNING id)
+    INSERT INTO webhook_deliveries (endpoint_id, event_id)
+    SELECT e.id, event.id FROM event, webhook_endpoints e
+    WHERE e.seller_id = alert_seller AND (cardinality(e.events) = 0 OR event_kind = ANY (e.events));
+END
+$raise_stock_alert$ LANGUAGE plpgsql;
+
+-- check_stock raises the alerts of every change to the stock or the reorder threshold of a product, whoever makes it:
+-- low stock once the stock is at or below a threshold above 0 and wasn't before, sold out once the last item is gone
+CREATE FUNCTION check_stock() RETURNS trigger AS
+$check_stock$
+DECLARE
+    seller text;
+BEGIN
+    SELECT seller_id INTO seller FROM products WHERE name = NEW.product_name;
+    if NOT FOUND then
+        RETURN NULL;
+    end if;
+    if NEW.reorder_threshold > 0 AND NEW.amount <= NEW.reorder_threshold
+        AND NOT (OLD.reorder_threshold > 0 AND OLD.amount <= OLD.reorder_threshold) then
+        PERFORM raise_stock_alert(NEW.product_name, seller, 'low_stock', NEW.amount, NEW.reorder_threshold,
+                                  jsonb_build_object('product', NEW.product_name, 'remaining', NEW.amount,
+                                                     'threshold', NEW.reorder_threshold));
+    end if;
+    if OLD.amount > 0 AND NEW.amount = 0 then
+        PERFORM raise_stock_alert(NEW.product_name, seller, 'sold_out', NEW.amount, NEW.reorder_threshold,
+                                  jsonb_build_object('product', NEW.product_name));
+    end if;
+    RETURN NULL;
+END
+$check_stock$ LANGUAGE plpgsql;
+
+-- deferred to the commit, so the events of a purchase follow the sold event written after the stock changed
+CREATE CONSTRAINT TRIGGER check_stock
+    AFTER UPDATE OF amount, reorder_threshold
+    ON inventory
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE PROCEDURE check_stock();
+
+-- one row for every version of this file, postgres.SchemaVersion is the version the service expects.
+-- db/migrations/NNN_*.sql bring a database set up from an older version up to version NNN, 001 starts from the
+-- schema that had no schema_version yet.
+CREATE TABLE schema_version
+(
+    version    int primary key,
+    applied_at timestamptz DEFAULT now()
+);
+
+INSERT INTO schema_version (version) VALUES (1), (2), (3);
@@ -0,0 +1,154 @@
+-- Databases set up from the db/init.sql that predates schema_version: adds the accounts, admin, policy, login
+-- throttling, password reset, webhook and stock alert tables and columns, and starts recording the version.
+-- Apply with psql -v ON_ERROR_STOP=1 -f.
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN locked         boolean DEFAULT false,
+    ADD COLUMN reset_required boolean DEFAULT false,
+    ADD COLUMN contact        text;
+
+CREATE TABLE password_resets
+(
+    token_hash text primary key,
+    username   text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    CONSTRAINT fk_username
+        FOREIGN KEY (username)
+            REFERENCES users (username) ON DELETE CASCADE
+);
+
+CREATE TABLE role_requests
+(
+    id         serial primary key,
+    username   text NOT NULL,
+    role       text NOT NULL,
+    status     text DEFAULT 'pending',
+    created_at timestamptz DEFAULT now(),
+    decided_by text,
+    decided_at timestamptz,
+    CONSTRAINT fk_username
+        FOREIGN KEY (username)
+            REFERENCES users (username) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX role_requests_pending ON role_requests (username) WHERE status = 'pending';
+
+CREATE TABLE audit_log
+(
+    id         serial primary key,
+    actor      text NOT NULL,
+    action     text NOT NULL,
+    target     text NOT NULL,
+    reason     text NOT NULL,
+    detail     text,
+    created_at timestamptz DEFAULT now()
+);
+
+ALTER TABLE inventory
+    ADD COLUMN reorder_threshold int NOT NULL DEFAULT 0;
+
+-- an empty table is seeded with the policy file by the first replica that starts
+CREATE TABLE casbin_rule
+(
+    id    serial primary key,
+    ptype text NOT NULL,
+    v0    text NOT NULL DEFAULT '',
+    v1    text NOT NULL DEFAULT '',
+    v2    text NOT NULL DEFAULT '',
+    v3    text NOT NULL DEFAULT '',
+    v4    text NOT NULL DEFAULT '',
+    v5    text NOT NULL DEFAULT '',
+    CONSTRAINT casbin_rule_unique UNIQUE (ptype, v0, v1, v2, v3, v4, v5)
+);
+
+CREATE TABLE login_attempts
+(
+    key          text primary key,
+    failures     int NOT NULL,
+    last_failure timestamptz NOT NULL
+);
+
+CREATE TABLE outbox
+(
+    id         serial primary key,
+    seller_id  text NOT NULL,
+    kind       text NOT NULL,
+    data       jsonb NOT NULL,
+    created_at timestamptz DEFAULT now()
+);
+
+CREATE TABLE webhook_endpoints
+(
+    id         serial primary key,
+    seller_id  text NOT NULL,
+    url        text NOT NULL,
+    events     text[] NOT NULL DEFAULT '{}',
+    secret     text NOT NULL,
+    created_at timestamptz DEFAULT now(),
+    CONSTRAINT fk_seller
+        FOREIGN KEY (seller_id)
+            REFERENCES users (username) ON DELETE CASCADE
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              serial primary key,
+    endpoint_id     int NOT NULL,
+    event_id        int NOT NULL,
+    status          text NOT NULL DEFAULT 'pending',
+    attempts        int NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT fk_endpoint
+        FOREIGN KEY (endpoint_id)
+            REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    CONSTRAINT fk_event
+        FOREIGN KEY (event_id)
+            REFERENCES outbox (id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE webhook_attempts
+(
+    id           serial primary key,
+    delivery_id  int NOT NULL,
+    status_code  int,
+    error        text,
+    duration_ms  int NOT NULL,
+    attempted_at timestamptz NOT NULL,
+    CONSTRAINT fk_delivery
+        FOREIGN KEY (delivery_id)
+            REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+);
+
+CREATE TABLE stock_alerts
+(
+    id              serial primary key,
+    product_name    text NOT NULL,
+    seller_id       text NOT NULL,
+    kind            text NOT NULL,
+    remaining       int NOT NULL,
+    threshold       int NOT NULL,
+    status          text NOT NULL DEFAULT 'open',
+    notified_at     timestamptz,
+    created_at      timestamptz DEFAULT now(),
+    acknowledged_at timestamptz,
+    CONSTRAINT fk_product_name
+        FOREIGN KEY (product_name)
+            REFERENCES products (name) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX stock_alerts_open ON stock_alerts (product_name, kind) WHERE status = 'open';
+CREATE INDEX stock_alerts_unnotified ON stock_alerts (id) WHERE notified_at IS NULL;
+
+CREATE TABLE schema_version
+(
+    version    int primary key,
+    applied_at timestamptz DEFAULT now()
+);
+
+INSERT INTO schema_version (version) VALUES (1);
+
+COMMIT;
@@ -0,0 +1,47 @@
+-- Databases at version 1 of db/init.sql or 001_schema_version.sql: purchases report out of stock as MV001
+-- and insufficient funds as MV002, with the numbers in the detail. Apply with psql -v ON_ERROR_STOP=1 -f.
+BEGIN;
+
+-- the version 1 tables have to be there, a database from before schema_version applies 001 first
+DO
+$check_version$
+BEGIN
+    if to_regclass('schema_version') IS NULL then
+        RAISE EXCEPTION 'schema_version is missing, apply 001_schema_version.sql first';
+    end if;
+    if NOT EXISTS(SELECT 1 FROM schema_version WHERE version = 1) then
+        RAISE EXCEPTION 'schema version 1 is missing, apply 001_schema_version.sql first';
+    end if;
+END
+$check_version$;
+
+CREATE OR REPLACE FUNCTION update_inventory() RETURNS trigger AS
+$update_inventory$
+DECLARE
+    inventory_amount int;
+    product_price    double precision;
+    user_deposit     int;
+BEGIN
+    SELECT amount, price into inventory_amount,product_price from inventory where product_name = NEW.product_name;
+    if NEW.amount > inventory_amount then
+        RAISE EXCEPTION 'amount is larger than inventory'
+            USING ERRCODE = 'MV001',
+                DETAIL = json_build_object('product', NEW.product_name, 'requested', NEW.amount, 'available', inventory_amount)::text;
+    end if;
+    SELECT deposit into user_deposit from users where username = NEW.username;
+    NEW.price = product_price;
+    if NEW.amount * NEW.price > user_deposit THEN
+        RAISE EXCEPTION 'cost is higher than deposit'
+            USING ERRCODE = 'MV002',
+                DETAIL = json_build_object('cost', NEW.amount * NEW.price, 'deposit', user_deposit)::text;
+    end if;
+
+    UPDATE inventory SET amount = amount - new.amount WHERE product_name = NEW.product_name;
+    UPDATE users SET deposit = deposit - (NEW.amount * NEW.price) WHERE username = NEW.username;
+    RETURN NEW;
+END
+$update_inventory$ LANGUAGE plpgsql;
+
+INSERT INTO schema_version (version) VALUES (2);
+
+COMMIT;
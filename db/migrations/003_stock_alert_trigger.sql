@@ -0,0 +1,72 @@
+-- Databases at version 2 of db/init.sql: stock alerts are raised by a trigger on every change to the stock or the
+-- reorder threshold of a product, not only by purchases. Apply with psql -v ON_ERROR_STOP=1 -f.
+BEGIN;
+
+DO
+$check_version$
+BEGIN
+    if to_regclass('schema_version') IS NULL then
+        RAISE EXCEPTION 'schema_version is missing, apply 001_schema_version.sql first';
+    end if;
+    if NOT EXISTS(SELECT 1 FROM schema_version WHERE version = 2) then
+        RAISE EXCEPTION 'schema version 2 is missing, apply 002_purchase_error_codes.sql first';
+    end if;
+END
+$check_version$;
+
+-- raise_stock_alert opens an alert unless the product has an open alert of its kind already,
+-- and tells the webhook endpoints of the seller about it
+CREATE FUNCTION raise_stock_alert(alert_product text, alert_seller text, alert_kind text, alert_remaining int,
+                                  alert_threshold int, alert_data jsonb) RETURNS void AS
+$raise_stock_alert$
+DECLARE
+    -- the webhook event kind of the alert, product.low_stock or product.sold_out
+    event_kind text := 'product.' || alert_kind;
+BEGIN
+    INSERT INTO stock_alerts (product_name, seller_id, kind, remaining, threshold)
+    VALUES (alert_product, alert_seller, alert_kind, alert_remaining, alert_threshold)
+    ON CONFLICT (product_name, kind) WHERE status = 'open' DO NOTHING;
+
+    WITH event AS (INSERT INTO outbox (seller_id, kind, data) VALUES (alert_seller, event_kind, alert_data) RETURNING id)
+    INSERT INTO webhook_deliveries (endpoint_id, event_id)
+    SELECT e.id, event.id FROM event, webhook_endpoints e
+    WHERE e.seller_id = alert_seller AND (cardinality(e.events) = 0 OR event_kind = ANY (e.events));
+END
+$raise_stock_alert$ LANGUAGE plpgsql;
+
+-- check_stock raises the alerts of every change to the stock or the reorder threshold of a product, whoever makes it:
+-- low stock once the stock is at or below a threshold above 0 and wasn't before, sold out once the last item is gone
+CREATE FUNCTION check_stock() RETURNS trigger AS
+$check_stock$
+DECLARE
+    seller text;
+BEGIN
+    SELECT seller_id INTO seller FROM products WHERE name = NEW.product_name;
+    if NOT FOUND then
+        RETURN NULL;
+    end if;
+    if NEW.reorder_threshold > 0 AND NEW.amount <= NEW.reorder_threshold
+        AND NOT (OLD.reorder_threshold > 0 AND OLD.amount <= OLD.reorder_threshold) then
+        PERFORM raise_stock_alert(NEW.product_name, seller, 'low_stock', NEW.amount, NEW.reorder_threshold,
+                                  jsonb_build_object('product', NEW.product_name, 'remaining', NEW.amount,
+                                                     'threshold', NEW.reorder_threshold));
+    end if;
+    if OLD.amount > 0 AND NEW.amount = 0 then
+        PERFORM raise_stock_alert(NEW.product_name, seller, 'sold_out', NEW.amount, NEW.reorder_threshold,
+                                  jsonb_build_object('product', NEW.product_name));
+    end if;
+    RETURN NULL;
+END
+$check_stock$ LANGUAGE plpgsql;
+
+-- deferred to the commit, so the events of a purchase follow the sold event written after the stock changed
+CREATE CONSTRAINT TRIGGER check_stock
+    AFTER UPDATE OF amount, reorder_threshold
+    ON inventory
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE PROCEDURE check_stock();
+
+INSERT INTO schema_version (version) VALUES (3);
+
+COMMIT;
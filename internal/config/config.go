@@ -0,0 +1,258 @@
+// Package config holds every setting of the service. Settings are read from a YAML or TOML file, environment variables
+// and flags, in that order of precedence from low to high, and are validated as a whole before anything starts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+)
+
+var InvalidErr = errors.New("invalid configuration")
+
+// sslModes are the modes lib/pq supports
+var sslModes = map[string]bool{"disable": true, "require": true, "verify-ca": true, "verify-full": true}
+
+type Config struct {
+	HTTP     HTTP     `mapstructure:"http"`
+	GRPC     Listener `mapstructure:"grpc"`
+	Admin    Listener `mapstructure:"admin"`
+	Coins    []int    `mapstructure:"coins"`
+	Database Database `mapstructure:"database"`
+	Auth     Auth     `mapstructure:"auth"`
+	Notifier Notifier `mapstructure:"notifier"`
+	Webhook  Webhook  `mapstructure:"webhook"`
+	Alert    Alert    `mapstructure:"alert"`
+	Trace    Trace    `mapstructure:"trace"`
+	Log      Log      `mapstructure:"log"`
+	TLS      TLS      `mapstructure:"tls"`
+	Shutdown Shutdown `mapstructure:"shutdown"`
+}
+
+type HTTP struct {
+	Host        string        `mapstructure:"host"`
+	ReadTimeout time.Duration `mapstructure:"read-timeout"`
+	// RequestTimeout bounds every request but the event stream
+	RequestTimeout time.Duration `mapstructure:"request-timeout"`
+}
+
+// Listener is an optional listener, an empty host disables it
+type Listener struct {
+	Host string `mapstructure:"host"`
+}
+
+type Database struct {
+	Host     string `mapstructure:"host"`
+	Name     string `mapstructure:"name"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	// SSLMode is disable, require, verify-ca or verify-full, the verifying modes check the server certificate
+	// against SSLRootCert
+	SSLMode         string        `mapstructure:"sslmode"`
+	SSLRootCert     string        `mapstructure:"sslrootcert"`
+	MaxOpenConns    int           `mapstructure:"max-open-conns"`
+	MaxIdleConns    int           `mapstructure:"max-idle-conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn-max-lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn-max-idle-time"`
+}
+
+type Auth struct {
+	// Model and Policy are the casbin files, the policy only seeds an empty database
+	Model         string        `mapstructure:"model"`
+	Policy        string        `mapstructure:"policy"`
+	CacheSize     int           `mapstructure:"cache-size"`
+	CacheTTL      time.Duration `mapstructure:"cache-ttl"`
+	ResetTokenTTL time.Duration `mapstructure:"reset-token-ttl"`
+	Lockout       Lockout       `mapstructure:"lockout"`
+	Password      Password      `mapstructure:"password"`
+}
+
+type Lockout struct {
+	MaxFailures int           `mapstructure:"max-failures"`
+	BaseDelay   time.Duration `mapstructure:"base-delay"`
+	MaxDelay    time.Duration `mapstructure:"max-delay"`
+	Duration    time.Duration `mapstructure:"duration"`
+	Window      time.Duration `mapstructure:"window"`
+}
+
+type Password struct {
+	// Hash is the scheme of new hashes, argon2id or bcrypt
+	Hash          string `mapstructure:"hash"`
+	BcryptCost    int    `mapstructure:"bcrypt-cost"`
+	Argon2Time    uint32 `mapstructure:"argon2-time"`
+	Argon2Memory  uint32 `mapstructure:"argon2-memory"`
+	Argon2Threads uint8  `mapstructure:"argon2-threads"`
+	MinLength     int    `mapstructure:"min-length"`
+	MaxLength     int    `mapstructure:"max-length"`
+	Blocklist     string `mapstructure:"blocklist"`
+}
+
+type Notifier struct {
+	// Kind is log or file
+	Kind string `mapstructure:"kind"`
+	File string `mapstructure:"file"`
+}
+
+type Webhook struct {
+	MaxAttempts int           `mapstructure:"max-attempts"`
+	BaseDelay   time.Duration `mapstructure:"base-delay"`
+	MaxDelay    time.Duration `mapstructure:"max-delay"`
+	Interval    time.Duration `mapstructure:"interval"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+type Alert struct {
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+type Trace struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sample-ratio"`
+}
+
+type Log struct {
+	Format string `mapstructure:"format"`
+	Level  string `mapstructure:"level"`
+}
+
+type TLS struct {
+	Cert           string        `mapstructure:"cert"`
+	Key            string        `mapstructure:"key"`
+	MinVersion     string        `mapstructure:"min-version"`
+	ClientCA       string        `mapstructure:"client-ca"`
+	ReloadInterval time.Duration `mapstructure:"reload-interval"`
+}
+
+type Shutdown struct {
+	DrainDelay time.Duration `mapstructure:"drain-delay"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// Validate reports every setting that is out of range at once.
+// Formats owned by other packages, like log levels and TLS versions, are checked when those packages start.
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, a ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, a...))
+		}
+	}
+
+	check(c.HTTP.Host != "", "http.host is empty")
+	check(c.HTTP.ReadTimeout > 0, "http.read-timeout must be positive")
+	check(c.HTTP.RequestTimeout > 0, "http.request-timeout must be positive")
+
+	if err := coin.Coins(c.Coins).Validate(); err != nil {
+		problems = append(problems, "coins: "+err.Error())
+	}
+
+	check(c.Database.Host != "", "database.host is empty")
+	check(c.Database.Name != "", "database.name is empty")
+	check(c.Database.User != "", "database.user is empty")
+	check(sslModes[c.Database.SSLMode], "unknown database.sslmode %q", c.Database.SSLMode)
+	check(c.Database.MaxOpenConns >= 0, "database.max-open-conns can't be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max-idle-conns can't be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn-max-lifetime can't be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn-max-idle-time can't be negative")
+
+	check(c.Auth.Model != "", "auth.model is empty")
+	check(c.Auth.Policy != "", "auth.policy is empty")
+	check(c.Auth.ResetTokenTTL > 0, "auth.reset-token-ttl must be positive")
+	check(c.Auth.Password.Hash == "argon2id" || c.Auth.Password.Hash == "bcrypt", "unknown auth.password.hash %q", c.Auth.Password.Hash)
+	check(c.Auth.Password.BcryptCost >= bcrypt.MinCost && c.Auth.Password.BcryptCost <= bcrypt.MaxCost,
+		"auth.password.bcrypt-cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	check(c.Auth.Password.MinLength > 0, "auth.password.min-length must be positive")
+	check(c.Auth.Password.MaxLength == 0 || c.Auth.Password.MaxLength >= c.Auth.Password.MinLength,
+		"auth.password.max-length is below auth.password.min-length")
+
+	check(c.Notifier.Kind == "log" || c.Notifier.Kind == "file", "unknown notifier.kind %q", c.Notifier.Kind)
+	check(c.Webhook.Interval > 0, "webhook.interval must be positive")
+	check(c.Webhook.Timeout > 0, "webhook.timeout must be positive")
+	check(c.Alert.Interval > 0, "alert.interval must be positive")
+	check(c.Trace.SampleRatio >= 0 && c.Trace.SampleRatio <= 1, "trace.sample-ratio must be between 0 and 1")
+	check(c.Shutdown.DrainDelay >= 0, "shutdown.drain-delay can't be negative")
+	check(c.Shutdown.Timeout > 0, "shutdown.timeout must be positive")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", InvalidErr, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// CheckReload refuses next when it changes a setting a reload would keep using the running value of,
+// the casbin files are only read from where the service started with
+func (c Config) CheckReload(next Config) error {
+	var problems []string
+	if next.Auth.Model != c.Auth.Model {
+		problems = append(problems, "auth.model")
+	}
+	if next.Auth.Policy != c.Auth.Policy {
+		problems = append(problems, "auth.policy")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s changed, that needs a restart", InvalidErr, strings.Join(problems, " and "))
+	}
+
+	return nil
+}
+
+func (c Config) ConnectionString() string {
+	d := c.Database
+	s := fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=%s", d.Host, d.User, d.Password, d.Name, d.SSLMode)
+	if d.SSLRootCert != "" {
+		s += " sslrootcert=" + d.SSLRootCert
+	}
+
+	return s
+}
+
+// Redacted returns c with its secrets masked
+func (c Config) Redacted() Config {
+	if c.Database.Password != "" {
+		c.Database.Password = logger.Redacted
+	}
+
+	return c
+}
+
+// WriteYAML writes c in the layout of the config file, so the output can be loaded again
+func (c Config) WriteYAML(w io.Writer) error {
+	out, err := yaml.Marshal(settings(reflect.ValueOf(c)))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+
+	return err
+}
+
+// settings lists the fields of a struct under their keys, in the order they are declared
+func settings(v reflect.Value) yaml.MapSlice {
+	s := make(yaml.MapSlice, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		item := yaml.MapItem{Key: v.Type().Field(i).Tag.Get("mapstructure"), Value: v.Field(i).Interface()}
+		switch value := item.Value.(type) {
+		case time.Duration:
+			item.Value = value.String()
+		default:
+			if v.Field(i).Kind() == reflect.Struct {
+				item.Value = settings(v.Field(i))
+			}
+		}
+		s = append(s, item)
+	}
+
+	return s
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// valid is a configuration Validate accepts, the cases break one setting each
+func valid() Config {
+	return Config{
+		HTTP:     HTTP{Host: ":7070", ReadTimeout: time.Second, RequestTimeout: time.Second},
+		Coins:    []int{5, 10},
+		Database: Database{Host: "db", Name: "d", User: "u", SSLMode: "disable"},
+		Auth: Auth{Model: "model.conf", Policy: "policy.csv", ResetTokenTTL: time.Minute,
+			Password: Password{Hash: "argon2id", BcryptCost: 10, MinLength: 8, MaxLength: 64}},
+		Notifier: Notifier{Kind: "log"},
+		Webhook:  Webhook{Interval: time.Second, Timeout: time.Second},
+		Alert:    Alert{Interval: time.Second},
+		Trace:    Trace{SampleRatio: 1},
+		Shutdown: Shutdown{Timeout: time.Second},
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{name: "valid", change: func(c *Config) {}},
+		{name: "no coins", change: func(c *Config) { c.Coins = nil }, want: "no coins"},
+		{name: "negative coin", change: func(c *Config) { c.Coins = []int{5, -1} }, want: "coin -1 must be positive"},
+		{name: "coin twice", change: func(c *Config) { c.Coins = []int{5, 5} }, want: "coin 5 is listed twice"},
+		{name: "no database", change: func(c *Config) { c.Database.Host = "" }, want: "database.host is empty"},
+		{name: "unknown sslmode", change: func(c *Config) { c.Database.SSLMode = "prefer" }, want: `unknown database.sslmode "prefer"`},
+		{name: "unknown hash", change: func(c *Config) { c.Auth.Password.Hash = "md5" }, want: `unknown auth.password.hash "md5"`},
+		{name: "password lengths", change: func(c *Config) { c.Auth.Password.MaxLength = 4 }, want: "auth.password.max-length is below"},
+		{name: "zero timeout", change: func(c *Config) { c.HTTP.RequestTimeout = 0 }, want: "http.request-timeout must be positive"},
+		{name: "every problem", change: func(c *Config) { c.Notifier.Kind = "mail"; c.Shutdown.Timeout = 0 },
+			want: `unknown notifier.kind "mail"; shutdown.timeout must be positive`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := valid()
+			tt.change(&c)
+			err := c.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, InvalidErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_CheckReload(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{name: "coins", change: func(c *Config) { c.Coins = []int{10, 20} }},
+		{name: "policy moved", change: func(c *Config) { c.Auth.Policy = "other.csv" }, want: "auth.policy changed"},
+		{name: "model and policy moved", change: func(c *Config) { c.Auth.Model = "other.conf"; c.Auth.Policy = "other.csv" },
+			want: "auth.model and auth.policy changed"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := valid()
+			tt.change(&next)
+			err := valid().CheckReload(next)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("CheckReload() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, InvalidErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckReload() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ConnectionString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		database Database
+		want     string
+	}{
+		{name: "disabled", database: Database{Host: "db", User: "u", Password: "p", Name: "d", SSLMode: "disable"},
+			want: "host=db user=u password=p dbname=d sslmode=disable"},
+		{name: "verified", database: Database{Host: "db", User: "u", Password: "p", Name: "d", SSLMode: "verify-full", SSLRootCert: "/certs/ca.pem"},
+			want: "host=db user=u password=p dbname=d sslmode=verify-full sslrootcert=/certs/ca.pem"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (Config{Database: tt.database}).ConnectionString(); got != tt.want {
+				t.Errorf("ConnectionString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	t.Parallel()
+	c := valid()
+	c.Database.Password = "magical_password"
+
+	var out strings.Builder
+	if err := c.Redacted().WriteYAML(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "magical_password") {
+		t.Errorf("WriteYAML() of the redacted config shows the password:\n%s", out.String())
+	}
+	if c.Database.Password != "magical_password" {
+		t.Error("Redacted() changed the config it was called on")
+	}
+}
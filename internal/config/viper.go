@@ -2,45 +2,186 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/tracing"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
-type Config struct {
-	PostgresHost     string `mapstructure:"POSTGRES_HOST"`
-	PostgresDB       string `mapstructure:"POSTGRES_DB"`
-	PostgresUser     string `mapstructure:"POSTGRES_USER"`
-	PostgresPassword string `mapstructure:"POSTGRES_PASSWORD"`
+// EnvPrefix starts the environment variable of every setting, the variable of auth.lockout.max-failures is
+// MVP_AUTH_LOCKOUT_MAX_FAILURES
+const EnvPrefix = "MVP"
+
+// ConfigEnv names the config file when --config isn't given
+const ConfigEnv = EnvPrefix + "_CONFIG"
+
+// envKeys turns a key into the end of its environment variable
+var envKeys = strings.NewReplacer(".", "_", "-", "_")
+
+// setting is a key of the config file, with the flag overriding it and its default
+type setting struct {
+	key   string
+	flag  string
+	value interface{}
+	usage string
 }
 
-func LoadConfig() (config Config, err error) {
-	viper.AutomaticEnv()
+// settingsTable lists every key of Config. Keys without a flag are only read from the file and the environment,
+// the database password stays out of the process arguments that way.
+var settingsTable = []setting{
+	{"http.host", "http-host", ":7070", "http host"},
+	{"http.read-timeout", "http-read-timeout", 5 * time.Second, "how long reading a request may take"},
+	{"http.request-timeout", "request-timeout", 5 * time.Second, "how long a request may take, the event stream stays open regardless"},
+	{"grpc.host", "grpc-host", ":7071", "grpc host, empty disables the grpc api"},
+	{"admin.host", "admin-host", "127.0.0.1:7072", "admin host serving /metrics and /reload without authentication, keep it off the public network, empty disables it"},
+	{"coins", "coins", []int{5, 10, 20, 50, 100}, "coins"},
 
-	err = viper.BindEnv("POSTGRES_HOST")
-	if err != nil {
-		return
+	{"database.host", "db-host", "", "postgres host"},
+	{"database.name", "db-name", "", "postgres database"},
+	{"database.user", "db-user", "", "postgres user"},
+	{"database.password", "", "", ""},
+	{"database.sslmode", "db-sslmode", "disable", "disable, require, verify-ca or verify-full"},
+	{"database.sslrootcert", "db-sslrootcert", "", "CA the verifying sslmodes check the server certificate against"},
+	{"database.max-open-conns", "db-max-open-conns", 20, "open connections to the database at most, 0 means no limit"},
+	{"database.max-idle-conns", "db-max-idle-conns", 10, "idle connections kept open"},
+	{"database.conn-max-lifetime", "db-conn-max-lifetime", 30 * time.Minute, "how long a connection is reused, 0 means forever"},
+	{"database.conn-max-idle-time", "db-conn-max-idle-time", 5 * time.Minute, "how long a connection may stay idle, 0 means forever"},
+
+	{"auth.model", "auth-model", "config/rbac_model.conf", "casbin model"},
+	{"auth.policy", "auth-policy", "config/auth_policy.csv", "casbin policy an empty database is seeded with"},
+	{"auth.cache-size", "auth-cache-size", 10000, "verified credentials kept in memory, 0 disables the cache"},
+	{"auth.cache-ttl", "auth-cache-ttl", 30 * time.Second, "how long verified credentials are kept, 0 disables the cache"},
+	{"auth.reset-token-ttl", "reset-token-ttl", 30 * time.Minute, "how long a password reset token is valid"},
+	{"auth.lockout.max-failures", "lockout-max-failures", lockout.DefaultPolicy.MaxFailures, "failed logins before a username is locked, 0 disables locking"},
+	{"auth.lockout.base-delay", "lockout-base-delay", lockout.DefaultPolicy.BaseDelay, "wait after the first failed login, doubled on every further failure"},
+	{"auth.lockout.max-delay", "lockout-max-delay", lockout.DefaultPolicy.MaxDelay, "longest wait between failed logins"},
+	{"auth.lockout.duration", "lockout-duration", lockout.DefaultPolicy.LockoutFor, "how long a username stays locked"},
+	{"auth.lockout.window", "lockout-window", lockout.DefaultPolicy.Window, "how long failed logins are remembered"},
+	{"auth.password.hash", "password-hash", "argon2id", "scheme for new password hashes, argon2id or bcrypt, older hashes are upgraded on login"},
+	{"auth.password.bcrypt-cost", "bcrypt-cost", bcrypt.DefaultCost, "bcrypt cost"},
+	{"auth.password.argon2-time", "argon2-time", pass.DefaultArgon2id.Time, "argon2id iterations"},
+	{"auth.password.argon2-memory", "argon2-memory", pass.DefaultArgon2id.Memory, "argon2id memory in KiB"},
+	{"auth.password.argon2-threads", "argon2-threads", pass.DefaultArgon2id.Threads, "argon2id parallelism"},
+	{"auth.password.min-length", "password-min-length", 8, "shortest password accepted"},
+	{"auth.password.max-length", "password-max-length", 64, "longest password accepted, 0 means no limit"},
+	{"auth.password.blocklist", "password-blocklist", "config/breached_passwords.txt", "file of breached passwords that are refused, one per line"},
+
+	{"notifier.kind", "notifier", "log", "delivery of password reset tokens and stock alerts, log or file"},
+	{"notifier.file", "notifier-file", "notifications.jsonl", "file the file notifier appends messages to"},
+	{"webhook.max-attempts", "webhook-max-attempts", webhook.DefaultRetry.MaxAttempts, "webhook delivery attempts before a delivery is dead"},
+	{"webhook.base-delay", "webhook-base-delay", webhook.DefaultRetry.BaseDelay, "wait after the first failed webhook delivery, doubled on every further failure"},
+	{"webhook.max-delay", "webhook-max-delay", webhook.DefaultRetry.MaxDelay, "longest wait between webhook delivery attempts"},
+	{"webhook.interval", "webhook-interval", 5 * time.Second, "how often due webhook deliveries are looked for"},
+	{"webhook.timeout", "webhook-timeout", 10 * time.Second, "how long a webhook receiver may take to answer"},
+	{"alert.interval", "alert-interval", 10 * time.Second, "how often new stock alerts are sent to their sellers"},
+
+	{"trace.exporter", "trace-exporter", tracing.None, "where spans go: none, otlp, stdout or file"},
+	{"trace.endpoint", "trace-endpoint", "localhost:4317", "host:port of the OTLP collector"},
+	{"trace.insecure", "trace-insecure", false, "send spans to the OTLP collector without TLS"},
+	{"trace.file", "trace-file", "traces.jsonl", "file the file exporter appends spans to"},
+	{"trace.sample-ratio", "trace-sample-ratio", 1.0, "share of the traces starting here that are recorded"},
+	{"log.format", "log-format", logger.JSON, "format of the log lines, json or text"},
+	{"log.level", "log-level", "info", "least severe level that is logged: debug, info, warn or error"},
+
+	{"tls.cert", "tls-cert", "", "PEM certificate chain the http and grpc hosts serve, empty serves plain text"},
+	{"tls.key", "tls-key", "", "PEM private key of --tls-cert"},
+	{"tls.min-version", "tls-min-version", "1.2", "oldest TLS version accepted: 1.0, 1.1, 1.2 or 1.3"},
+	{"tls.client-ca", "tls-client-ca", "", "PEM CAs whose client certificates authenticate users, empty disables client certificates"},
+	{"tls.reload-interval", "tls-reload-interval", time.Minute, "how often the certificate files are checked for a rotated certificate"},
+
+	{"shutdown.drain-delay", "drain-delay", 5 * time.Second, "how long requests are still taken after a shutdown signal while /readyz fails"},
+	{"shutdown.timeout", "shutdown-timeout", 10 * time.Second, "how long running requests, calls and workers may take to finish on shutdown"},
+}
+
+// legacyEnv are the variables the database was configured with before the config file, they still work
+var legacyEnv = map[string]string{
+	"database.host":        "POSTGRES_HOST",
+	"database.name":        "POSTGRES_DB",
+	"database.user":        "POSTGRES_USER",
+	"database.password":    "POSTGRES_PASSWORD",
+	"database.sslmode":     "POSTGRES_SSLMODE",
+	"database.sslrootcert": "POSTGRES_SSLROOTCERT",
+}
+
+// RegisterFlags adds --config and a flag for every setting that has one to fs
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.String("config", "", "YAML or TOML config file, "+ConfigEnv+" names it otherwise")
+	for _, s := range settingsTable {
+		if s.flag == "" {
+			continue
+		}
+		switch value := s.value.(type) {
+		case string:
+			fs.String(s.flag, value, s.usage)
+		case int:
+			fs.Int(s.flag, value, s.usage)
+		case uint8:
+			fs.Uint8(s.flag, value, s.usage)
+		case uint32:
+			fs.Uint32(s.flag, value, s.usage)
+		case bool:
+			fs.Bool(s.flag, value, s.usage)
+		case float64:
+			fs.Float64(s.flag, value, s.usage)
+		case time.Duration:
+			fs.Duration(s.flag, value, s.usage)
+		case []int:
+			fs.IntSlice(s.flag, value, s.usage)
+		default:
+			panic(fmt.Sprintf("config: no flag type for %s", s.key))
+		}
 	}
+}
 
-	err = viper.BindEnv("POSTGRES_PASSWORD")
-	if err != nil {
-		return
+// Load reads the configuration of the parsed flags in fs, which RegisterFlags set up.
+// A flag given on the command line wins over the environment, which wins over the config file, which wins over
+// the defaults. The result is validated.
+func Load(fs *pflag.FlagSet) (config Config, err error) {
+	v := viper.New()
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(envKeys)
+	v.AutomaticEnv()
+
+	for _, s := range settingsTable {
+		v.SetDefault(s.key, s.value)
+		if legacy, ok := legacyEnv[s.key]; ok {
+			if err = v.BindEnv(s.key, EnvPrefix+"_"+strings.ToUpper(envKeys.Replace(s.key)), legacy); err != nil {
+				return
+			}
+		}
+		if s.flag != "" {
+			if err = v.BindPFlag(s.key, fs.Lookup(s.flag)); err != nil {
+				return
+			}
+		}
 	}
 
-	err = viper.BindEnv("POSTGRES_USER")
+	file, err := fs.GetString("config")
 	if err != nil {
 		return
 	}
+	if file == "" {
+		file = os.Getenv(ConfigEnv)
+	}
+	if file != "" {
+		v.SetConfigFile(file)
+		if err = v.ReadInConfig(); err != nil {
+			return
+		}
+	}
 
-	err = viper.BindEnv("POSTGRES_DB")
-	if err != nil {
+	// keys the file doesn't know are refused, a typo would otherwise leave the default in place silently
+	if err = v.UnmarshalExact(&config); err != nil {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
-
-	return
-}
-
-func (c Config) ConnectionString() string {
-	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=disable", c.PostgresHost, c.PostgresUser, c.PostgresPassword, c.PostgresDB)
+	return config, config.Validate()
 }
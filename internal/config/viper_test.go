@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// load parses args like the command line and loads the configuration, the environment is left to the test
+func load(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	return Load(fs)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+const database = `
+database:
+  host: db
+  name: rainbow_database
+  user: unicorn_user
+`
+
+// the environment is shared by the whole process, so these tests don't run in parallel
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "mvp.yaml", database+`
+http:
+  host: ":8000"
+  request-timeout: 3s
+grpc:
+  host: ":8001"
+admin:
+  host: ":8002"
+coins: [1, 2]
+`)
+	t.Setenv("MVP_GRPC_HOST", ":9001")
+	t.Setenv("MVP_ADMIN_HOST", ":9002")
+
+	c, err := load(t, "--config", file, "--admin-host", ":10002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HTTP.Host != ":8000" || c.HTTP.RequestTimeout != 3*time.Second {
+		t.Errorf("http = %+v, want the file", c.HTTP)
+	}
+	if c.GRPC.Host != ":9001" {
+		t.Errorf("grpc.host = %q, want the environment", c.GRPC.Host)
+	}
+	if c.Admin.Host != ":10002" {
+		t.Errorf("admin.host = %q, want the flag", c.Admin.Host)
+	}
+	if !reflect.DeepEqual(c.Coins, []int{1, 2}) {
+		t.Errorf("coins = %v, want the file", c.Coins)
+	}
+	if c.Auth.Lockout.MaxFailures != 5 || c.Auth.Password.Hash != "argon2id" {
+		t.Errorf("auth = %+v, want the defaults", c.Auth)
+	}
+}
+
+func TestLoad_Environment(t *testing.T) {
+	t.Setenv("POSTGRES_HOST", "db")
+	t.Setenv("POSTGRES_DB", "rainbow_database")
+	t.Setenv("POSTGRES_USER", "unicorn_user")
+	t.Setenv("POSTGRES_PASSWORD", "magical_password")
+	t.Setenv("MVP_DATABASE_USER", "admin")
+	t.Setenv("MVP_COINS", "5,10")
+	t.Setenv("MVP_AUTH_LOCKOUT_MAX_FAILURES", "3")
+
+	c, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Database{Host: "db", Name: "rainbow_database", User: "admin", Password: "magical_password", SSLMode: "disable"}
+	got := c.Database
+	got.MaxOpenConns, got.MaxIdleConns, got.ConnMaxLifetime, got.ConnMaxIdleTime = 0, 0, 0, 0
+	if got != want {
+		t.Errorf("database = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(c.Coins, []int{5, 10}) {
+		t.Errorf("coins = %v, want [5 10]", c.Coins)
+	}
+	if c.Auth.Lockout.MaxFailures != 3 {
+		t.Errorf("auth.lockout.max-failures = %d, want 3", c.Auth.Lockout.MaxFailures)
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	file := writeFile(t, "mvp.toml", `
+coins = [25, 50]
+
+[database]
+host = "db"
+name = "rainbow_database"
+user = "unicorn_user"
+max-open-conns = 4
+
+[auth.password]
+min-length = 12
+`)
+	t.Setenv(ConfigEnv, file)
+
+	c, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Coins, []int{25, 50}) || c.Database.MaxOpenConns != 4 || c.Auth.Password.MinLength != 12 {
+		t.Errorf("Load() = %+v, want the file", c)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want string
+	}{
+		{name: "unknown key", file: database + "\nauth:\n  cache-sizes: 10\n", want: "cache-sizes"},
+		{name: "invalid value", file: database, args: []string{"--coins", "5,0"}, want: "coin 0 must be positive"},
+		{name: "no database", file: "coins: [5]\n", want: "database.host is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--config", writeFile(t, "mvp.yaml", tt.file)}, tt.args...)
+			_, err := load(t, args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_PrintedConfig(t *testing.T) {
+	c, err := load(t, "--config", writeFile(t, "mvp.yaml", database), "--coins", "1,2,5", "--trace-sample-ratio", "0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := c.WriteYAML(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	// the printed configuration is a config file giving the same settings
+	again, err := load(t, "--config", writeFile(t, "printed.yaml", out.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, c) {
+		t.Errorf("loading the printed config = %+v, want %+v", again, c)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := load(t, "--config", filepath.Join(t.TempDir(), "missing.yaml"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/admin (interfaces: Repository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	admin "github.com/artback/mvp/pkg/admin"
+	security "github.com/artback/mvp/pkg/api/middleware/security"
+	users "github.com/artback/mvp/pkg/users"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// AdminRepository is a mock of Repository interface.
+type AdminRepository struct {
+	ctrl     *gomock.Controller
+	recorder *AdminRepositoryMockRecorder
+}
+
+// AdminRepositoryMockRecorder is the mock recorder for AdminRepository.
+type AdminRepositoryMockRecorder struct {
+	mock *AdminRepository
+}
+
+// NewAdminRepository creates a new mock instance.
+func NewAdminRepository(ctrl *gomock.Controller) *AdminRepository {
+	mock := &AdminRepository{ctrl: ctrl}
+	mock.recorder = &AdminRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AdminRepository) EXPECT() *AdminRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdjustDeposit mocks base method.
+func (m *AdminRepository) AdjustDeposit(arg0 context.Context, arg1 admin.Entry, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustDeposit", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustDeposit indicates an expected call of AdjustDeposit.
+func (mr *AdminRepositoryMockRecorder) AdjustDeposit(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustDeposit", reflect.TypeOf((*AdminRepository)(nil).AdjustDeposit), arg0, arg1, arg2)
+}
+
+// AuditLog mocks base method.
+func (m *AdminRepository) AuditLog(arg0 context.Context, arg1 string) ([]admin.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", arg0, arg1)
+	ret0, _ := ret[0].([]admin.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *AdminRepositoryMockRecorder) AuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*AdminRepository)(nil).AuditLog), arg0, arg1)
+}
+
+// DecideRoleRequest mocks base method.
+func (m *AdminRepository) DecideRoleRequest(arg0 context.Context, arg1 admin.Entry, arg2 int, arg3 users.RoleRequestStatus) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideRoleRequest", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideRoleRequest indicates an expected call of DecideRoleRequest.
+func (mr *AdminRepositoryMockRecorder) DecideRoleRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideRoleRequest", reflect.TypeOf((*AdminRepository)(nil).DecideRoleRequest), arg0, arg1, arg2, arg3)
+}
+
+// ListUsers mocks base method.
+func (m *AdminRepository) ListUsers(arg0 context.Context, arg1 admin.Filter) ([]admin.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]admin.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *AdminRepositoryMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*AdminRepository)(nil).ListUsers), arg0, arg1)
+}
+
+// RequirePasswordReset mocks base method.
+func (m *AdminRepository) RequirePasswordReset(arg0 context.Context, arg1 admin.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequirePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequirePasswordReset indicates an expected call of RequirePasswordReset.
+func (mr *AdminRepositoryMockRecorder) RequirePasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequirePasswordReset", reflect.TypeOf((*AdminRepository)(nil).RequirePasswordReset), arg0, arg1)
+}
+
+// RoleRequests mocks base method.
+func (m *AdminRepository) RoleRequests(arg0 context.Context) ([]users.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleRequests", arg0)
+	ret0, _ := ret[0].([]users.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoleRequests indicates an expected call of RoleRequests.
+func (mr *AdminRepositoryMockRecorder) RoleRequests(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleRequests", reflect.TypeOf((*AdminRepository)(nil).RoleRequests), arg0)
+}
+
+// SetLocked mocks base method.
+func (m *AdminRepository) SetLocked(arg0 context.Context, arg1 admin.Entry, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocked", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLocked indicates an expected call of SetLocked.
+func (mr *AdminRepositoryMockRecorder) SetLocked(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocked", reflect.TypeOf((*AdminRepository)(nil).SetLocked), arg0, arg1, arg2)
+}
+
+// SetRole mocks base method.
+func (m *AdminRepository) SetRole(arg0 context.Context, arg1 admin.Entry, arg2 security.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *AdminRepositoryMockRecorder) SetRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*AdminRepository)(nil).SetRole), arg0, arg1, arg2)
+}
@@ -0,0 +1,181 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/admin (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	admin "github.com/artback/mvp/pkg/admin"
+	security "github.com/artback/mvp/pkg/api/middleware/security"
+	users "github.com/artback/mvp/pkg/users"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// AdminService is a mock of Service interface.
+type AdminService struct {
+	ctrl     *gomock.Controller
+	recorder *AdminServiceMockRecorder
+}
+
+// AdminServiceMockRecorder is the mock recorder for AdminService.
+type AdminServiceMockRecorder struct {
+	mock *AdminService
+}
+
+// NewAdminService creates a new mock instance.
+func NewAdminService(ctrl *gomock.Controller) *AdminService {
+	mock := &AdminService{ctrl: ctrl}
+	mock.recorder = &AdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AdminService) EXPECT() *AdminServiceMockRecorder {
+	return m.recorder
+}
+
+// AdjustDeposit mocks base method.
+func (m *AdminService) AdjustDeposit(arg0 context.Context, arg1, arg2 string, arg3 int, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustDeposit", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustDeposit indicates an expected call of AdjustDeposit.
+func (mr *AdminServiceMockRecorder) AdjustDeposit(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustDeposit", reflect.TypeOf((*AdminService)(nil).AdjustDeposit), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ApproveRoleRequest mocks base method.
+func (m *AdminService) ApproveRoleRequest(arg0 context.Context, arg1 string, arg2 int, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveRoleRequest", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveRoleRequest indicates an expected call of ApproveRoleRequest.
+func (mr *AdminServiceMockRecorder) ApproveRoleRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveRoleRequest", reflect.TypeOf((*AdminService)(nil).ApproveRoleRequest), arg0, arg1, arg2, arg3)
+}
+
+// AuditLog mocks base method.
+func (m *AdminService) AuditLog(arg0 context.Context, arg1 string) ([]admin.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", arg0, arg1)
+	ret0, _ := ret[0].([]admin.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *AdminServiceMockRecorder) AuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*AdminService)(nil).AuditLog), arg0, arg1)
+}
+
+// ForcePasswordReset mocks base method.
+func (m *AdminService) ForcePasswordReset(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForcePasswordReset", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForcePasswordReset indicates an expected call of ForcePasswordReset.
+func (mr *AdminServiceMockRecorder) ForcePasswordReset(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForcePasswordReset", reflect.TypeOf((*AdminService)(nil).ForcePasswordReset), arg0, arg1, arg2, arg3)
+}
+
+// ListUsers mocks base method.
+func (m *AdminService) ListUsers(arg0 context.Context, arg1 admin.Filter) ([]admin.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]admin.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *AdminServiceMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*AdminService)(nil).ListUsers), arg0, arg1)
+}
+
+// Lock mocks base method.
+func (m *AdminService) Lock(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *AdminServiceMockRecorder) Lock(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*AdminService)(nil).Lock), arg0, arg1, arg2, arg3)
+}
+
+// RejectRoleRequest mocks base method.
+func (m *AdminService) RejectRoleRequest(arg0 context.Context, arg1 string, arg2 int, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectRoleRequest", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectRoleRequest indicates an expected call of RejectRoleRequest.
+func (mr *AdminServiceMockRecorder) RejectRoleRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectRoleRequest", reflect.TypeOf((*AdminService)(nil).RejectRoleRequest), arg0, arg1, arg2, arg3)
+}
+
+// RoleRequests mocks base method.
+func (m *AdminService) RoleRequests(arg0 context.Context) ([]users.RoleRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleRequests", arg0)
+	ret0, _ := ret[0].([]users.RoleRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoleRequests indicates an expected call of RoleRequests.
+func (mr *AdminServiceMockRecorder) RoleRequests(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleRequests", reflect.TypeOf((*AdminService)(nil).RoleRequests), arg0)
+}
+
+// SetRole mocks base method.
+func (m *AdminService) SetRole(arg0 context.Context, arg1, arg2 string, arg3 security.Role, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *AdminServiceMockRecorder) SetRole(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*AdminService)(nil).SetRole), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Unlock mocks base method.
+func (m *AdminService) Unlock(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *AdminServiceMockRecorder) Unlock(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*AdminService)(nil).Unlock), arg0, arg1, arg2, arg3)
+}
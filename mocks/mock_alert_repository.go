@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/alert (interfaces: Repository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	alert "github.com/artback/mvp/pkg/alert"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// AlertRepository is a mock of Repository interface.
+type AlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *AlertRepositoryMockRecorder
+}
+
+// AlertRepositoryMockRecorder is the mock recorder for AlertRepository.
+type AlertRepositoryMockRecorder struct {
+	mock *AlertRepository
+}
+
+// NewAlertRepository creates a new mock instance.
+func NewAlertRepository(ctrl *gomock.Controller) *AlertRepository {
+	mock := &AlertRepository{ctrl: ctrl}
+	mock.recorder = &AlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AlertRepository) EXPECT() *AlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Acknowledge mocks base method.
+func (m *AlertRepository) Acknowledge(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acknowledge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Acknowledge indicates an expected call of Acknowledge.
+func (mr *AlertRepositoryMockRecorder) Acknowledge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acknowledge", reflect.TypeOf((*AlertRepository)(nil).Acknowledge), arg0, arg1, arg2)
+}
+
+// Alerts mocks base method.
+func (m *AlertRepository) Alerts(arg0 context.Context, arg1 string, arg2 alert.Status) ([]alert.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Alerts", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]alert.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Alerts indicates an expected call of Alerts.
+func (mr *AlertRepositoryMockRecorder) Alerts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alerts", reflect.TypeOf((*AlertRepository)(nil).Alerts), arg0, arg1, arg2)
+}
+
+// Renotify mocks base method.
+func (m *AlertRepository) Renotify(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Renotify", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Renotify indicates an expected call of Renotify.
+func (mr *AlertRepositoryMockRecorder) Renotify(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Renotify", reflect.TypeOf((*AlertRepository)(nil).Renotify), arg0, arg1)
+}
+
+// SetThreshold mocks base method.
+func (m *AlertRepository) SetThreshold(arg0 context.Context, arg1, arg2 string, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreshold", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreshold indicates an expected call of SetThreshold.
+func (mr *AlertRepositoryMockRecorder) SetThreshold(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreshold", reflect.TypeOf((*AlertRepository)(nil).SetThreshold), arg0, arg1, arg2, arg3)
+}
+
+// Unnotified mocks base method.
+func (m *AlertRepository) Unnotified(arg0 context.Context, arg1 int) ([]alert.Notice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unnotified", arg0, arg1)
+	ret0, _ := ret[0].([]alert.Notice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unnotified indicates an expected call of Unnotified.
+func (mr *AlertRepositoryMockRecorder) Unnotified(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unnotified", reflect.TypeOf((*AlertRepository)(nil).Unnotified), arg0, arg1)
+}
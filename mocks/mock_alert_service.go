@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/alert (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	alert "github.com/artback/mvp/pkg/alert"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// AlertService is a mock of Service interface.
+type AlertService struct {
+	ctrl     *gomock.Controller
+	recorder *AlertServiceMockRecorder
+}
+
+// AlertServiceMockRecorder is the mock recorder for AlertService.
+type AlertServiceMockRecorder struct {
+	mock *AlertService
+}
+
+// NewAlertService creates a new mock instance.
+func NewAlertService(ctrl *gomock.Controller) *AlertService {
+	mock := &AlertService{ctrl: ctrl}
+	mock.recorder = &AlertServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AlertService) EXPECT() *AlertServiceMockRecorder {
+	return m.recorder
+}
+
+// Acknowledge mocks base method.
+func (m *AlertService) Acknowledge(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acknowledge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Acknowledge indicates an expected call of Acknowledge.
+func (mr *AlertServiceMockRecorder) Acknowledge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acknowledge", reflect.TypeOf((*AlertService)(nil).Acknowledge), arg0, arg1, arg2)
+}
+
+// Alerts mocks base method.
+func (m *AlertService) Alerts(arg0 context.Context, arg1 string, arg2 alert.Status) ([]alert.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Alerts", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]alert.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Alerts indicates an expected call of Alerts.
+func (mr *AlertServiceMockRecorder) Alerts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alerts", reflect.TypeOf((*AlertService)(nil).Alerts), arg0, arg1, arg2)
+}
+
+// SetThreshold mocks base method.
+func (m *AlertService) SetThreshold(arg0 context.Context, arg1, arg2 string, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreshold", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreshold indicates an expected call of SetThreshold.
+func (mr *AlertServiceMockRecorder) SetThreshold(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreshold", reflect.TypeOf((*AlertService)(nil).SetThreshold), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/events (interfaces: Publisher)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	events "github.com/artback/mvp/pkg/events"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// EventPublisher is a mock of Publisher interface.
+type EventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *EventPublisherMockRecorder
+}
+
+// EventPublisherMockRecorder is the mock recorder for EventPublisher.
+type EventPublisherMockRecorder struct {
+	mock *EventPublisher
+}
+
+// NewEventPublisher creates a new mock instance.
+func NewEventPublisher(ctrl *gomock.Controller) *EventPublisher {
+	mock := &EventPublisher{ctrl: ctrl}
+	mock.recorder = &EventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *EventPublisher) EXPECT() *EventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *EventPublisher) Publish(arg0 context.Context, arg1 events.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *EventPublisherMockRecorder) Publish(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*EventPublisher)(nil).Publish), arg0, arg1)
+}
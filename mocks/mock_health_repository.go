@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/health (interfaces: Repository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// HealthRepository is a mock of Repository interface.
+type HealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *HealthRepositoryMockRecorder
+}
+
+// HealthRepositoryMockRecorder is the mock recorder for HealthRepository.
+type HealthRepositoryMockRecorder struct {
+	mock *HealthRepository
+}
+
+// NewHealthRepository creates a new mock instance.
+func NewHealthRepository(ctrl *gomock.Controller) *HealthRepository {
+	mock := &HealthRepository{ctrl: ctrl}
+	mock.recorder = &HealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *HealthRepository) EXPECT() *HealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Ping mocks base method.
+func (m *HealthRepository) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *HealthRepositoryMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*HealthRepository)(nil).Ping), arg0)
+}
+
+// SchemaVersion mocks base method.
+func (m *HealthRepository) SchemaVersion(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaVersion", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchemaVersion indicates an expected call of SchemaVersion.
+func (mr *HealthRepositoryMockRecorder) SchemaVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaVersion", reflect.TypeOf((*HealthRepository)(nil).SchemaVersion), arg0)
+}
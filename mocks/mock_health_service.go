@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/health (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	health "github.com/artback/mvp/pkg/health"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// HealthService is a mock of Service interface.
+type HealthService struct {
+	ctrl     *gomock.Controller
+	recorder *HealthServiceMockRecorder
+}
+
+// HealthServiceMockRecorder is the mock recorder for HealthService.
+type HealthServiceMockRecorder struct {
+	mock *HealthService
+}
+
+// NewHealthService creates a new mock instance.
+func NewHealthService(ctrl *gomock.Controller) *HealthService {
+	mock := &HealthService{ctrl: ctrl}
+	mock.recorder = &HealthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *HealthService) EXPECT() *HealthServiceMockRecorder {
+	return m.recorder
+}
+
+// Ready mocks base method.
+func (m *HealthService) Ready(arg0 context.Context) health.Report {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", arg0)
+	ret0, _ := ret[0].(health.Report)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *HealthServiceMockRecorder) Ready(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*HealthService)(nil).Ready), arg0)
+}
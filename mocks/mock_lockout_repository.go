@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/lockout (interfaces: Repository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	lockout "github.com/artback/mvp/pkg/lockout"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// LockoutRepository is a mock of Repository interface.
+type LockoutRepository struct {
+	ctrl     *gomock.Controller
+	recorder *LockoutRepositoryMockRecorder
+}
+
+// LockoutRepositoryMockRecorder is the mock recorder for LockoutRepository.
+type LockoutRepositoryMockRecorder struct {
+	mock *LockoutRepository
+}
+
+// NewLockoutRepository creates a new mock instance.
+func NewLockoutRepository(ctrl *gomock.Controller) *LockoutRepository {
+	mock := &LockoutRepository{ctrl: ctrl}
+	mock.recorder = &LockoutRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *LockoutRepository) EXPECT() *LockoutRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Fail mocks base method.
+func (m *LockoutRepository) Fail(arg0 context.Context, arg1 string, arg2, arg3 time.Time) (lockout.State, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(lockout.State)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *LockoutRepositoryMockRecorder) Fail(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*LockoutRepository)(nil).Fail), arg0, arg1, arg2, arg3)
+}
+
+// Get mocks base method.
+func (m *LockoutRepository) Get(arg0 context.Context, arg1 ...string) ([]lockout.State, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
+	ret0, _ := ret[0].([]lockout.State)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *LockoutRepositoryMockRecorder) Get(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*LockoutRepository)(nil).Get), varargs...)
+}
+
+// Reset mocks base method.
+func (m *LockoutRepository) Reset(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *LockoutRepositoryMockRecorder) Reset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*LockoutRepository)(nil).Reset), arg0, arg1)
+}
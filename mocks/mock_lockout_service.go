@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/lockout (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// LockoutService is a mock of Service interface.
+type LockoutService struct {
+	ctrl     *gomock.Controller
+	recorder *LockoutServiceMockRecorder
+}
+
+// LockoutServiceMockRecorder is the mock recorder for LockoutService.
+type LockoutServiceMockRecorder struct {
+	mock *LockoutService
+}
+
+// NewLockoutService creates a new mock instance.
+func NewLockoutService(ctrl *gomock.Controller) *LockoutService {
+	mock := &LockoutService{ctrl: ctrl}
+	mock.recorder = &LockoutServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *LockoutService) EXPECT() *LockoutServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *LockoutService) Check(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *LockoutServiceMockRecorder) Check(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*LockoutService)(nil).Check), arg0, arg1, arg2)
+}
+
+// Fail mocks base method.
+func (m *LockoutService) Fail(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *LockoutServiceMockRecorder) Fail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*LockoutService)(nil).Fail), arg0, arg1, arg2)
+}
+
+// Reset mocks base method.
+func (m *LockoutService) Reset(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *LockoutServiceMockRecorder) Reset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*LockoutService)(nil).Reset), arg0, arg1)
+}
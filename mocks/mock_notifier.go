@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/notify (interfaces: Notifier)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	notify "github.com/artback/mvp/pkg/notify"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Notifier is a mock of Notifier interface.
+type Notifier struct {
+	ctrl     *gomock.Controller
+	recorder *NotifierMockRecorder
+}
+
+// NotifierMockRecorder is the mock recorder for Notifier.
+type NotifierMockRecorder struct {
+	mock *Notifier
+}
+
+// NewNotifier creates a new mock instance.
+func NewNotifier(ctrl *gomock.Controller) *Notifier {
+	mock := &Notifier{ctrl: ctrl}
+	mock.recorder = &NotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Notifier) EXPECT() *NotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *Notifier) Notify(arg0 context.Context, arg1 notify.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *NotifierMockRecorder) Notify(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*Notifier)(nil).Notify), arg0, arg1)
+}
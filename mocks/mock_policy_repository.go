@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/policy (interfaces: Repository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	admin "github.com/artback/mvp/pkg/admin"
+	policy "github.com/artback/mvp/pkg/policy"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// PolicyRepository is a mock of Repository interface.
+type PolicyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *PolicyRepositoryMockRecorder
+}
+
+// PolicyRepositoryMockRecorder is the mock recorder for PolicyRepository.
+type PolicyRepositoryMockRecorder struct {
+	mock *PolicyRepository
+}
+
+// NewPolicyRepository creates a new mock instance.
+func NewPolicyRepository(ctrl *gomock.Controller) *PolicyRepository {
+	mock := &PolicyRepository{ctrl: ctrl}
+	mock.recorder = &PolicyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *PolicyRepository) EXPECT() *PolicyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *PolicyRepository) Apply(arg0 context.Context, arg1 admin.Entry, arg2 ...policy.Change) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Apply", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *PolicyRepositoryMockRecorder) Apply(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*PolicyRepository)(nil).Apply), varargs...)
+}
@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/policy (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	policy "github.com/artback/mvp/pkg/policy"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// PolicyService is a mock of Service interface.
+type PolicyService struct {
+	ctrl     *gomock.Controller
+	recorder *PolicyServiceMockRecorder
+}
+
+// PolicyServiceMockRecorder is the mock recorder for PolicyService.
+type PolicyServiceMockRecorder struct {
+	mock *PolicyService
+}
+
+// NewPolicyService creates a new mock instance.
+func NewPolicyService(ctrl *gomock.Controller) *PolicyService {
+	mock := &PolicyService{ctrl: ctrl}
+	mock.recorder = &PolicyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *PolicyService) EXPECT() *PolicyServiceMockRecorder {
+	return m.recorder
+}
+
+// AddAssignment mocks base method.
+func (m *PolicyService) AddAssignment(arg0 context.Context, arg1 string, arg2 policy.Assignment, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAssignment", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAssignment indicates an expected call of AddAssignment.
+func (mr *PolicyServiceMockRecorder) AddAssignment(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAssignment", reflect.TypeOf((*PolicyService)(nil).AddAssignment), arg0, arg1, arg2, arg3)
+}
+
+// AddRule mocks base method.
+func (m *PolicyService) AddRule(arg0 context.Context, arg1 string, arg2 policy.Rule, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRule", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRule indicates an expected call of AddRule.
+func (mr *PolicyServiceMockRecorder) AddRule(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRule", reflect.TypeOf((*PolicyService)(nil).AddRule), arg0, arg1, arg2, arg3)
+}
+
+// Assignments mocks base method.
+func (m *PolicyService) Assignments(arg0 context.Context) ([]policy.Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assignments", arg0)
+	ret0, _ := ret[0].([]policy.Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Assignments indicates an expected call of Assignments.
+func (mr *PolicyServiceMockRecorder) Assignments(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assignments", reflect.TypeOf((*PolicyService)(nil).Assignments), arg0)
+}
+
+// RemoveAssignment mocks base method.
+func (m *PolicyService) RemoveAssignment(arg0 context.Context, arg1 string, arg2 policy.Assignment, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAssignment", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAssignment indicates an expected call of RemoveAssignment.
+func (mr *PolicyServiceMockRecorder) RemoveAssignment(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAssignment", reflect.TypeOf((*PolicyService)(nil).RemoveAssignment), arg0, arg1, arg2, arg3)
+}
+
+// RemoveRule mocks base method.
+func (m *PolicyService) RemoveRule(arg0 context.Context, arg1 string, arg2 policy.Rule, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRule", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRule indicates an expected call of RemoveRule.
+func (mr *PolicyServiceMockRecorder) RemoveRule(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRule", reflect.TypeOf((*PolicyService)(nil).RemoveRule), arg0, arg1, arg2, arg3)
+}
+
+// Rules mocks base method.
+func (m *PolicyService) Rules(arg0 context.Context) ([]policy.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rules", arg0)
+	ret0, _ := ret[0].([]policy.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rules indicates an expected call of Rules.
+func (mr *PolicyServiceMockRecorder) Rules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rules", reflect.TypeOf((*PolicyService)(nil).Rules), arg0)
+}
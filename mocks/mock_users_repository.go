@@ -7,7 +7,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	security "github.com/artback/mvp/pkg/api/middleware/security"
 	users "github.com/artback/mvp/pkg/users"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +37,34 @@ func (m *UserRepository) EXPECT() *UserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Apply mocks base method.
+func (m *UserRepository) Apply(arg0 context.Context, arg1 string, arg2 users.Change) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *UserRepositoryMockRecorder) Apply(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*UserRepository)(nil).Apply), arg0, arg1, arg2)
+}
+
+// CreateResetToken mocks base method.
+func (m *UserRepository) CreateResetToken(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateResetToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateResetToken indicates an expected call of CreateResetToken.
+func (mr *UserRepositoryMockRecorder) CreateResetToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResetToken", reflect.TypeOf((*UserRepository)(nil).CreateResetToken), arg0, arg1, arg2, arg3)
+}
+
 // Delete mocks base method.
 func (m *UserRepository) Delete(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -78,6 +108,92 @@ func (mr *UserRepositoryMockRecorder) Insert(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*UserRepository)(nil).Insert), arg0, arg1)
 }
 
+// InsertRequestingRole mocks base method.
+func (m *UserRepository) InsertRequestingRole(arg0 context.Context, arg1 users.User, arg2 security.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRequestingRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertRequestingRole indicates an expected call of InsertRequestingRole.
+func (mr *UserRepositoryMockRecorder) InsertRequestingRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRequestingRole", reflect.TypeOf((*UserRepository)(nil).InsertRequestingRole), arg0, arg1, arg2)
+}
+
+// RedeemResetToken mocks base method.
+func (m *UserRepository) RedeemResetToken(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemResetToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemResetToken indicates an expected call of RedeemResetToken.
+func (mr *UserRepositoryMockRecorder) RedeemResetToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemResetToken", reflect.TypeOf((*UserRepository)(nil).RedeemResetToken), arg0, arg1, arg2)
+}
+
+// ReplacePasswordHash mocks base method.
+func (m *UserRepository) ReplacePasswordHash(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePasswordHash", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplacePasswordHash indicates an expected call of ReplacePasswordHash.
+func (mr *UserRepositoryMockRecorder) ReplacePasswordHash(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePasswordHash", reflect.TypeOf((*UserRepository)(nil).ReplacePasswordHash), arg0, arg1, arg2, arg3)
+}
+
+// RequestRole mocks base method.
+func (m *UserRepository) RequestRole(arg0 context.Context, arg1 string, arg2 security.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestRole indicates an expected call of RequestRole.
+func (mr *UserRepositoryMockRecorder) RequestRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRole", reflect.TypeOf((*UserRepository)(nil).RequestRole), arg0, arg1, arg2)
+}
+
+// ResetTokenUser mocks base method.
+func (m *UserRepository) ResetTokenUser(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetTokenUser", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetTokenUser indicates an expected call of ResetTokenUser.
+func (mr *UserRepositoryMockRecorder) ResetTokenUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetTokenUser", reflect.TypeOf((*UserRepository)(nil).ResetTokenUser), arg0, arg1)
+}
+
+// SetRole mocks base method.
+func (m *UserRepository) SetRole(arg0 context.Context, arg1 string, arg2 security.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *UserRepositoryMockRecorder) SetRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*UserRepository)(nil).SetRole), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *UserRepository) Update(arg0 context.Context, arg1 users.User) error {
 	m.ctrl.T.Helper()
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	security "github.com/artback/mvp/pkg/api/middleware/security"
 	users "github.com/artback/mvp/pkg/users"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,21 @@ func (m *UserService) EXPECT() *UserServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangeRole mocks base method.
+func (m *UserService) ChangeRole(arg0 context.Context, arg1 string, arg2 security.Role) (users.Transition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(users.Transition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeRole indicates an expected call of ChangeRole.
+func (mr *UserServiceMockRecorder) ChangeRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRole", reflect.TypeOf((*UserService)(nil).ChangeRole), arg0, arg1, arg2)
+}
+
 // Delete mocks base method.
 func (m *UserService) Delete(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -93,16 +109,58 @@ func (mr *UserServiceMockRecorder) Insert(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*UserService)(nil).Insert), arg0, arg1)
 }
 
-// Update mocks base method.
-func (m *UserService) Update(arg0 context.Context, arg1 users.User) error {
+// Patch mocks base method.
+func (m *UserService) Patch(arg0 context.Context, arg1 string, arg2 users.Patch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *UserServiceMockRecorder) Patch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*UserService)(nil).Patch), arg0, arg1, arg2)
+}
+
+// RehashPassword mocks base method.
+func (m *UserService) RehashPassword(arg0 context.Context, arg1 users.User, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *UserServiceMockRecorder) RehashPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*UserService)(nil).RehashPassword), arg0, arg1, arg2)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *UserService) RequestPasswordReset(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *UserServiceMockRecorder) RequestPasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*UserService)(nil).RequestPasswordReset), arg0, arg1)
+}
+
+// ResetPassword mocks base method.
+func (m *UserService) ResetPassword(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update.
-func (mr *UserServiceMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *UserServiceMockRecorder) ResetPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*UserService)(nil).Update), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*UserService)(nil).ResetPassword), arg0, arg1, arg2)
 }
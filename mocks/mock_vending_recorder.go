@@ -0,0 +1,60 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/vending (interfaces: Recorder)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	change "github.com/artback/mvp/pkg/change"
+	vending "github.com/artback/mvp/pkg/vending"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// VendingRecorder is a mock of Recorder interface.
+type VendingRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *VendingRecorderMockRecorder
+}
+
+// VendingRecorderMockRecorder is the mock recorder for VendingRecorder.
+type VendingRecorderMockRecorder struct {
+	mock *VendingRecorder
+}
+
+// NewVendingRecorder creates a new mock instance.
+func NewVendingRecorder(ctrl *gomock.Controller) *VendingRecorder {
+	mock := &VendingRecorder{ctrl: ctrl}
+	mock.recorder = &VendingRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *VendingRecorder) EXPECT() *VendingRecorderMockRecorder {
+	return m.recorder
+}
+
+// Deposited mocks base method.
+func (m *VendingRecorder) Deposited(arg0 change.Deposit) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Deposited", arg0)
+}
+
+// Deposited indicates an expected call of Deposited.
+func (mr *VendingRecorderMockRecorder) Deposited(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposited", reflect.TypeOf((*VendingRecorder)(nil).Deposited), arg0)
+}
+
+// Sold mocks base method.
+func (m *VendingRecorder) Sold(arg0 vending.Transaction) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Sold", arg0)
+}
+
+// Sold indicates an expected call of Sold.
+func (mr *VendingRecorderMockRecorder) Sold(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sold", reflect.TypeOf((*VendingRecorder)(nil).Sold), arg0)
+}
@@ -37,11 +37,12 @@ func (m *VendingRepsitory) EXPECT() *VendingRepsitoryMockRecorder {
 }
 
 // BuyProduct mocks base method.
-func (m *VendingRepsitory) BuyProduct(arg0 context.Context, arg1 string, arg2 products.Product) error {
+func (m *VendingRepsitory) BuyProduct(arg0 context.Context, arg1 string, arg2 products.Product) (*vending.Transaction, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BuyProduct", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*vending.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // BuyProduct indicates an expected call of BuyProduct.
@@ -65,6 +66,21 @@ func (mr *VendingRepsitoryMockRecorder) GetAccount(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*VendingRepsitory)(nil).GetAccount), arg0, arg1)
 }
 
+// GetTransaction mocks base method.
+func (m *VendingRepsitory) GetTransaction(arg0 context.Context, arg1 int) (*vending.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*vending.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *VendingRepsitoryMockRecorder) GetTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*VendingRepsitory)(nil).GetTransaction), arg0, arg1)
+}
+
 // IncrementDeposit mocks base method.
 func (m *VendingRepsitory) IncrementDeposit(arg0 context.Context, arg1 string, arg2 int) error {
 	m.ctrl.T.Helper()
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	change "github.com/artback/mvp/pkg/change"
 	products "github.com/artback/mvp/pkg/products"
 	vending "github.com/artback/mvp/pkg/vending"
 	gomock "github.com/golang/mock/gomock"
@@ -50,6 +51,20 @@ func (mr *VendingServiceMockRecorder) BuyProduct(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuyProduct", reflect.TypeOf((*VendingService)(nil).BuyProduct), arg0, arg1, arg2)
 }
 
+// Deposit mocks base method.
+func (m *VendingService) Deposit(arg0 context.Context, arg1 string, arg2 change.Deposit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *VendingServiceMockRecorder) Deposit(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*VendingService)(nil).Deposit), arg0, arg1, arg2)
+}
+
 // GetAccount mocks base method.
 func (m *VendingService) GetAccount(arg0 context.Context, arg1 string) (*vending.Response, error) {
 	m.ctrl.T.Helper()
@@ -65,18 +80,19 @@ func (mr *VendingServiceMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*VendingService)(nil).GetAccount), arg0, arg1)
 }
 
-// IncrementDeposit mocks base method.
-func (m *VendingService) IncrementDeposit(arg0 context.Context, arg1 string, arg2 int) error {
+// GetTransaction mocks base method.
+func (m *VendingService) GetTransaction(arg0 context.Context, arg1 int) (*vending.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrementDeposit", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*vending.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// IncrementDeposit indicates an expected call of IncrementDeposit.
-func (mr *VendingServiceMockRecorder) IncrementDeposit(arg0, arg1, arg2 interface{}) *gomock.Call {
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *VendingServiceMockRecorder) GetTransaction(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementDeposit", reflect.TypeOf((*VendingService)(nil).IncrementDeposit), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*VendingService)(nil).GetTransaction), arg0, arg1)
 }
 
 // SetDeposit mocks base method.
@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/webhook (interfaces: Repository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	webhook "github.com/artback/mvp/pkg/webhook"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// WebhookRepository is a mock of Repository interface.
+type WebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *WebhookRepositoryMockRecorder
+}
+
+// WebhookRepositoryMockRecorder is the mock recorder for WebhookRepository.
+type WebhookRepositoryMockRecorder struct {
+	mock *WebhookRepository
+}
+
+// NewWebhookRepository creates a new mock instance.
+func NewWebhookRepository(ctrl *gomock.Controller) *WebhookRepository {
+	mock := &WebhookRepository{ctrl: ctrl}
+	mock.recorder = &WebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *WebhookRepository) EXPECT() *WebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddEndpoint mocks base method.
+func (m *WebhookRepository) AddEndpoint(arg0 context.Context, arg1 webhook.Endpoint) (*webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEndpoint", arg0, arg1)
+	ret0, _ := ret[0].(*webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddEndpoint indicates an expected call of AddEndpoint.
+func (mr *WebhookRepositoryMockRecorder) AddEndpoint(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEndpoint", reflect.TypeOf((*WebhookRepository)(nil).AddEndpoint), arg0, arg1)
+}
+
+// Claim mocks base method.
+func (m *WebhookRepository) Claim(arg0 context.Context, arg1 int, arg2 time.Duration) ([]webhook.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]webhook.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *WebhookRepositoryMockRecorder) Claim(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*WebhookRepository)(nil).Claim), arg0, arg1, arg2)
+}
+
+// DeleteEndpoint mocks base method.
+func (m *WebhookRepository) DeleteEndpoint(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEndpoint", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEndpoint indicates an expected call of DeleteEndpoint.
+func (mr *WebhookRepositoryMockRecorder) DeleteEndpoint(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEndpoint", reflect.TypeOf((*WebhookRepository)(nil).DeleteEndpoint), arg0, arg1, arg2)
+}
+
+// Deliveries mocks base method.
+func (m *WebhookRepository) Deliveries(arg0 context.Context, arg1 string, arg2 int, arg3 webhook.Status) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *WebhookRepositoryMockRecorder) Deliveries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*WebhookRepository)(nil).Deliveries), arg0, arg1, arg2, arg3)
+}
+
+// Endpoints mocks base method.
+func (m *WebhookRepository) Endpoints(arg0 context.Context, arg1 string) ([]webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Endpoints", arg0, arg1)
+	ret0, _ := ret[0].([]webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Endpoints indicates an expected call of Endpoints.
+func (mr *WebhookRepositoryMockRecorder) Endpoints(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Endpoints", reflect.TypeOf((*WebhookRepository)(nil).Endpoints), arg0, arg1)
+}
+
+// Record mocks base method.
+func (m *WebhookRepository) Record(arg0 context.Context, arg1 int, arg2 webhook.Attempt, arg3 webhook.Status, arg4 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *WebhookRepositoryMockRecorder) Record(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*WebhookRepository)(nil).Record), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Redeliver mocks base method.
+func (m *WebhookRepository) Redeliver(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *WebhookRepositoryMockRecorder) Redeliver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*WebhookRepository)(nil).Redeliver), arg0, arg1, arg2)
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/artback/mvp/pkg/webhook (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	webhook "github.com/artback/mvp/pkg/webhook"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// WebhookService is a mock of Service interface.
+type WebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *WebhookServiceMockRecorder
+}
+
+// WebhookServiceMockRecorder is the mock recorder for WebhookService.
+type WebhookServiceMockRecorder struct {
+	mock *WebhookService
+}
+
+// NewWebhookService creates a new mock instance.
+func NewWebhookService(ctrl *gomock.Controller) *WebhookService {
+	mock := &WebhookService{ctrl: ctrl}
+	mock.recorder = &WebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *WebhookService) EXPECT() *WebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteEndpoint mocks base method.
+func (m *WebhookService) DeleteEndpoint(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEndpoint", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEndpoint indicates an expected call of DeleteEndpoint.
+func (mr *WebhookServiceMockRecorder) DeleteEndpoint(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEndpoint", reflect.TypeOf((*WebhookService)(nil).DeleteEndpoint), arg0, arg1, arg2)
+}
+
+// Deliveries mocks base method.
+func (m *WebhookService) Deliveries(arg0 context.Context, arg1 string, arg2 int, arg3 webhook.Status) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *WebhookServiceMockRecorder) Deliveries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*WebhookService)(nil).Deliveries), arg0, arg1, arg2, arg3)
+}
+
+// Endpoints mocks base method.
+func (m *WebhookService) Endpoints(arg0 context.Context, arg1 string) ([]webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Endpoints", arg0, arg1)
+	ret0, _ := ret[0].([]webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Endpoints indicates an expected call of Endpoints.
+func (mr *WebhookServiceMockRecorder) Endpoints(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Endpoints", reflect.TypeOf((*WebhookService)(nil).Endpoints), arg0, arg1)
+}
+
+// Redeliver mocks base method.
+func (m *WebhookService) Redeliver(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *WebhookServiceMockRecorder) Redeliver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*WebhookService)(nil).Redeliver), arg0, arg1, arg2)
+}
+
+// Register mocks base method.
+func (m *WebhookService) Register(arg0 context.Context, arg1, arg2 string, arg3 []webhook.Kind) (*webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *WebhookServiceMockRecorder) Register(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*WebhookService)(nil).Register), arg0, arg1, arg2, arg3)
+}
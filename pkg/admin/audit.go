@@ -0,0 +1,31 @@
+package admin
+
+import "time"
+
+type Action string
+
+const (
+	ChangeRole         Action = "change_role"
+	Lock               Action = "lock"
+	Unlock             Action = "unlock"
+	ForcePasswordReset Action = "force_password_reset"
+	AdjustDeposit      Action = "adjust_deposit"
+	ApproveRole        Action = "approve_role"
+	RejectRole         Action = "reject_role"
+	AddPolicy          Action = "add_policy"
+	RemovePolicy       Action = "remove_policy"
+	AddAssignment      Action = "add_role_assignment"
+	RemoveAssignment   Action = "remove_role_assignment"
+	ReloadPolicy       Action = "reload_policy"
+)
+
+// Entry is one recorded admin action
+type Entry struct {
+	ID        int       `json:"id"`
+	Actor     string    `json:"actor"`
+	Action    Action    `json:"action"`
+	Target    string    `json:"target"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/users"
+)
+
+//go:generate mockgen -destination=../../mocks/mock_admin_repository.go -mock_names=Repository=AdminRepository -package=mocks github.com/artback/mvp/pkg/admin Repository
+type Repository interface {
+	// Mutations record their Entry in the same transaction as the change
+	ListUsers(ctx context.Context, filter Filter) ([]User, error)
+	SetRole(ctx context.Context, entry Entry, role security.Role) error
+	SetLocked(ctx context.Context, entry Entry, locked bool) error
+	RequirePasswordReset(ctx context.Context, entry Entry) error
+	AdjustDeposit(ctx context.Context, entry Entry, amount int) error
+	AuditLog(ctx context.Context, target string) ([]Entry, error)
+	RoleRequests(ctx context.Context) ([]users.RoleRequest, error)
+	// DecideRoleRequest sets the Target of entry to the user that filed the request and returns it
+	DecideRoleRequest(ctx context.Context, entry Entry, id int, status users.RoleRequestStatus) (string, error)
+}
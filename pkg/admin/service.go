@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/users"
+)
+
+//go:generate mockgen -destination=../../mocks/mock_admin_service.go -mock_names=Service=AdminService -package=mocks github.com/artback/mvp/pkg/admin Service
+type Service interface {
+	ListUsers(ctx context.Context, filter Filter) ([]User, error)
+	SetRole(ctx context.Context, actor, username string, role security.Role, reason string) error
+	Lock(ctx context.Context, actor, username, reason string) error
+	Unlock(ctx context.Context, actor, username, reason string) error
+	ForcePasswordReset(ctx context.Context, actor, username, reason string) error
+	AdjustDeposit(ctx context.Context, actor, username string, amount int, reason string) error
+	AuditLog(ctx context.Context, target string) ([]Entry, error)
+	RoleRequests(ctx context.Context) ([]users.RoleRequest, error)
+	ApproveRoleRequest(ctx context.Context, actor string, id int, reason string) error
+	RejectRoleRequest(ctx context.Context, actor string, id int, reason string) error
+}
@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+var (
+	MissingReasonErr = errors.New("a reason is required")
+	SelfActionErr    = errors.New("admins can not perform this action on their own account")
+)
+
+// User is the admin view of an account, it never carries the password hash
+type User struct {
+	Username      string        `json:"username"`
+	Role          security.Role `json:"role"`
+	Deposit       int           `json:"deposit"`
+	Locked        bool          `json:"locked"`
+	ResetRequired bool          `json:"resetRequired"`
+}
+
+// Filter narrows down ListUsers, empty fields match everything
+type Filter struct {
+	Query  string
+	Role   security.Role
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,62 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artback/mvp/pkg/notify"
+)
+
+var InvalidThresholdErr = errors.New("reorder threshold can't be negative")
+
+// Kind is an alert the check_stock trigger of db/init.sql raises on every change to a stock or reorder threshold
+type Kind string
+
+const (
+	// LowStock is raised when the stock of a product is at or below a reorder threshold above 0 and wasn't before
+	LowStock Kind = "low_stock"
+	// SoldOut is raised when the last item of a product is gone
+	SoldOut Kind = "sold_out"
+)
+
+type Status string
+
+const (
+	Open         Status = "open"
+	Acknowledged Status = "acknowledged"
+)
+
+// Alert tells a seller about the stock of one product, a product has at most one open alert of each kind
+type Alert struct {
+	ID             int        `json:"id"`
+	Product        string     `json:"product"`
+	SellerID       string     `json:"sellerId"`
+	Kind           Kind       `json:"kind"`
+	Remaining      int        `json:"remaining"`
+	Threshold      int        `json:"threshold"`
+	Status         Status     `json:"status"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+}
+
+// Notice is an alert on its way to the contact of its seller, Contact is empty when the seller has none
+type Notice struct {
+	Alert
+	Contact string
+}
+
+// Message is what the seller is told about the alert
+func (n Notice) Message() notify.Message {
+	msg := notify.Message{To: n.Contact}
+	switch n.Kind {
+	case SoldOut:
+		msg.Subject = fmt.Sprintf("%s is sold out", n.Product)
+		msg.Body = fmt.Sprintf("The last %s was just bought.", n.Product)
+	default:
+		msg.Subject = fmt.Sprintf("%s is running low", n.Product)
+		msg.Body = fmt.Sprintf("Only %d of %s are left, the reorder threshold is %d.", n.Remaining, n.Product, n.Threshold)
+	}
+
+	return msg
+}
@@ -0,0 +1,16 @@
+package alert
+
+import "context"
+
+//go:generate mockgen -destination=../../mocks/mock_alert_repository.go -mock_names=Repository=AlertRepository -package=mocks github.com/artback/mvp/pkg/alert Repository
+type Repository interface {
+	// SetThreshold changes the reorder threshold of a product of the seller
+	SetThreshold(ctx context.Context, sellerID, product string, threshold int) error
+	// Alerts of the seller, newest first, an empty status lists every status
+	Alerts(ctx context.Context, sellerID string, status Status) ([]Alert, error)
+	Acknowledge(ctx context.Context, sellerID string, id int) error
+	// Unnotified hands out up to limit alerts whose seller wasn't told yet and marks them as told
+	Unnotified(ctx context.Context, limit int) ([]Notice, error)
+	// Renotify makes an alert wait for Unnotified again, after telling its seller failed
+	Renotify(ctx context.Context, id int) error
+}
@@ -0,0 +1,64 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/artback/mvp/pkg/notify"
+	"github.com/sirupsen/logrus"
+)
+
+// Sender tells sellers about their alerts through Notifier. Alerts are written with the purchase that raised them,
+// the sender picks them up afterwards so a slow notifier never holds up a purchase.
+type Sender struct {
+	Repository Repository
+	Notifier   notify.Notifier
+	Batch      int
+	Interval   time.Duration
+}
+
+// Run sends every Interval until ctx is done
+func (s Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Send(ctx); err != nil {
+			logger.From(ctx).WithError(err).Error("send stock alerts")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Send tells the sellers about one batch of alerts and returns how many were told
+func (s Sender) Send(ctx context.Context) (int, error) {
+	notices, err := s.Repository.Unnotified(ctx, s.Batch)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, n := range notices {
+		if n.Contact == "" {
+			// nobody to tell, the alert is still listed under /v1/alerts
+			continue
+		}
+		if err := s.Notifier.Notify(ctx, n.Message()); err != nil {
+			log := logger.From(ctx).WithFields(logrus.Fields{"seller": n.SellerID, "alert_id": n.ID})
+			log.WithError(err).Warn("notify seller about alert")
+			if err := s.Repository.Renotify(ctx, n.ID); err != nil {
+				log.WithError(err).Error("renotify alert")
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
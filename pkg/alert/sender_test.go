@@ -0,0 +1,61 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/notify"
+	"github.com/golang/mock/gomock"
+)
+
+func TestSender_Send(t *testing.T) {
+	t.Parallel()
+
+	lowStock := alert.Notice{Alert: alert.Alert{ID: 1, Product: "cola", SellerID: "sam", Kind: alert.LowStock, Remaining: 2, Threshold: 3}, Contact: "sam@example.com"}
+	soldOut := alert.Notice{Alert: alert.Alert{ID: 2, Product: "fanta", SellerID: "sam", Kind: alert.SoldOut}, Contact: "sam@example.com"}
+	noContact := alert.Notice{Alert: alert.Alert{ID: 3, Product: "sprite", SellerID: "sven", Kind: alert.SoldOut}}
+
+	tests := []struct {
+		name      string
+		notices   []alert.Notice
+		notifyErr error
+		notified  int
+		renotify  int
+		want      int
+	}{
+		{name: "nothing to send"},
+		{name: "sent", notices: []alert.Notice{lowStock, soldOut}, notified: 2, want: 2},
+		{name: "seller without contact", notices: []alert.Notice{noContact}},
+		{name: "notifier fails", notices: []alert.Notice{lowStock}, notifyErr: errors.New("smtp down"), notified: 1, renotify: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewAlertRepository(mockCtrl)
+			r.EXPECT().Unnotified(gomock.Any(), 10).Return(tt.notices, nil)
+			r.EXPECT().Renotify(gomock.Any(), lowStock.ID).Return(nil).Times(tt.renotify)
+			n := mocks.NewNotifier(mockCtrl)
+			n.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, msg notify.Message) error {
+				if msg.To != "sam@example.com" || msg.Subject == "" {
+					t.Errorf("Notify() message = %+v, want one for sam@example.com", msg)
+				}
+				return tt.notifyErr
+			}).Times(tt.notified)
+
+			got, err := alert.Sender{Repository: r, Notifier: n, Batch: 10}.Send(context.Background())
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Send() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
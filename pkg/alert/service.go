@@ -0,0 +1,10 @@
+package alert
+
+import "context"
+
+//go:generate mockgen -destination=../../mocks/mock_alert_service.go -mock_names=Service=AlertService -package=mocks github.com/artback/mvp/pkg/alert Service
+type Service interface {
+	SetThreshold(ctx context.Context, sellerID, product string, threshold int) error
+	Alerts(ctx context.Context, sellerID string, status Status) ([]Alert, error)
+	Acknowledge(ctx context.Context, sellerID string, id int) error
+}
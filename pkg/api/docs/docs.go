@@ -0,0 +1,32 @@
+// Package docs serves the OpenAPI document of the api and a page to browse it.
+// openapi.json is written by hand, handler.TestOpenAPI fails when it drifts from the router.
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+var (
+	//go:embed openapi.json
+	spec []byte
+	//go:embed index.html
+	page []byte
+)
+
+// Spec returns the OpenAPI 3 document
+func Spec() []byte {
+	return spec
+}
+
+// SpecHandler serves the OpenAPI 3 document
+func SpecHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(spec)
+}
+
+// UIHandler serves a page rendering the document, it needs nothing but the api itself
+func UIHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(page)
+}
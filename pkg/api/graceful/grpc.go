@@ -0,0 +1,46 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// GRPC serves a grpc.Server on Addr. Shutdown lets running calls finish, the calls left when ctx is done are cancelled.
+type GRPC struct {
+	*grpc.Server
+	Addr string
+}
+
+func (g GRPC) ListenAndServe() error {
+	listener, err := net.Listen("tcp", g.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", g.Addr, err)
+	}
+
+	err = g.Server.Serve(listener)
+	if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("unexpected error from Serve: %w", err)
+	}
+
+	return nil
+}
+
+func (g GRPC) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		g.Server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		g.Server.Stop()
+		return fmt.Errorf("running calls were cancelled: %w", ctx.Err())
+	}
+}
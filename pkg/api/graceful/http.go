@@ -0,0 +1,38 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// HTTP serves an http.Server, over TLS when it has a TLSConfig.
+// Shutdown waits for running requests, the connections left when ctx is done are closed.
+type HTTP struct {
+	*http.Server
+}
+
+func (h HTTP) ListenAndServe() error {
+	var err error
+	if h.TLSConfig != nil {
+		// the certificate comes from TLSConfig
+		err = h.Server.ListenAndServeTLS("", "")
+	} else {
+		err = h.Server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		// expected error after calling Server.Shutdown().
+		return nil
+	}
+
+	return err
+}
+
+func (h HTTP) Shutdown(ctx context.Context) error {
+	err := h.Server.Shutdown(ctx)
+	if err != nil {
+		_ = h.Server.Close()
+	}
+
+	return err
+}
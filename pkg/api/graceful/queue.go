@@ -0,0 +1,58 @@
+package graceful
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Queue runs work that outlives its request on a fixed number of goroutines, a burst of requests
+// can't start more than that. Run it as a Worker: the running jobs finish on shutdown, the queued ones are dropped.
+type Queue struct {
+	workers int
+	// timeout bounds every job, the job keeps running when the request that pushed it is done
+	timeout time.Duration
+	jobs    chan func(ctx context.Context)
+}
+
+// NewQueue returns a queue holding up to size jobs for workers goroutines
+func NewQueue(workers, size int, timeout time.Duration) *Queue {
+	return &Queue{workers: workers, timeout: timeout, jobs: make(chan func(ctx context.Context), size)}
+}
+
+// Push queues job, false when the queue is full and job was dropped
+func (q *Queue) Push(job func(ctx context.Context)) bool {
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run runs the queued jobs until ctx is done, then waits for the running ones
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-q.jobs:
+					q.run(job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) run(job func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	defer cancel()
+
+	job(ctx)
+}
@@ -1,57 +1,189 @@
+// Package graceful runs the listeners of the service side by side and shuts them down in order:
+// a signal drains traffic, then every listener stops taking new work and finishes what it has,
+// only then the hooks release what the listeners were using, like the database.
 package graceful
 
 import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"net/http"
-	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
+
+	"github.com/artback/mvp/pkg/logger"
 )
 
+// StoppedErr is reported for a listener that stopped serving before the shutdown
+var StoppedErr = errors.New("stopped before the shutdown")
+
+// Listener is served by a Server until it is shut down
+type Listener interface {
+	// ListenAndServe blocks while the listener serves, it returns nil once Shutdown was called
+	ListenAndServe() error
+	// Shutdown stops taking new work and waits for the running work until ctx is done
+	Shutdown(ctx context.Context) error
+}
+
+// Hook releases something the listeners were using, it runs after every listener stopped
+type Hook func(ctx context.Context) error
+
+// Errors are everything that went wrong while serving and shutting down
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+type named struct {
+	name     string
+	listener Listener
+}
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Server is the lifecycle of the service. It serves until SIGTERM, SIGINT, the end of the context given to Run
+// or until a listener stops on its own, which takes the others down with it.
 type Server struct {
-	*http.Server
-	shutdownFinished chan struct{}
+	// OnSignal runs as soon as the shutdown starts, the listeners keep taking requests for DrainDelay afterwards
+	// so load balancers have time to notice the failing readiness probe and move traffic away
+	OnSignal   func()
+	DrainDelay time.Duration
+	// Timeout bounds the shutdown of the listeners, running work still left is cancelled.
+	// The hooks get another Timeout.
+	Timeout   time.Duration
+	listeners []named
+	hooks     []namedHook
+}
+
+// Listen adds l to the listeners started by Run, name tells it apart in logs and errors
+func (s *Server) Listen(name string, l Listener) {
+	s.listeners = append(s.listeners, named{name: name, listener: l})
+}
+
+// RegisterOnShutdown adds a hook, hooks run one after another in the order they were registered
+func (s *Server) RegisterOnShutdown(name string, hook Hook) {
+	s.hooks = append(s.hooks, namedHook{name: name, hook: hook})
 }
 
-func (s *Server) ListenAndServe() (err error) {
-	if s.shutdownFinished == nil {
-		s.shutdownFinished = make(chan struct{})
+type result struct {
+	name string
+	err  error
+}
+
+// Run serves every listener and returns once all of them are shut down and the hooks ran.
+// The error is nil or Errors, a listener failing to serve or to shut down doesn't keep the others from stopping.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	log := logger.Default()
+
+	served := make(chan result, len(s.listeners))
+	for _, l := range s.listeners {
+		l := l
+		log.WithField("listener", l.name).Info("serving")
+		go func() {
+			served <- result{name: l.name, err: l.listener.ListenAndServe()}
+		}()
 	}
 
-	err = s.Server.ListenAndServe()
-	if errors.Is(err, http.ErrServerClosed) {
-		// expected error after calling Server.Shutdown().
-		err = nil
-	} else if err != nil {
-		return fmt.Errorf("unexpected error from ListenAndServe: %w", err)
+	var errs Errors
+	running := len(s.listeners)
+	select {
+	case <-ctx.Done():
+		log.Info("shutting down")
+	case r := <-served:
+		running--
+		if r.err == nil {
+			r.err = StoppedErr
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+		log.WithError(r.err).WithField("listener", r.name).Error("listener failed, shutting down")
 	}
+	// a second signal kills the process right away
+	stop()
 
-	log.Println("waiting for shutdown finishing...")
-	<-s.shutdownFinished
-	log.Println("shutdown finished")
+	if s.OnSignal != nil {
+		s.OnSignal()
+	}
+	if s.DrainDelay > 0 && len(errs) == 0 {
+		log.Infof("draining for %v", s.DrainDelay)
+		time.Sleep(s.DrainDelay)
+	}
 
-	return
+	errs = append(errs, s.shutdown(served, running)...)
+	errs = append(errs, s.runHooks()...)
+	if len(errs) > 0 {
+		return errs
+	}
+	log.Info("shutdown finished")
+
+	return nil
 }
 
-func (s *Server) WaitForExitingSignal(timeout time.Duration) {
-	waiter := make(chan os.Signal, 1) // buffered channel
-	signal.Notify(waiter, syscall.SIGTERM, syscall.SIGINT)
+// shutdown stops every listener at once and waits for the running ones to return from ListenAndServe
+func (s *Server) shutdown(served <-chan result, running int) Errors {
+	ctx, cancel := s.deadline()
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		errs Errors
+		wg   sync.WaitGroup
+	)
+	for _, l := range s.listeners {
+		l := l
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.listener.Shutdown(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("shut down %s: %w", l.name, err))
+				mu.Unlock()
+				return
+			}
+			logger.Default().WithField("listener", l.name).Info("shut down")
+		}()
+	}
+	wg.Wait()
 
-	// blocks here until there's a signal
-	<-waiter
+	for ; running > 0; running-- {
+		if r := <-served; r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+		}
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return errs
+}
+
+func (s *Server) runHooks() Errors {
+	ctx, cancel := s.deadline()
 	defer cancel()
 
-	err := s.Server.Shutdown(ctx)
-	if err != nil {
-		log.Println("shutting down: " + err.Error())
-	} else {
-		log.Println("shutdown processed successfully")
-		close(s.shutdownFinished)
+	var errs Errors
+	for _, h := range s.hooks {
+		if err := h.hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
 	}
+
+	return errs
+}
+
+func (s *Server) deadline() (context.Context, context.CancelFunc) {
+	if s.Timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), s.Timeout)
 }
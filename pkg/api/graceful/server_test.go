@@ -0,0 +1,268 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeListener serves until it is shut down, or fails right away with err
+type fakeListener struct {
+	err      error
+	shutErr  error
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newFakeListener(err, shutErr error) *fakeListener {
+	return &fakeListener{err: err, shutErr: shutErr, stopped: make(chan struct{})}
+}
+
+func (f *fakeListener) ListenAndServe() error {
+	if f.err != nil {
+		return f.err
+	}
+	<-f.stopped
+
+	return nil
+}
+
+func (f *fakeListener) Shutdown(context.Context) error {
+	f.stopOnce.Do(func() { close(f.stopped) })
+
+	return f.shutErr
+}
+
+func TestServer_Run(t *testing.T) {
+	t.Parallel()
+
+	failed := errors.New("address already in use")
+	tests := []struct {
+		name      string
+		listeners []*fakeListener
+		// signal ends the context given to Run, like a signal would
+		signal   bool
+		hookErr  error
+		wantErrs []string
+	}{
+		{name: "shut down", listeners: []*fakeListener{newFakeListener(nil, nil), newFakeListener(nil, nil)}, signal: true},
+		{name: "listener fails", listeners: []*fakeListener{newFakeListener(nil, nil), newFakeListener(failed, nil)},
+			wantErrs: []string{"b: address already in use"}},
+		{name: "shutdown fails", listeners: []*fakeListener{newFakeListener(nil, context.DeadlineExceeded)}, signal: true,
+			wantErrs: []string{"shut down a: context deadline exceeded"}},
+		{name: "hook fails", listeners: []*fakeListener{newFakeListener(nil, nil)}, signal: true, hookErr: failed,
+			wantErrs: []string{"second: address already in use"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var (
+				mu       sync.Mutex
+				hooks    []string
+				signaled bool
+			)
+			s := Server{OnSignal: func() { signaled = true }, Timeout: time.Second}
+			for i, l := range tt.listeners {
+				s.Listen(string(rune('a'+i)), l)
+			}
+			s.RegisterOnShutdown("first", func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				// hooks only run once every listener is shut down
+				for _, l := range tt.listeners {
+					select {
+					case <-l.stopped:
+					default:
+						if l.err == nil {
+							t.Error("hook ran before the listeners were shut down")
+						}
+					}
+				}
+				hooks = append(hooks, "first")
+				return nil
+			})
+			s.RegisterOnShutdown("second", func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				hooks = append(hooks, "second")
+				return tt.hookErr
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.signal {
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+			err := s.Run(ctx)
+
+			var got []string
+			if err != nil {
+				for _, e := range err.(Errors) {
+					got = append(got, e.Error())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Errorf("Run() errors = %q, want %q", got, tt.wantErrs)
+			}
+			if !reflect.DeepEqual(hooks, []string{"first", "second"}) {
+				t.Errorf("hooks ran as %v, want first then second", hooks)
+			}
+			if !signaled {
+				t.Error("OnSignal wasn't called")
+			}
+		})
+	}
+}
+
+func TestServer_RunDrains(t *testing.T) {
+	t.Parallel()
+	var (
+		mu       sync.Mutex
+		answered int
+	)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		answered++
+		mu.Unlock()
+	})}
+	s := Server{DrainDelay: 50 * time.Millisecond, Timeout: time.Second}
+	s.Listen("http", listening{HTTP: HTTP{Server: api}, ln: ln})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	drained := make(chan struct{})
+	s.OnSignal = func() {
+		go func() {
+			defer close(drained)
+			// the server still answers while draining
+			resp, err := http.Get("http://" + ln.Addr().String())
+			if err != nil {
+				t.Errorf("request while draining: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-drained
+	if answered != 1 {
+		t.Errorf("answered %d requests while draining, want 1", answered)
+	}
+}
+
+// listening serves on a listener opened by the test, so the test knows its address
+type listening struct {
+	HTTP
+	ln net.Listener
+}
+
+func (l listening) ListenAndServe() error {
+	if err := l.Server.Serve(l.ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context)
+		wantErr error
+	}{
+		{name: "stops with its context", run: func(ctx context.Context) { <-ctx.Done() }},
+		{name: "abandoned", run: func(context.Context) { time.Sleep(time.Second) }, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := NewWorker(context.Background(), tt.run)
+			served := make(chan error)
+			go func() { served <- w.ListenAndServe() }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if err := w.Shutdown(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Shutdown() error = %v, want %v", err, tt.wantErr)
+			}
+			select {
+			case err := <-served:
+				if err != nil {
+					t.Errorf("ListenAndServe() error = %v", err)
+				}
+			case <-time.After(time.Second / 2):
+				t.Error("ListenAndServe() didn't return after Shutdown")
+			}
+		})
+	}
+}
+
+func TestErrors_Error(t *testing.T) {
+	t.Parallel()
+	err := Errors{errors.New("http: closed"), errors.New("database: gone")}
+	if got, want := err.Error(), "http: closed; database: gone"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	q := NewQueue(1, 1, time.Second)
+	release, ran := make(chan struct{}), make(chan int, 3)
+	job := func(i int) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			<-release
+			ran <- i
+		}
+	}
+
+	// the first job blocks the only worker, the second fills the queue
+	if !q.Push(job(1)) {
+		t.Fatal("Push() refused the first job")
+	}
+	w := NewWorker(context.Background(), q.Run)
+	go func() { _ = w.ListenAndServe() }()
+	deadline := time.Now().Add(time.Second)
+	for !q.Push(job(2)) {
+		if time.Now().After(deadline) {
+			t.Fatal("the worker never took the first job")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if q.Push(job(3)) {
+		t.Error("Push() = true on a full queue, want false")
+	}
+
+	close(release)
+	for _, want := range []int{1, 2} {
+		select {
+		case got := <-ran:
+			if got != want {
+				t.Errorf("ran job %d, want %d", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("job %d never ran", want)
+		}
+	}
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
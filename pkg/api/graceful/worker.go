@@ -0,0 +1,50 @@
+package graceful
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker is a background loop run as a listener, it runs until its context is cancelled.
+// Shutdown cancels the context and waits for the loop to return, a loop still running when Shutdown
+// gives up is abandoned.
+type Worker struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	run       func(ctx context.Context)
+	done      chan struct{}
+	abandoned chan struct{}
+	abandon   sync.Once
+}
+
+// NewWorker returns a worker running run with a context derived from ctx
+func NewWorker(ctx context.Context, run func(ctx context.Context)) *Worker {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &Worker{ctx: ctx, cancel: cancel, run: run, done: make(chan struct{}), abandoned: make(chan struct{})}
+}
+
+func (w *Worker) ListenAndServe() error {
+	go func() {
+		defer close(w.done)
+		w.run(w.ctx)
+	}()
+
+	select {
+	case <-w.done:
+	case <-w.abandoned:
+	}
+
+	return nil
+}
+
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.abandon.Do(func() { close(w.abandoned) })
+		return ctx.Err()
+	}
+}
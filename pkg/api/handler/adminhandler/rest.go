@@ -0,0 +1,187 @@
+package adminhandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+var InvalidActionFormErr = fmt.Errorf("%w: invalid admin action form", problem.MalformedErr)
+
+type RestHandler struct {
+	admin.Service
+}
+
+// actionRequest is the body shared by all admin actions, fields not used by an action are ignored
+type actionRequest struct {
+	Role   security.Role `json:"role"`
+	Amount int           `json:"amount"`
+	Reason string        `json:"reason"`
+}
+
+func decodeAction(r *http.Request) (actionRequest, error) {
+	req := actionRequest{}
+
+	return req, validate.Decode(r, &req)
+}
+
+func (rest RestHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := validate.NewQuery(r)
+	filter := admin.Filter{
+		Query:  r.URL.Query().Get("q"),
+		Role:   security.Role(r.URL.Query().Get("role")),
+		Limit:  query.Int("limit", 0, 0),
+		Offset: query.Int("offset", 0, 0),
+	}
+	if err := query.Err(); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	list, err := rest.Service.ListUsers(r.Context(), filter)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&list); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	if err := rest.setRole(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) setRole(r *http.Request) error {
+	req, err := decodeAction(r)
+	if err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return rest.Service.SetRole(r.Context(), actor, chi.URLParam(r, "username"), req.Role, req.Reason)
+}
+
+func (rest RestHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	if err := rest.lock(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) lock(r *http.Request) error {
+	req, err := decodeAction(r)
+	if err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return rest.Service.Lock(r.Context(), actor, chi.URLParam(r, "username"), req.Reason)
+}
+
+func (rest RestHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	if err := rest.unlock(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) unlock(r *http.Request) error {
+	req, err := decodeAction(r)
+	if err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return rest.Service.Unlock(r.Context(), actor, chi.URLParam(r, "username"), req.Reason)
+}
+
+func (rest RestHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	if err := rest.forcePasswordReset(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) forcePasswordReset(r *http.Request) error {
+	req, err := decodeAction(r)
+	if err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return rest.Service.ForcePasswordReset(r.Context(), actor, chi.URLParam(r, "username"), req.Reason)
+}
+
+func (rest RestHandler) AdjustDeposit(w http.ResponseWriter, r *http.Request) {
+	if err := rest.adjustDeposit(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) adjustDeposit(r *http.Request) error {
+	req, err := decodeAction(r)
+	if err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return rest.Service.AdjustDeposit(r.Context(), actor, chi.URLParam(r, "username"), req.Amount, req.Reason)
+}
+
+func (rest RestHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := rest.Service.AuditLog(r.Context(), r.URL.Query().Get("target"))
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&entries); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) RoleRequests(w http.ResponseWriter, r *http.Request) {
+	requests, err := rest.Service.RoleRequests(r.Context())
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&requests); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) ApproveRoleRequest(w http.ResponseWriter, r *http.Request) {
+	if err := rest.decideRoleRequest(r, rest.Service.ApproveRoleRequest); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) RejectRoleRequest(w http.ResponseWriter, r *http.Request) {
+	if err := rest.decideRoleRequest(r, rest.Service.RejectRoleRequest); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) decideRoleRequest(r *http.Request, decide func(ctx context.Context, actor string, id int, reason string) error) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return InvalidActionFormErr
+	}
+	req, err := decodeAction(r)
+	if err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return decide(r.Context(), actor, id, req.Reason)
+}
@@ -0,0 +1,158 @@
+package adminhandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+)
+
+type ServiceResponse struct {
+	times int
+	err   error
+}
+
+func withUsername(r *http.Request, username string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("username", username)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestController_SetRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body []byte
+		ServiceResponse
+		want int
+	}{
+		{
+			name:            "successful",
+			body:            []byte(`{"role": "seller","reason": "approved"}`),
+			ServiceResponse: ServiceResponse{times: 1},
+			want:            http.StatusOK,
+		},
+		{
+			name:            "unsuccessful, json decoder",
+			body:            []byte(`{"role: "seller"}`),
+			ServiceResponse: ServiceResponse{times: 0},
+			want:            http.StatusBadRequest,
+		},
+		{
+			name:            "unsuccessful, missing reason",
+			body:            []byte(`{"role": "seller"}`),
+			ServiceResponse: ServiceResponse{times: 1, err: admin.MissingReasonErr},
+			want:            http.StatusBadRequest,
+		},
+		{
+			name:            "unsuccessful, own account",
+			body:            []byte(`{"role": "seller","reason": "approved"}`),
+			ServiceResponse: ServiceResponse{times: 1, err: admin.SelfActionErr},
+			want:            http.StatusForbidden,
+		},
+		{
+			name:            "unsuccessful, unknown user",
+			body:            []byte(`{"role": "seller","reason": "approved"}`),
+			ServiceResponse: ServiceResponse{times: 1, err: repository.EmptyError{}},
+			want:            http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewAdminService(mockCtrl)
+			s.EXPECT().SetRole(gomock.Any(), "root", "mike", security.Seller, gomock.Any()).Return(tt.err).Times(tt.times)
+			co := RestHandler{Service: s}
+			ctx := security.WithUser(context.Background(), security.User{Username: "root", Role: security.Admin})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPut, "/", bytes.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			co.SetRole(w, withUsername(req, "mike"))
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_AdjustDeposit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body []byte
+		ServiceResponse
+		want int
+	}{
+		{
+			name:            "successful",
+			body:            []byte(`{"amount": -50,"reason": "refund"}`),
+			ServiceResponse: ServiceResponse{times: 1},
+			want:            http.StatusOK,
+		},
+		{
+			name:            "unsuccessful, negative deposit",
+			body:            []byte(`{"amount": -50,"reason": "refund"}`),
+			ServiceResponse: ServiceResponse{times: 1, err: repository.InvalidError{Title: "deposit can not be negative"}},
+			want:            http.StatusNotAcceptable,
+		},
+		{
+			name:            "unsuccessful, error service",
+			body:            []byte(`{"amount": -50,"reason": "refund"}`),
+			ServiceResponse: ServiceResponse{times: 1, err: errors.New("something happened")},
+			want:            http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewAdminService(mockCtrl)
+			s.EXPECT().AdjustDeposit(gomock.Any(), "root", "mike", -50, "refund").Return(tt.err).Times(tt.times)
+			co := RestHandler{Service: s}
+			ctx := security.WithUser(context.Background(), security.User{Username: "root", Role: security.Admin})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			co.AdjustDeposit(w, withUsername(req, "mike"))
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	s := mocks.NewAdminService(mockCtrl)
+	s.EXPECT().ListUsers(gomock.Any(), admin.Filter{Query: "mi", Role: security.Buyer, Limit: 10, Offset: 20}).
+		Return([]admin.User{{Username: "mike", Role: security.Buyer}}, nil).Times(1)
+	co := RestHandler{Service: s}
+	req, _ := http.NewRequest(http.MethodGet, "/?q=mi&role=buyer&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+	co.ListUsers(w, req)
+	if status := w.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
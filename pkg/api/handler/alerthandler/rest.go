@@ -0,0 +1,82 @@
+package alerthandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+var InvalidIDErr = fmt.Errorf("%w: invalid alert id", problem.MalformedErr)
+
+type RestHandler struct {
+	alert.Service
+}
+
+type thresholdRequest struct {
+	Threshold int `json:"threshold" validate:"min=0"`
+}
+
+func (rest RestHandler) SetThreshold(w http.ResponseWriter, r *http.Request) {
+	if err := rest.setThreshold(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) setThreshold(r *http.Request) error {
+	req := thresholdRequest{}
+	if err := validate.Decode(r, &req); err != nil {
+		return err
+	}
+	seller := security.GetUser(r.Context()).Username
+
+	return rest.Service.SetThreshold(r.Context(), seller, chi.URLParam(r, "product_name"), req.Threshold)
+}
+
+func (rest RestHandler) Alerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := rest.alerts(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&alerts); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+// alerts lists the open alerts unless another status is asked for
+func (rest RestHandler) alerts(r *http.Request) ([]alert.Alert, error) {
+	status := alert.Status(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = alert.Open
+	case alert.Open, alert.Acknowledged:
+	default:
+		return nil, problem.ValidationError{Fields: []problem.FieldError{
+			{Field: "status", Code: "oneof", Message: "must be one of open, acknowledged"},
+		}}
+	}
+
+	return rest.Service.Alerts(r.Context(), security.GetUser(r.Context()).Username, status)
+}
+
+func (rest RestHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	if err := rest.acknowledge(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) acknowledge(r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return InvalidIDErr
+	}
+
+	return rest.Service.Acknowledge(r.Context(), security.GetUser(r.Context()).Username, id)
+}
@@ -0,0 +1,125 @@
+package alerthandler
+
+import (
+	"bytes"
+	"context"
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestHandler_SetThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		times     int
+		threshold int
+		err       error
+		want      int
+	}{
+		{name: "threshold", body: `{"threshold":5}`, times: 1, threshold: 5, want: http.StatusOK},
+		{name: "disabled", body: `{"threshold":0}`, times: 1, want: http.StatusOK},
+		{name: "negative", body: `{"threshold":-1}`, want: http.StatusBadRequest},
+		{name: "product of another seller", body: `{"threshold":5}`, times: 1, threshold: 5, err: repository.EmptyError{}, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewAlertService(mockCtrl)
+			s.EXPECT().SetThreshold(gomock.Any(), "sam", "cola", tt.threshold).Return(tt.err).Times(tt.times)
+			ctx := security.WithUser(context.Background(), security.User{Username: "sam", Role: security.Seller})
+			route := chi.NewRouteContext()
+			route.URLParams.Add("product_name", "cola")
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, route)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPut, "/", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+			RestHandler{Service: s}.SetThreshold(w, req)
+			if w.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestHandler_Alerts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		query  string
+		times  int
+		status alert.Status
+		want   int
+	}{
+		{name: "open by default", times: 1, status: alert.Open, want: http.StatusOK},
+		{name: "acknowledged", query: "?status=acknowledged", times: 1, status: alert.Acknowledged, want: http.StatusOK},
+		{name: "unknown status", query: "?status=closed", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewAlertService(mockCtrl)
+			s.EXPECT().Alerts(gomock.Any(), "sam", tt.status).Return([]alert.Alert{}, nil).Times(tt.times)
+			ctx := security.WithUser(context.Background(), security.User{Username: "sam", Role: security.Seller})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/"+tt.query, nil)
+			w := httptest.NewRecorder()
+			RestHandler{Service: s}.Alerts(w, req)
+			if w.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestHandler_Acknowledge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		id    string
+		times int
+		err   error
+		want  int
+	}{
+		{name: "acknowledged", id: "1", times: 1, want: http.StatusOK},
+		{name: "invalid id", id: "one", want: http.StatusBadRequest},
+		{name: "already acknowledged", id: "2", times: 1, err: repository.EmptyError{}, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewAlertService(mockCtrl)
+			s.EXPECT().Acknowledge(gomock.Any(), "sam", gomock.Any()).Return(tt.err).Times(tt.times)
+			ctx := security.WithUser(context.Background(), security.User{Username: "sam", Role: security.Seller})
+			route := chi.NewRouteContext()
+			route.URLParams.Add("id", tt.id)
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, route)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+			w := httptest.NewRecorder()
+			RestHandler{Service: s}.Acknowledge(w, req)
+			if w.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
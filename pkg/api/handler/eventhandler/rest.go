@@ -0,0 +1,192 @@
+package eventhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/artback/mvp/pkg/api/middleware/logging"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/vending"
+	"net/http"
+	"time"
+)
+
+// DefaultHeartbeat keeps idle streams open through proxies that close silent connections
+const DefaultHeartbeat = 15 * time.Second
+
+// DefaultLoadTimeout bounds loading one product or deposit, the stream itself has no deadline
+const DefaultLoadTimeout = 5 * time.Second
+
+// retry tells clients how long to wait before reconnecting, they get a fresh snapshot then
+const retry = 3 * time.Second
+
+// RestHandler streams changes as server-sent events
+type RestHandler struct {
+	Bus       *events.Bus
+	Products  products.Service
+	Vending   vending.Service
+	Heartbeat time.Duration
+	// LoadTimeout bounds every snapshot the stream loads, so none holds a database connection for long
+	LoadTimeout time.Duration
+}
+
+// Events sends the watched products and the deposit of a buyer, then every change of them until the client leaves.
+// Without product parameters every product change is sent.
+func (re RestHandler) Events(w http.ResponseWriter, r *http.Request) {
+	query := validate.NewQuery(r)
+	names := query.Strings("product", 64)
+	if err := query.Err(); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(w, r, errors.New("response writer can't stream"))
+		return
+	}
+
+	s := stream{
+		RestHandler: re,
+		w:           w,
+		r:           r,
+		watched:     make(map[string]bool, len(names)),
+		buyer:       security.GetUser(r.Context()),
+	}
+	for _, name := range names {
+		s.watched[name] = true
+	}
+	if s.buyer.Role != security.Buyer {
+		s.buyer.Username = ""
+	}
+
+	// subscribe before the snapshot is loaded, a change in between is sent twice rather than lost
+	subscription := re.Bus.Subscribe()
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds())
+
+	heartbeat := re.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	err := s.snapshot(names)
+	for err == nil {
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-subscription.Done():
+			return
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case <-subscription.Ready():
+			for _, e := range subscription.Take() {
+				if err = s.send(e); err != nil {
+					break
+				}
+			}
+		}
+	}
+	// the client reconnects and starts over with a snapshot
+	logging.From(r).WithError(err).Info("event stream ended")
+}
+
+type stream struct {
+	RestHandler
+	w       http.ResponseWriter
+	r       *http.Request
+	watched map[string]bool
+	// buyer has no username unless the caller has a deposit to watch
+	buyer security.User
+}
+
+func (s stream) snapshot(names []string) error {
+	for _, name := range names {
+		if err := s.product(name); err != nil {
+			return err
+		}
+	}
+	if s.buyer.Username != "" {
+		return s.deposit()
+	}
+
+	return nil
+}
+
+func (s stream) send(e events.Event) error {
+	switch {
+	case e.Type == events.Resync:
+		if err := s.write("resync", struct{}{}); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(s.watched))
+		for name := range s.watched {
+			names = append(names, name)
+		}
+		return s.snapshot(names)
+	case e.Type == events.Product && (len(s.watched) == 0 || s.watched[e.Key]):
+		return s.product(e.Key)
+	case e.Type == events.Deposit && s.buyer.Username != "" && e.Key == s.buyer.Username:
+		return s.deposit()
+	}
+
+	return nil
+}
+
+// load returns a context for one snapshot, it ends with the stream or after LoadTimeout
+func (s stream) load() (context.Context, context.CancelFunc) {
+	timeout := s.LoadTimeout
+	if timeout <= 0 {
+		timeout = DefaultLoadTimeout
+	}
+
+	return context.WithTimeout(s.r.Context(), timeout)
+}
+
+func (s stream) product(name string) error {
+	ctx, cancel := s.load()
+	defer cancel()
+	p, err := s.Products.Get(ctx, name)
+	if errors.As(err, &repository.EmptyError{}) {
+		return s.write("product_deleted", map[string]string{"name": name})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.write("product", p)
+}
+
+func (s stream) deposit() error {
+	ctx, cancel := s.load()
+	defer cancel()
+	account, err := s.Vending.GetAccount(ctx, s.buyer.Username)
+	if err != nil {
+		return err
+	}
+
+	return s.write("deposit", account)
+}
+
+func (s stream) write(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data)
+
+	return err
+}
@@ -0,0 +1,178 @@
+package eventhandler
+
+import (
+	"bufio"
+	"context"
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRestHandler_Events(t *testing.T) {
+	t.Parallel()
+
+	anyArg := gomock.Any()
+	cola := &products.Product{Name: "cola", SellerID: "sam", Price: 25, Amount: 10}
+
+	tests := []struct {
+		name    string
+		user    security.User
+		query   string
+		setup   func(p *mocks.ProductService, v *mocks.VendingService)
+		publish []events.Event
+		want    []string
+	}{
+		{
+			name:  "watched product is sent first and on every change",
+			user:  security.User{Username: "sam", Role: security.Seller},
+			query: "?product=cola",
+			setup: func(p *mocks.ProductService, _ *mocks.VendingService) {
+				p.EXPECT().Get(anyArg, "cola").Return(cola, nil).Times(1)
+				p.EXPECT().Get(anyArg, "cola").Return(nil, repository.EmptyError{}).Times(1)
+			},
+			publish: []events.Event{{Type: events.Product, Key: "tea"}, {Type: events.Product, Key: "cola"}},
+			want: []string{
+				`product {"name":"cola","sellerId":"sam","price":25,"amount":10}`,
+				`product_deleted {"name":"cola"}`,
+			},
+		},
+		{
+			name: "buyer gets own deposit only",
+			user: security.User{Username: "mike", Role: security.Buyer},
+			setup: func(_ *mocks.ProductService, v *mocks.VendingService) {
+				// every load gets a deadline of its own, the stream's context has none
+				v.EXPECT().GetAccount(withDeadline{}, "mike").Return(&vending.Response{Spent: 0}, nil).Times(1)
+				v.EXPECT().GetAccount(withDeadline{}, "mike").Return(&vending.Response{Spent: 25}, nil).Times(1)
+			},
+			publish: []events.Event{{Type: events.Deposit, Key: "sam"}, {Type: events.Deposit, Key: "mike"}},
+			want: []string{
+				`deposit {"spent":0}`,
+				`deposit {"spent":25}`,
+			},
+		},
+		{
+			name: "resync is followed by a snapshot",
+			user: security.User{Username: "mike", Role: security.Buyer},
+			setup: func(_ *mocks.ProductService, v *mocks.VendingService) {
+				v.EXPECT().GetAccount(anyArg, "mike").Return(&vending.Response{}, nil).Times(2)
+			},
+			publish: []events.Event{{Type: events.Resync}},
+			want: []string{
+				`deposit {"spent":0}`,
+				`resync {}`,
+				`deposit {"spent":0}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			p, v := mocks.NewProductService(mockCtrl), mocks.NewVendingService(mockCtrl)
+			tt.setup(p, v)
+			bus := events.NewBus(nil)
+			handler := RestHandler{Bus: bus, Products: p, Vending: v}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handler.Events(w, r.WithContext(security.WithUser(r.Context(), tt.user)))
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/"+tt.query, nil)
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if got := res.Header.Get("Content-Type"); got != "text/event-stream" {
+				t.Fatalf("Content-Type = %q, want text/event-stream", got)
+			}
+
+			frames := bufio.NewScanner(res.Body)
+			// the retry frame follows the subscription, events published from now on are sent
+			if next(frames) == "" {
+				t.Fatal("stream ended before the retry frame")
+			}
+			for _, e := range tt.publish {
+				_ = bus.Publish(ctx, e)
+			}
+
+			var got []string
+			for len(got) < len(tt.want) {
+				frame := next(frames)
+				if frame == "" {
+					break
+				}
+				if !strings.HasPrefix(frame, ":") {
+					got = append(got, frame)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Events() sent %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestHandler_Events_InvalidProduct(t *testing.T) {
+	t.Parallel()
+
+	handler := RestHandler{Bus: events.NewBus(nil)}
+	ctx := security.WithUser(context.Background(), security.User{Username: "mike", Role: security.Buyer})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/?product=", nil)
+	w := httptest.NewRecorder()
+	handler.Events(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+// next reads one frame as "event data", comments as they are, "" at the end of the stream
+func next(s *bufio.Scanner) string {
+	var fields []string
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			if len(fields) > 0 {
+				return strings.Join(fields, " ")
+			}
+			continue
+		}
+		if i := strings.Index(line, ": "); i >= 0 && !strings.HasPrefix(line, ":") {
+			line = line[i+2:]
+		}
+		fields = append(fields, line)
+	}
+
+	return ""
+}
+
+// withDeadline matches contexts that end on their own
+type withDeadline struct{}
+
+func (withDeadline) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+	if !ok {
+		return false
+	}
+	_, ok = ctx.Deadline()
+
+	return ok
+}
+
+func (withDeadline) String() string {
+	return "is a context with a deadline"
+}
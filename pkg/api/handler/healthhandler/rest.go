@@ -0,0 +1,35 @@
+package healthhandler
+
+import (
+	"encoding/json"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/health"
+	"net/http"
+)
+
+type RestHandler struct {
+	health.Service
+}
+
+// Live answers as long as the process serves requests, it checks nothing else so a slow database never gets it restarted
+func (rest RestHandler) Live(w http.ResponseWriter, r *http.Request) {
+	write(w, r, http.StatusOK, health.Report{Status: health.Up, Checks: []health.Check{}})
+}
+
+// Ready answers 503 while a check fails, or from the moment the service is told to stop
+func (rest RestHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := rest.Service.Ready(r.Context())
+	status := http.StatusOK
+	if report.Status != health.Up {
+		status = http.StatusServiceUnavailable
+	}
+	write(w, r, status, report)
+}
+
+func write(w http.ResponseWriter, r *http.Request, status int, report health.Report) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(&report); err != nil {
+		problem.Write(w, r, err)
+	}
+}
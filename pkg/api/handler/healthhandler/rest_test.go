@@ -0,0 +1,56 @@
+package healthhandler
+
+import (
+	"encoding/json"
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/health"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestHandler_Live(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	RestHandler{}.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", w.Code, http.StatusOK)
+	}
+}
+
+func TestRestHandler_Ready(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		report health.Report
+		want   int
+	}{
+		{name: "ready", report: health.Report{Status: health.Up, Checks: []health.Check{{Name: "database", Status: health.Up}}}, want: http.StatusOK},
+		{name: "not ready", report: health.Report{Status: health.Down, Checks: []health.Check{{Name: "database", Status: health.Down, Error: "connection refused"}}}, want: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewHealthService(mockCtrl)
+			s.EXPECT().Ready(gomock.Any()).Return(tt.report)
+			w := httptest.NewRecorder()
+			RestHandler{Service: s}.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.want)
+			}
+			var got health.Report
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.report.Status || len(got.Checks) != len(tt.report.Checks) {
+				t.Errorf("handler returned %+v, want %+v", got, tt.report)
+			}
+		})
+	}
+}
@@ -0,0 +1,536 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/api/docs"
+	"github.com/artback/mvp/pkg/api/graceful"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/health"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/casbin/casbin/v2"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+)
+
+type openAPI struct {
+	Paths      map[string]map[string]operation `json:"paths"`
+	Components struct {
+		Schemas   map[string]schema   `json:"schemas"`
+		Responses map[string]response `json:"responses"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Responses map[string]response `json:"responses"`
+}
+
+type response struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema schema `json:"schema"`
+	} `json:"content"`
+}
+
+type schema struct {
+	Ref                  string            `json:"$ref"`
+	Type                 string            `json:"type"`
+	Nullable             bool              `json:"nullable"`
+	Enum                 []interface{}     `json:"enum"`
+	Required             []string          `json:"required"`
+	Properties           map[string]schema `json:"properties"`
+	Items                *schema           `json:"items"`
+	AdditionalProperties json.RawMessage   `json:"additionalProperties"`
+}
+
+func loadSpec(t *testing.T) openAPI {
+	t.Helper()
+	spec := openAPI{}
+	if err := json.Unmarshal(docs.Spec(), &spec); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+
+	return spec
+}
+
+// TestOpenAPI_Routes fails when a route of HttpRouter is missing from the spec or the spec documents a route that doesn't exist
+func TestOpenAPI_Routes(t *testing.T) {
+	e, err := casbin.NewSyncedEnforcer("../../../config/rbac_model.conf", "../../../config/auth_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, err := HttpRouter(nil, e, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routed := map[string]bool{}
+	err = chi.Walk(router, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// sub routers mounted at "/" show up as /*/, a trailing slash is the root of a sub router
+		route = strings.TrimSuffix(strings.ReplaceAll(route, "/*/", "/"), "/")
+		routed[strings.ToLower(method)+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range loadSpec(t).Paths {
+		for method := range operations {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for _, route := range sortedKeys(routed) {
+		if !documented[route] {
+			t.Errorf("route %s is not documented in openapi.json", route)
+		}
+	}
+	for _, route := range sortedKeys(documented) {
+		if !routed[route] {
+			t.Errorf("openapi.json documents %s, the router doesn't serve it", route)
+		}
+	}
+}
+
+type serviceMocks struct {
+	users    *mocks.UserService
+	products *mocks.ProductService
+	vending  *mocks.VendingService
+	admin    *mocks.AdminService
+	policies *mocks.PolicyService
+	webhooks *mocks.WebhookService
+	alerts   *mocks.AlertService
+	health   *mocks.HealthService
+	lockout  *mocks.LockoutService
+}
+
+// TestOpenAPI_Responses runs every documented operation through the routes and checks status and body against the spec
+func TestOpenAPI_Responses(t *testing.T) {
+	anyArg := gomock.Any()
+	created := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	product := &products.Product{Name: "cola", SellerID: "sam", Price: 25, Amount: 10}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		setup  func(m serviceMocks)
+		status int
+		// left is set for streams, the client has gone before the request is served
+		left bool
+	}{
+		{name: "create user", method: http.MethodPost, target: "/v1/user", body: `{"username":"mike","password":"secret","contact":"mike@example.com"}`,
+			setup: func(m serviceMocks) { m.users.EXPECT().Insert(anyArg, anyArg).Return(nil) }, status: http.StatusOK},
+		{name: "create user without password", method: http.MethodPost, target: "/v1/user", body: `{"username":"mike"}`, status: http.StatusBadRequest},
+		{name: "create duplicate user", method: http.MethodPost, target: "/v1/user", body: `{"username":"mike","password":"secret"}`,
+			setup: func(m serviceMocks) { m.users.EXPECT().Insert(anyArg, anyArg).Return(repository.DuplicateError{}) }, status: http.StatusConflict},
+		{name: "get user", method: http.MethodGet, target: "/v1/user/mike",
+			setup: func(m serviceMocks) {
+				m.users.EXPECT().GetResponse(anyArg, "mike").Return(&users.Response{Username: "mike", Role: security.Buyer, Deposit: change.Deposit{5: 1, 20: 2}}, nil)
+			}, status: http.StatusOK},
+		{name: "get missing user", method: http.MethodGet, target: "/v1/user/mike",
+			setup: func(m serviceMocks) {
+				m.users.EXPECT().GetResponse(anyArg, "mike").Return(nil, repository.EmptyError{})
+			}, status: http.StatusNotFound},
+		{name: "patch user", method: http.MethodPatch, target: "/v1/user", body: `{"contact":null}`,
+			setup: func(m serviceMocks) { m.users.EXPECT().Patch(anyArg, "mike", anyArg).Return(nil) }, status: http.StatusOK},
+		{name: "patch role", method: http.MethodPatch, target: "/v1/user", body: `{"role":"seller"}`, status: http.StatusBadRequest},
+		{name: "put user", method: http.MethodPut, target: "/v1/user", body: `{"contact":"mike@example.com"}`,
+			setup: func(m serviceMocks) { m.users.EXPECT().Patch(anyArg, "mike", anyArg).Return(nil) }, status: http.StatusOK},
+		{name: "delete user", method: http.MethodDelete, target: "/v1/user",
+			setup: func(m serviceMocks) { m.users.EXPECT().Delete(anyArg, "mike").Return(nil) }, status: http.StatusOK},
+		{name: "change role", method: http.MethodPost, target: "/v1/user/role", body: `{"role":"seller"}`,
+			setup: func(m serviceMocks) {
+				m.users.EXPECT().ChangeRole(anyArg, "mike", security.Seller).Return(users.NeedsApproval, nil)
+			}, status: http.StatusAccepted},
+		{name: "request password reset", method: http.MethodPost, target: "/v1/user/password-reset", body: `{"username":"mike"}`,
+			setup: func(m serviceMocks) {
+				m.lockout.EXPECT().Check(anyArg, "mike", anyArg).Return(false, nil)
+				m.lockout.EXPECT().Fail(anyArg, "mike", anyArg).Return(nil)
+				m.users.EXPECT().RequestPasswordReset(anyArg, "mike").Return(nil).AnyTimes()
+			}, status: http.StatusAccepted},
+		{name: "throttled password reset", method: http.MethodPost, target: "/v1/user/password-reset", body: `{"username":"mike"}`,
+			setup: func(m serviceMocks) {
+				m.lockout.EXPECT().Check(anyArg, "mike", anyArg).Return(false, security.RetryError{Err: security.ThrottledErr, RetryAfter: time.Minute})
+			}, status: http.StatusTooManyRequests},
+		{name: "confirm password reset", method: http.MethodPost, target: "/v1/user/password-reset/confirm", body: `{"token":"abc","password":"secret"}`,
+			setup: func(m serviceMocks) { m.users.EXPECT().ResetPassword(anyArg, "abc", "secret").Return(nil) }, status: http.StatusOK},
+		{name: "create product", method: http.MethodPost, target: "/v1/product", body: `{"name":"cola","price":25,"amount":10}`,
+			setup: func(m serviceMocks) { m.products.EXPECT().Insert(anyArg, anyArg).Return(nil) }, status: http.StatusOK},
+		{name: "get product", method: http.MethodGet, target: "/v1/product/cola",
+			setup: func(m serviceMocks) { m.products.EXPECT().Get(anyArg, "cola").Return(product, nil) }, status: http.StatusOK},
+		{name: "get missing product", method: http.MethodGet, target: "/v1/product/cola",
+			setup: func(m serviceMocks) { m.products.EXPECT().Get(anyArg, "cola").Return(nil, repository.EmptyError{}) }, status: http.StatusNotFound},
+		{name: "update product", method: http.MethodPut, target: "/v1/product/cola", body: `{"price":30,"amount":5}`,
+			setup: func(m serviceMocks) { m.products.EXPECT().Update(anyArg, anyArg).Return(nil) }, status: http.StatusOK},
+		{name: "delete product", method: http.MethodDelete, target: "/v1/product/cola",
+			setup: func(m serviceMocks) { m.products.EXPECT().Delete(anyArg, "mike", "cola").Return(nil) }, status: http.StatusOK},
+		{name: "get account", method: http.MethodGet, target: "/v1/deposit",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().GetAccount(anyArg, "mike").Return(&vending.Response{Deposit: change.Deposit{10: 1}, Products: []products.Product{*product}, Spent: 25}, nil)
+			}, status: http.StatusOK},
+		{name: "deposit", method: http.MethodPut, target: "/v1/deposit", body: `{"5":2,"100":1}`,
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().Deposit(anyArg, "mike", change.Deposit{5: 2, 100: 1}).Return(nil)
+			}, status: http.StatusOK},
+		{name: "negative deposit", method: http.MethodPut, target: "/v1/deposit", body: `{"5":-1}`, status: http.StatusBadRequest},
+		{name: "buy", method: http.MethodPost, target: "/v1/buy/cola?amount=2",
+			setup: func(m serviceMocks) { m.vending.EXPECT().BuyProduct(anyArg, "mike", anyArg).Return(nil) }, status: http.StatusOK},
+		{name: "buy without funds", method: http.MethodPost, target: "/v1/buy/cola",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().BuyProduct(anyArg, "mike", anyArg).Return(vending.InsufficientFundsError{Cost: 25, Deposit: 10})
+			}, status: http.StatusPaymentRequired},
+		{name: "buy out of stock", method: http.MethodPost, target: "/v1/buy/cola?amount=3",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().BuyProduct(anyArg, "mike", anyArg).Return(vending.OutOfStockError{Product: "cola", Requested: 3, Available: 1})
+			}, status: http.StatusConflict},
+		{name: "buy nothing", method: http.MethodPost, target: "/v1/buy/cola?amount=0", status: http.StatusBadRequest},
+		{name: "reset deposit", method: http.MethodDelete, target: "/v1/reset",
+			setup: func(m serviceMocks) { m.vending.EXPECT().SetDeposit(anyArg, "mike", 0).Return(nil) }, status: http.StatusOK},
+		{name: "get transaction", method: http.MethodGet, target: "/v1/transaction/7",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().GetTransaction(anyArg, 7).Return(&vending.Transaction{ID: 7, ProductName: "cola", Username: "mike", Amount: 1, Price: 25}, nil)
+			}, status: http.StatusOK},
+		{name: "list users", method: http.MethodGet, target: "/v1/admin/users?q=mi&limit=10",
+			setup: func(m serviceMocks) {
+				m.admin.EXPECT().ListUsers(anyArg, anyArg).Return([]admin.User{{Username: "mike", Role: security.Buyer, Deposit: 10, Locked: true}}, nil)
+			}, status: http.StatusOK},
+		{name: "list users with a bad limit", method: http.MethodGet, target: "/v1/admin/users?limit=-1", status: http.StatusBadRequest},
+		{name: "set role", method: http.MethodPut, target: "/v1/admin/users/sam/role", body: `{"role":"seller","reason":"asked"}`,
+			setup: func(m serviceMocks) {
+				m.admin.EXPECT().SetRole(anyArg, "mike", "sam", security.Seller, "asked").Return(nil)
+			}, status: http.StatusOK},
+		{name: "lock", method: http.MethodPost, target: "/v1/admin/users/sam/lock", body: `{"reason":"fraud"}`,
+			setup: func(m serviceMocks) { m.admin.EXPECT().Lock(anyArg, "mike", "sam", "fraud").Return(nil) }, status: http.StatusOK},
+		{name: "lock without reason", method: http.MethodPost, target: "/v1/admin/users/sam/lock", body: `{}`,
+			setup: func(m serviceMocks) { m.admin.EXPECT().Lock(anyArg, "mike", "sam", "").Return(admin.MissingReasonErr) }, status: http.StatusBadRequest},
+		{name: "unlock", method: http.MethodPost, target: "/v1/admin/users/sam/unlock", body: `{"reason":"resolved"}`,
+			setup: func(m serviceMocks) { m.admin.EXPECT().Unlock(anyArg, "mike", "sam", "resolved").Return(nil) }, status: http.StatusOK},
+		{name: "force password reset", method: http.MethodPost, target: "/v1/admin/users/sam/password-reset", body: `{"reason":"leaked"}`,
+			setup: func(m serviceMocks) { m.admin.EXPECT().ForcePasswordReset(anyArg, "mike", "sam", "leaked").Return(nil) }, status: http.StatusOK},
+		{name: "adjust deposit", method: http.MethodPost, target: "/v1/admin/users/sam/deposit", body: `{"amount":-5,"reason":"refund"}`,
+			setup: func(m serviceMocks) { m.admin.EXPECT().AdjustDeposit(anyArg, "mike", "sam", -5, "refund").Return(nil) }, status: http.StatusOK},
+		{name: "audit log", method: http.MethodGet, target: "/v1/admin/audit?target=sam",
+			setup: func(m serviceMocks) {
+				m.admin.EXPECT().AuditLog(anyArg, "sam").Return([]admin.Entry{{ID: 1, Actor: "mike", Action: "lock", Target: "sam", Reason: "fraud", CreatedAt: created}}, nil)
+			}, status: http.StatusOK},
+		{name: "role requests", method: http.MethodGet, target: "/v1/admin/role-requests",
+			setup: func(m serviceMocks) {
+				m.admin.EXPECT().RoleRequests(anyArg).Return([]users.RoleRequest{{ID: 3, Username: "sam", Role: security.Seller, Status: "pending", CreatedAt: created}}, nil)
+			}, status: http.StatusOK},
+		{name: "approve role request", method: http.MethodPost, target: "/v1/admin/role-requests/3/approve", body: `{"reason":"known seller"}`,
+			setup: func(m serviceMocks) {
+				m.admin.EXPECT().ApproveRoleRequest(anyArg, "mike", 3, "known seller").Return(nil)
+			}, status: http.StatusOK},
+		{name: "reject role request", method: http.MethodPost, target: "/v1/admin/role-requests/3/reject", body: `{"reason":"unknown"}`,
+			setup: func(m serviceMocks) { m.admin.EXPECT().RejectRoleRequest(anyArg, "mike", 3, "unknown").Return(nil) }, status: http.StatusOK},
+		{name: "policies", method: http.MethodGet, target: "/v1/admin/policies",
+			setup: func(m serviceMocks) {
+				m.policies.EXPECT().Rules(anyArg).Return([]policy.Rule{{Role: security.Buyer, Path: "/v1/deposit", Method: "GET", Ownership: "owner"}}, nil)
+			}, status: http.StatusOK},
+		{name: "add policy", method: http.MethodPost, target: "/v1/admin/policies", body: `{"role":"buyer","path":"/v1/deposit","method":"GET","ownership":"owner","reason":"needed"}`,
+			setup: func(m serviceMocks) { m.policies.EXPECT().AddRule(anyArg, "mike", anyArg, "needed").Return(nil) }, status: http.StatusOK},
+		{name: "remove policy", method: http.MethodDelete, target: "/v1/admin/policies", body: `{"role":"buyer","path":"/v1/deposit","method":"GET","ownership":"owner","reason":"unused"}`,
+			setup: func(m serviceMocks) { m.policies.EXPECT().RemoveRule(anyArg, "mike", anyArg, "unused").Return(nil) }, status: http.StatusOK},
+		{name: "role assignments", method: http.MethodGet, target: "/v1/admin/role-assignments",
+			setup: func(m serviceMocks) {
+				m.policies.EXPECT().Assignments(anyArg).Return([]policy.Assignment{{Role: "support", Inherits: security.Buyer}}, nil)
+			}, status: http.StatusOK},
+		{name: "add role assignment", method: http.MethodPost, target: "/v1/admin/role-assignments", body: `{"role":"support","inherits":"buyer","reason":"new role"}`,
+			setup: func(m serviceMocks) {
+				m.policies.EXPECT().AddAssignment(anyArg, "mike", anyArg, "new role").Return(nil)
+			}, status: http.StatusOK},
+		{name: "remove role assignment", method: http.MethodDelete, target: "/v1/admin/role-assignments", body: `{"role":"support","inherits":"buyer","reason":"unused"}`,
+			setup: func(m serviceMocks) {
+				m.policies.EXPECT().RemoveAssignment(anyArg, "mike", anyArg, "unused").Return(nil)
+			}, status: http.StatusOK},
+		{name: "vars", method: http.MethodGet, target: "/v1/admin/vars", status: http.StatusOK},
+		{name: "register webhook", method: http.MethodPost, target: "/v1/webhooks", body: `{"url":"https://seller.example/hook","events":["product.sold"]}`,
+			setup: func(m serviceMocks) {
+				m.webhooks.EXPECT().Register(anyArg, "mike", "https://seller.example/hook", []webhook.Kind{webhook.Sold}).Return(&webhook.Endpoint{
+					ID: 1, SellerID: "mike", URL: "https://seller.example/hook", Events: []webhook.Kind{webhook.Sold}, Secret: "whsec_1", CreatedAt: created,
+				}, nil)
+			}, status: http.StatusCreated},
+		{name: "list webhooks", method: http.MethodGet, target: "/v1/webhooks",
+			setup: func(m serviceMocks) {
+				m.webhooks.EXPECT().Endpoints(anyArg, "mike").Return([]webhook.Endpoint{
+					{ID: 1, SellerID: "mike", URL: "https://seller.example/hook", Events: []webhook.Kind{}, CreatedAt: created},
+				}, nil)
+			}, status: http.StatusOK},
+		{name: "delete webhook", method: http.MethodDelete, target: "/v1/webhooks/1",
+			setup:  func(m serviceMocks) { m.webhooks.EXPECT().DeleteEndpoint(anyArg, "mike", 1).Return(nil) },
+			status: http.StatusOK},
+		{name: "webhook deliveries", method: http.MethodGet, target: "/v1/webhooks/1/deliveries?status=pending",
+			setup: func(m serviceMocks) {
+				m.webhooks.EXPECT().Deliveries(anyArg, "mike", 1, webhook.Pending).Return([]webhook.Delivery{{
+					ID: 3, EndpointID: 1, Status: webhook.Pending, NextAttempt: &created,
+					Event:    webhook.Event{ID: 7, Kind: webhook.Sold, Data: json.RawMessage(`{"product":"cola","amount":1}`), CreatedAt: created},
+					Attempts: []webhook.Attempt{{StatusCode: 500, Error: "unexpected status 500", Duration: 12, AttemptedAt: created}},
+				}}, nil)
+			}, status: http.StatusOK},
+		{name: "redeliver webhook", method: http.MethodPost, target: "/v1/webhooks/deliveries/3/redeliver",
+			setup:  func(m serviceMocks) { m.webhooks.EXPECT().Redeliver(anyArg, "mike", 3).Return(nil) },
+			status: http.StatusOK},
+		{name: "set threshold", method: http.MethodPut, target: "/v1/product/cola/threshold", body: `{"threshold":3}`,
+			setup:  func(m serviceMocks) { m.alerts.EXPECT().SetThreshold(anyArg, "mike", "cola", 3).Return(nil) },
+			status: http.StatusOK},
+		{name: "list alerts", method: http.MethodGet, target: "/v1/alerts",
+			setup: func(m serviceMocks) {
+				m.alerts.EXPECT().Alerts(anyArg, "mike", alert.Open).Return([]alert.Alert{
+					{ID: 2, Product: "cola", SellerID: "mike", Kind: alert.LowStock, Remaining: 3, Threshold: 3, Status: alert.Open, CreatedAt: created},
+				}, nil)
+			}, status: http.StatusOK},
+		{name: "acknowledge alert", method: http.MethodPost, target: "/v1/alerts/2/acknowledge",
+			setup:  func(m serviceMocks) { m.alerts.EXPECT().Acknowledge(anyArg, "mike", 2).Return(nil) },
+			status: http.StatusOK},
+		{name: "event stream", method: http.MethodGet, target: "/v1/events?product=cola",
+			setup: func(m serviceMocks) {
+				m.products.EXPECT().Get(anyArg, "cola").Return(&products.Product{Name: "cola", SellerID: "sam", Price: 25, Amount: 10}, nil)
+				m.vending.EXPECT().GetAccount(anyArg, "mike").Return(&vending.Response{}, nil)
+			}, status: http.StatusOK, left: true},
+		{name: "openapi document", method: http.MethodGet, target: "/v1/openapi.json", status: http.StatusOK},
+		{name: "docs page", method: http.MethodGet, target: "/v1/docs", status: http.StatusOK},
+		{name: "liveness", method: http.MethodGet, target: "/healthz", status: http.StatusOK},
+		{name: "readiness", method: http.MethodGet, target: "/readyz",
+			setup: func(m serviceMocks) {
+				m.health.EXPECT().Ready(anyArg).Return(health.Report{Status: health.Up, Checks: []health.Check{{Name: "database", Status: health.Up}}})
+			}, status: http.StatusOK},
+		{name: "not ready", method: http.MethodGet, target: "/readyz",
+			setup: func(m serviceMocks) {
+				m.health.EXPECT().Ready(anyArg).Return(health.Report{Status: health.Down, Checks: []health.Check{{Name: "shutdown", Status: health.Down, Error: "shutting down"}}})
+			}, status: http.StatusServiceUnavailable},
+	}
+
+	spec := loadSpec(t)
+	exercised := map[string]bool{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			m := serviceMocks{
+				users:    mocks.NewUserService(ctrl),
+				products: mocks.NewProductService(ctrl),
+				vending:  mocks.NewVendingService(ctrl),
+				admin:    mocks.NewAdminService(ctrl),
+				policies: mocks.NewPolicyService(ctrl),
+				webhooks: mocks.NewWebhookService(ctrl),
+				alerts:   mocks.NewAlertService(ctrl),
+				health:   mocks.NewHealthService(ctrl),
+				lockout:  mocks.NewLockoutService(ctrl),
+			}
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			router := chi.NewRouter()
+			router.Use(middleware.SetHeader("Content-Type", "application/json"), withUser(security.User{Username: "mike", Role: security.Buyer}))
+			routes(router, services{users: m.users, products: m.products, vending: m.vending, admin: m.admin, policies: m.policies, webhooks: m.webhooks, alerts: m.alerts, health: m.health, events: events.NewBus(nil),
+				resets: graceful.NewQueue(1, 1, time.Second), resetLockout: m.lockout}, time.Minute)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.left {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %v, want %v, body %s", w.Code, tt.status, w.Body)
+			}
+
+			path, op := spec.operation(tt.method, strings.Split(tt.target, "?")[0])
+			if op == nil {
+				t.Fatalf("%s %s is not documented", tt.method, tt.target)
+			}
+			exercised[strings.ToLower(tt.method)+" "+path] = true
+			for _, problem := range spec.checkResponse(op, w) {
+				t.Error(problem)
+			}
+		})
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if !exercised[method+" "+path] {
+				t.Errorf("%s %s has no case in TestOpenAPI_Responses", method, path)
+			}
+		}
+	}
+}
+
+func withUser(user security.User) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(security.WithUser(r.Context(), user)))
+		})
+	}
+}
+
+var pathParam = regexp.MustCompile(`\{[^/]+\}`)
+
+// operation finds the documented operation serving method and path
+func (spec openAPI) operation(method, path string) (string, *operation) {
+	for template, operations := range spec.Paths {
+		if !regexp.MustCompile("^" + pathParam.ReplaceAllString(template, `[^/]+`) + "$").MatchString(path) {
+			continue
+		}
+		if op, ok := operations[strings.ToLower(method)]; ok {
+			return template, &op
+		}
+	}
+
+	return "", nil
+}
+
+// checkResponse reports every way the recorded response differs from what op documents
+func (spec openAPI) checkResponse(op *operation, w *httptest.ResponseRecorder) []string {
+	documented, ok := op.Responses[fmt.Sprint(w.Code)]
+	if !ok {
+		documented, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("status %v is not documented", w.Code)}
+	}
+	if documented.Ref != "" {
+		documented = spec.Components.Responses[strings.TrimPrefix(documented.Ref, "#/components/responses/")]
+	}
+
+	if len(documented.Content) == 0 {
+		if w.Body.Len() > 0 {
+			return []string{fmt.Sprintf("status %v documents no body, got %s", w.Code, w.Body)}
+		}
+		return nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	content, ok := documented.Content[contentType]
+	if !ok {
+		return []string{fmt.Sprintf("content type %q is not documented for status %v", contentType, w.Code)}
+	}
+	if contentType != "application/json" && !strings.HasSuffix(contentType, "+json") {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		return []string{fmt.Sprintf("body is not json: %v", err)}
+	}
+
+	return spec.check(content.Schema, body, "body")
+}
+
+// check validates value against the subset of json schema used by openapi.json
+func (spec openAPI) check(s schema, value interface{}, at string) []string {
+	if s.Ref != "" {
+		return spec.check(spec.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")], value, at)
+	}
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return []string{at + " is null"}
+	}
+	if len(s.Enum) > 0 && !contains(s.Enum, value) {
+		return []string{fmt.Sprintf("%s = %v is not one of %v", at, value, s.Enum)}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if s.Type != "object" {
+			return []string{fmt.Sprintf("%s is an object, want %s", at, s.Type)}
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", at, name))
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			if property, ok := s.Properties[name]; ok {
+				problems = append(problems, spec.check(property, v[name], at+"."+name)...)
+				continue
+			}
+			additional := schema{}
+			switch {
+			case len(s.AdditionalProperties) == 0:
+				continue
+			case string(s.AdditionalProperties) == "false":
+				problems = append(problems, fmt.Sprintf("%s.%s is not documented", at, name))
+			case json.Unmarshal(s.AdditionalProperties, &additional) == nil && additional.Type != "":
+				problems = append(problems, spec.check(additional, v[name], at+"."+name)...)
+			}
+		}
+	case []interface{}:
+		if s.Type != "array" {
+			return []string{fmt.Sprintf("%s is an array, want %s", at, s.Type)}
+		}
+		for i, item := range v {
+			problems = append(problems, spec.check(*s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case string:
+		if s.Type != "string" {
+			problems = append(problems, fmt.Sprintf("%s is a string, want %s", at, s.Type))
+		}
+	case bool:
+		if s.Type != "boolean" {
+			problems = append(problems, fmt.Sprintf("%s is a boolean, want %s", at, s.Type))
+		}
+	case float64:
+		if s.Type != "number" && (s.Type != "integer" || v != float64(int64(v))) {
+			problems = append(problems, fmt.Sprintf("%s = %v, want %s", at, v, s.Type))
+		}
+	}
+
+	return problems
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]bool:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/vending"
+)
+
+// ownerRoutes maps every owned resource to the loader of its owner, the patterns mirror the routes in HttpRouter.
+// /v1/user/role only exists as POST, any other method reads or changes the account named "role".
+func ownerRoutes(product products.Service, vend vending.Service) security.OwnerRoutes {
+	return security.OwnerRoutes{
+		{Pattern: regexp.MustCompile(`^/v1/user/?$`), Owner: security.Self},
+		{Method: http.MethodPost, Pattern: regexp.MustCompile(`^/v1/user/role$`), Owner: security.Self},
+		{Pattern: regexp.MustCompile(`^/v1/user/([^/]+)$`), Owner: userOwner},
+		{Pattern: regexp.MustCompile(`^/v1/product/([^/]+)$`), Owner: productOwner(product)},
+		{Pattern: regexp.MustCompile(`^/v1/product/([^/]+)/threshold$`), Owner: productOwner(product)},
+		{Pattern: regexp.MustCompile(`^/v1/transaction/([^/]+)$`), Owner: transactionOwner(vend)},
+		{Pattern: regexp.MustCompile(`^/v1/(deposit|reset|buy/[^/]+)$`), Owner: security.Self},
+	}
+}
+
+// userOwner makes every account owned by itself
+func userOwner(_ context.Context, username string) (string, error) {
+	return username, nil
+}
+
+func productOwner(service products.Service) security.OwnerFunc {
+	return func(ctx context.Context, name string) (string, error) {
+		product, err := service.Get(ctx, name)
+		if err != nil {
+			return "", ignoreEmpty(err)
+		}
+
+		return product.SellerID, nil
+	}
+}
+
+func transactionOwner(service vending.Service) security.OwnerFunc {
+	return func(ctx context.Context, key string) (string, error) {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return "", nil
+		}
+
+		transaction, err := service.GetTransaction(ctx, id)
+		if err != nil {
+			return "", ignoreEmpty(err)
+		}
+
+		return transaction.Username, nil
+	}
+}
+
+// ignoreEmpty leaves missing resources without owner, the policy then decides if the handler may answer 404
+func ignoreEmpty(err error) error {
+	if errors.As(err, &repository.EmptyError{}) {
+		return nil
+	}
+
+	return err
+}
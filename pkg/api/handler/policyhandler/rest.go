@@ -0,0 +1,93 @@
+package policyhandler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/policy"
+	"net/http"
+)
+
+type RestHandler struct {
+	policy.Service
+}
+
+type ruleRequest struct {
+	policy.Rule
+	Reason string `json:"reason"`
+}
+
+type assignmentRequest struct {
+	policy.Assignment
+	Reason string `json:"reason"`
+}
+
+func (rest RestHandler) Rules(w http.ResponseWriter, r *http.Request) {
+	rules, err := rest.Service.Rules(r.Context())
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&rules); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) AddRule(w http.ResponseWriter, r *http.Request) {
+	if err := rest.changeRule(r, rest.Service.AddRule); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) RemoveRule(w http.ResponseWriter, r *http.Request) {
+	if err := rest.changeRule(r, rest.Service.RemoveRule); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) changeRule(r *http.Request, change func(ctx context.Context, actor string, rule policy.Rule, reason string) error) error {
+	req := ruleRequest{}
+	if err := validate.Decode(r, &req); err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return change(r.Context(), actor, req.Rule, req.Reason)
+}
+
+func (rest RestHandler) Assignments(w http.ResponseWriter, r *http.Request) {
+	assignments, err := rest.Service.Assignments(r.Context())
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&assignments); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) AddAssignment(w http.ResponseWriter, r *http.Request) {
+	if err := rest.changeAssignment(r, rest.Service.AddAssignment); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) RemoveAssignment(w http.ResponseWriter, r *http.Request) {
+	if err := rest.changeAssignment(r, rest.Service.RemoveAssignment); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) changeAssignment(r *http.Request, change func(ctx context.Context, actor string, assignment policy.Assignment, reason string) error) error {
+	req := assignmentRequest{}
+	if err := validate.Decode(r, &req); err != nil {
+		return err
+	}
+	actor := security.GetUser(r.Context()).Username
+
+	return change(r.Context(), actor, req.Assignment, req.Reason)
+}
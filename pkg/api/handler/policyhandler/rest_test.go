@@ -0,0 +1,76 @@
+package policyhandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/golang/mock/gomock"
+)
+
+func TestController_AddRule(t *testing.T) {
+	t.Parallel()
+
+	rule := policy.Rule{Role: security.Buyer, Path: "/v1/product/*", Method: "GET", Ownership: policy.Any}
+
+	tests := []struct {
+		name  string
+		body  []byte
+		times int
+		err   error
+		want  int
+	}{
+		{
+			name:  "successful",
+			body:  []byte(`{"role": "buyer","path": "/v1/product/*","method": "GET","ownership": "any","reason": "browse"}`),
+			times: 1,
+			want:  http.StatusOK,
+		},
+		{
+			name:  "unsuccessful, duplicate",
+			body:  []byte(`{"role": "buyer","path": "/v1/product/*","method": "GET","ownership": "any","reason": "browse"}`),
+			times: 1,
+			err:   repository.DuplicateError{},
+			want:  http.StatusConflict,
+		},
+		{
+			name:  "unsuccessful, missing reason",
+			body:  []byte(`{"role": "buyer","path": "/v1/product/*","method": "GET","ownership": "any","reason": "browse"}`),
+			times: 1,
+			err:   admin.MissingReasonErr,
+			want:  http.StatusBadRequest,
+		},
+		{
+			name: "unsuccessful, json decoder",
+			body: []byte(`{"role: "buyer"}`),
+			want: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewPolicyService(mockCtrl)
+			s.EXPECT().AddRule(gomock.Any(), "root", rule, "browse").Return(tt.err).Times(tt.times)
+			co := RestHandler{Service: s}
+			ctx := security.WithUser(context.Background(), security.User{Username: "root", Role: security.Admin})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			co.AddRule(w, req)
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.want)
+			}
+		})
+	}
+}
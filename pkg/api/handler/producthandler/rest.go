@@ -2,47 +2,28 @@ package producthandler
 
 import (
 	"encoding/json"
-	"errors"
 	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
 	"github.com/artback/mvp/pkg/products"
-	"github.com/artback/mvp/pkg/repository"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
 
-var JsonErr = errors.New("error parsing json body")
-
 type RestHandler struct {
 	products.Service
 }
 
-func httpError(w http.ResponseWriter, err error) {
-	var code int
-
-	switch {
-	case errors.Is(err, repository.EmptyError{}):
-		code = http.StatusNotFound
-	case errors.Is(err, repository.DuplicateError{}):
-		code = http.StatusConflict
-	case errors.Is(err, JsonErr):
-		code = http.StatusBadRequest
-	default:
-		code = http.StatusInternalServerError
-	}
-
-	http.Error(w, err.Error(), code)
-}
-
 func (rest RestHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	err := rest.createProduct(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
 func (rest RestHandler) createProduct(r *http.Request) error {
 	product := products.Product{}
-	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
+	if err := validate.Decode(r, &product); err != nil {
 		return err
 	}
 
@@ -54,11 +35,12 @@ func (rest RestHandler) createProduct(r *http.Request) error {
 func (rest RestHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	p, err := rest.getProduct(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
+		return
 	}
 
 	if err := json.NewEncoder(w).Encode(&p); err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
@@ -72,17 +54,20 @@ func (rest RestHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpError(w, err)
+	problem.Write(w, r, err)
 }
 
 func (rest RestHandler) updateProduct(r *http.Request) error {
 	req := products.Product{}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return JsonErr
+	if err := validate.Body(r, &req); err != nil {
+		return err
 	}
 
 	req.Name = chi.URLParam(r, "product_name")
 	req.SellerID = security.GetUser(r.Context()).Username
+	if err := validate.Struct(&req); err != nil {
+		return err
+	}
 
 	return rest.Update(r.Context(), req)
 }
@@ -93,7 +78,7 @@ func (rest RestHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpError(w, err)
+	problem.Write(w, r, err)
 }
 
 func (rest RestHandler) deleteProduct(r *http.Request) error {
@@ -14,6 +14,7 @@ import (
 	"github.com/artback/mvp/mocks"
 	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/repository"
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 )
 
@@ -36,8 +37,8 @@ func TestController_CreateProduct(t *testing.T) {
 	}{
 		{
 			name:            "successful create",
-			body:            []byte(`{"name": "product1","seller_id": "mike"}`),
-			insert:          products.Product{Name: "product1", SellerID: "mike"},
+			body:            []byte(`{"name": "product1","price": 5,"amount": 10}`),
+			insert:          products.Product{Name: "product1", SellerID: "mike", Price: 5, Amount: 10},
 			username:        "mike",
 			ServiceResponse: ServiceResponse{times: 1},
 			want:            http.StatusOK,
@@ -47,35 +48,50 @@ func TestController_CreateProduct(t *testing.T) {
 			body:            []byte(`{"name: "product1"}`),
 			username:        "mike",
 			ServiceResponse: ServiceResponse{times: 0},
-			want:            http.StatusInternalServerError,
+			want:            http.StatusBadRequest,
 		},
 		{
 			name:            "unsuccessful create, service error",
-			body:            []byte(`{"name": "product1","seller_id": "mike"}`),
-			insert:          products.Product{Name: "product1", SellerID: "mike"},
+			body:            []byte(`{"name": "product1","price": 5,"amount": 10}`),
+			insert:          products.Product{Name: "product1", SellerID: "mike", Price: 5, Amount: 10},
 			want:            http.StatusInternalServerError,
 			username:        "mike",
 			ServiceResponse: ServiceResponse{err: errors.New("something happened"), times: 1},
 		},
 		{
 			name:            "unsuccessful create, insert error",
-			body:            []byte(`{"name": "product1","seller_id": "mike"}`),
-			insert:          products.Product{Name: "product1", SellerID: "mike"},
+			body:            []byte(`{"name": "product1","price": 5,"amount": 10}`),
+			insert:          products.Product{Name: "product1", SellerID: "mike", Price: 5, Amount: 10},
 			want:            http.StatusInternalServerError,
 			username:        "mike",
 			ServiceResponse: ServiceResponse{err: errors.New("something happened"), times: 1},
 		},
 		{
 			name:            "unsuccessful create, Duplicate error",
-			body:            []byte(`{"name": "product1","seller_id": "mike"}`),
-			insert:          products.Product{Name: "product1", SellerID: "mike"},
+			body:            []byte(`{"name": "product1","price": 5,"amount": 10}`),
+			insert:          products.Product{Name: "product1", SellerID: "mike", Price: 5, Amount: 10},
 			want:            http.StatusConflict,
 			username:        "mike",
 			ServiceResponse: ServiceResponse{err: repository.DuplicateError{}, times: 1},
 		},
+		{
+			name:            "unsuccessful create, negative price and no name",
+			body:            []byte(`{"price": -5}`),
+			username:        "mike",
+			ServiceResponse: ServiceResponse{times: 0},
+			want:            http.StatusBadRequest,
+		},
+		{
+			name:            "unsuccessful create, unknown field",
+			body:            []byte(`{"name": "product1","price": 5,"seller_id": "sven"}`),
+			username:        "mike",
+			ServiceResponse: ServiceResponse{times: 0},
+			want:            http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
@@ -174,7 +190,7 @@ func TestController_UpdateProduct(t *testing.T) {
 		{
 			name:   "successful update",
 			body:   []byte(`{"price": 5}`),
-			update: products.Product{Price: 5, SellerID: "mike"},
+			update: products.Product{Name: "product1", Price: 5, SellerID: "mike"},
 			want:   http.StatusOK,
 			Service: ServiceResponse{
 				times: 1,
@@ -184,7 +200,7 @@ func TestController_UpdateProduct(t *testing.T) {
 		{
 			name:   "unsuccessful update, no products error",
 			body:   []byte(`{"price": 5}`),
-			update: products.Product{Price: 5, SellerID: "mike"},
+			update: products.Product{Name: "product1", Price: 5, SellerID: "mike"},
 			want:   http.StatusNotFound,
 			Service: ServiceResponse{
 				err:   repository.EmptyError{},
@@ -199,10 +215,17 @@ func TestController_UpdateProduct(t *testing.T) {
 			username: "mike",
 			want:     http.StatusBadRequest,
 		},
+		{
+			name:     "unsuccessful update, zero price",
+			body:     []byte(`{"price": 0}`),
+			Service:  ServiceResponse{times: 0},
+			username: "mike",
+			want:     http.StatusBadRequest,
+		},
 		{
 			name:     "unsuccessful update, insert error",
 			body:     []byte(`{"price": 5}`),
-			update:   products.Product{Price: 5, SellerID: "mike"},
+			update:   products.Product{Name: "product1", Price: 5, SellerID: "mike"},
 			want:     http.StatusInternalServerError,
 			username: "mike",
 			Service: ServiceResponse{
@@ -213,6 +236,7 @@ func TestController_UpdateProduct(t *testing.T) {
 	}
 
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
@@ -221,7 +245,10 @@ func TestController_UpdateProduct(t *testing.T) {
 			service.EXPECT().Update(gomock.Any(), tt.update).Return(tt.Service.err).Times(tt.Service.times)
 			co := RestHandler{Service: service}
 			w := httptest.NewRecorder()
-			ctx := security.WithUser(context.Background(), security.User{Username: tt.username})
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("product_name", "product1")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			ctx = security.WithUser(ctx, security.User{Username: tt.username})
 			req, _ := http.NewRequestWithContext(ctx, http.MethodPut, "/", bytes.NewReader(tt.body))
 			co.UpdateProduct(w, req)
 			if status := w.Code; status != tt.want {
@@ -1,84 +1,295 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
+	"expvar"
 	"fmt"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/api/docs"
+	"github.com/artback/mvp/pkg/api/graceful"
+	"github.com/artback/mvp/pkg/api/handler/adminhandler"
+	"github.com/artback/mvp/pkg/api/handler/alerthandler"
+	"github.com/artback/mvp/pkg/api/handler/eventhandler"
+	"github.com/artback/mvp/pkg/api/handler/healthhandler"
+	"github.com/artback/mvp/pkg/api/handler/policyhandler"
 	"github.com/artback/mvp/pkg/api/handler/producthandler"
 	"github.com/artback/mvp/pkg/api/handler/userhandler"
 	"github.com/artback/mvp/pkg/api/handler/vendinghandler"
+	"github.com/artback/mvp/pkg/api/handler/webhookhandler"
 	"github.com/artback/mvp/pkg/api/middleware/logging"
 	"github.com/artback/mvp/pkg/api/middleware/security"
 	"github.com/artback/mvp/pkg/api/middleware/security/basic"
+	"github.com/artback/mvp/pkg/api/middleware/security/cert"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/rpc"
 	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/health"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/artback/mvp/pkg/metrics"
+	"github.com/artback/mvp/pkg/notify"
+	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/repository/postgres"
+	"github.com/artback/mvp/pkg/tracing"
 	"github.com/artback/mvp/pkg/usecase"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/artback/mvp/pkg/webhook"
 	"github.com/casbin/casbin/v2"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
-	"log"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 	"net/http"
-	"path/filepath"
+	"time"
 )
 
-func printWalk(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
-	log.Printf("%s %s\n", method, route) // Walk and print out all routes
-
-	return nil
+// NewResetQueue sends up to 4 password resets at a time, 10 seconds each, and holds 100 more
+func NewResetQueue() *graceful.Queue {
+	return graceful.NewQueue(4, 100, 10*time.Second)
 }
 
-func HttpRouter(db *sql.DB, coins coin.Coins) (chi.Router, error) {
-	configPath, err := filepath.Abs("./config")
-	if err != nil {
-		return nil, err
+// logWalk logs every route of a router with l
+func logWalk(l *logrus.Logger) chi.WalkFunc {
+	return func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		l.WithFields(logrus.Fields{"method": method, "route": route}).Debug("route")
+
+		return nil
 	}
-	e, err := casbin.NewEnforcer(configPath+"/rbac_model.conf", configPath+"/auth_policy.csv")
-	if err != nil {
-		return nil, err
+}
+
+// Options configure the services behind the router
+type Options struct {
+	Coins   coin.Source
+	Lockout lockout.Policy
+	// AuthCache keeps verified credentials, Invalidator revokes them when a user changes
+	AuthCache      *basic.Cache
+	Invalidator    users.Invalidator
+	Hasher         pass.Hasher
+	PasswordPolicy pass.Policy
+	// Notifier delivers password reset tokens, valid for ResetTTL
+	Notifier notify.Notifier
+	ResetTTL time.Duration
+	// Resets sends password resets after the request asked for them, a queue run in the background when nil
+	Resets *graceful.Queue
+	// PolicyNotifier tells the other replicas about policy changes, nil without other replicas
+	PolicyNotifier policy.Notifier
+	// Events carries product and deposit changes to the event stream, a local bus when nil
+	Events *events.Bus
+	// RequestTimeout bounds every request but the event stream, 0 means no bound
+	RequestTimeout time.Duration
+	// Metrics counts requests, logins and sales, nil measures nothing
+	Metrics *metrics.Metrics
+	// Logger writes the lines of every request, logger.Default when nil
+	Logger *logrus.Logger
+	// Drain fails the readiness probe once it is started, nil never fails it
+	Drain *health.Drain
+}
+
+func (o Options) logger() *logrus.Logger {
+	if o.Logger == nil {
+		return logger.Default()
 	}
 
-	userService := usecase.UserService{Repository: postgres.UserRepository{DB: db}, Coins: coins}
+	return o.Logger
+}
+
+func HttpRouter(db *sql.DB, e *casbin.SyncedEnforcer, o Options) (chi.Router, error) {
+	s, auth := newServices(db, e, o)
 
 	router := chi.NewRouter()
+	// the span of a request covers every other middleware
+	router.Use(tracing.Middleware, middleware.RequestID)
+	if o.Metrics != nil {
+		// ahead of authentication, so refused requests are counted too
+		router.Use(o.Metrics.Middleware)
+	}
 	router.Use(
 		render.SetContentType(render.ContentTypeJSON),
-		logging.RequestLoggerMiddleware,
+		// every handler answers json, problem.Write and the docs page replace the header
+		middleware.SetHeader("Content-Type", "application/json"),
+		logging.RequestLogger(o.logger()),
 		middleware.Recoverer,
-		security.Authenticate(basic.Basic{Service: userService}),
-		security.Authorize(e),
+		security.Authenticate(auth, problem.Write),
+		security.Authorize(e, ownerRoutes(s.products, s.vending), problem.Write),
 	)
+	routes(router, s, o.RequestTimeout)
 
-	router.Route("/v1", func(r chi.Router) {
-		r.Route("/user", func(r chi.Router) {
-			service := userService
-			handler := userhandler.RestHandler{Service: service}
-			r.Post("/", handler.CreateUser)
-			r.Get("/{username}", handler.GetUser)
-			r.Put("/", handler.UpdateUser)
-			r.Delete("/", handler.DeleteUser)
-		})
-		r.Route("/product", func(r chi.Router) {
-			service := usecase.ProductService{Repository: postgres.ProductRepository{DB: db}}
-			handler := producthandler.RestHandler{Service: service}
-			r.Get("/{product_name}", handler.GetProduct)
-			r.Post("/", handler.CreateProduct)
-			r.Put("/{product_name}", handler.UpdateProduct)
-			r.Delete("/{product_name}", handler.DeleteProduct)
-		})
-		r.Route("/", func(r chi.Router) {
-			service := usecase.VendingService{Repository: postgres.VendingRepository{DB: db}, Coins: coins}
-			handler := vendinghandler.RestHandler{Service: service}
-			r.Get("/deposit", handler.GetAccount)
-			r.Put("/deposit", handler.Deposit)
-			r.Post("/buy/{product_name}", handler.BuyProduct)
-			r.Delete("/reset", handler.ResetDeposit)
-
-		})
-	})
-
-	if err := chi.Walk(router, printWalk); err != nil {
+	if err := chi.Walk(router, logWalk(o.logger())); err != nil {
 		return router, fmt.Errorf("logging err: %v", err)
 	}
 
 	return router, nil
 }
+
+// GrpcServer serves the user, product and vending use cases over gRPC with the authentication and policy of HttpRouter
+func GrpcServer(db *sql.DB, e *casbin.SyncedEnforcer, o Options, opts ...grpc.ServerOption) *grpc.Server {
+	s, auth := newServices(db, e, o)
+
+	return rpc.NewServer(rpc.Services{Users: s.users, Products: s.products, Vending: s.vending}, auth, e, ownerRoutes(s.products, s.vending), o.logger(), opts...)
+}
+
+// newServices builds the use cases on db and the authentication shared by both apis
+func newServices(db *sql.DB, e *casbin.SyncedEnforcer, o Options) (services, security.Auth) {
+	lockoutService := usecase.LockoutService{Repository: postgres.LockoutRepository{DB: db}, Policy: o.Lockout}
+	userService := usecase.UserService{
+		Repository:  postgres.UserRepository{DB: db},
+		Coins:       o.Coins,
+		Hasher:      o.Hasher,
+		Policy:      o.PasswordPolicy,
+		Invalidator: o.Invalidator,
+		Notifier:    o.Notifier,
+		ResetTTL:    o.ResetTTL,
+		Lockout:     lockoutService,
+	}
+	adminRepository := postgres.AdminRepository{DB: db}
+	bus := o.Events
+	if bus == nil {
+		bus = events.NewBus(nil)
+	}
+	resets := o.Resets
+	if resets == nil {
+		resets = NewResetQueue()
+		go resets.Run(context.Background())
+	}
+	vendingService := usecase.VendingService{Repository: postgres.VendingRepository{DB: db}, Coins: o.Coins, Events: bus}
+	// a basic auth header wins over a client certificate, machines present no header
+	var auth security.Auth = security.Chain{basic.Basic{Service: userService, Lockout: lockoutService, Cache: o.AuthCache}, cert.Cert{}}
+	if o.Metrics != nil {
+		vendingService.Recorder = o.Metrics
+		auth = metrics.Auth{Auth: auth, Metrics: o.Metrics}
+	}
+	s := services{
+		users:    userService,
+		products: usecase.ProductService{Repository: postgres.ProductRepository{DB: db}, Events: bus},
+		vending:  vendingService,
+		admin:    usecase.AdminService{Repository: adminRepository, Lockout: lockoutService, Invalidator: o.Invalidator, Events: bus},
+		policies: usecase.PolicyService{Enforcer: e, Repository: postgres.PolicyRepository{DB: db}, Notifier: o.PolicyNotifier},
+		webhooks: usecase.WebhookService{Repository: postgres.WebhookRepository{DB: db}},
+		alerts:   usecase.AlertService{Repository: postgres.AlertRepository{DB: db}},
+		health:   usecase.HealthService{Repository: postgres.HealthRepository{DB: db}, Policy: e, Schema: postgres.SchemaVersion, Drain: o.Drain},
+		events:   bus,
+		resets:   resets,
+		// a lockout service of its own, reset requests never lock a login
+		resetLockout: usecase.LockoutService{
+			Repository: postgres.LockoutRepository{DB: db}, Policy: lockout.ResetPolicy, Scope: lockout.ResetScope,
+		},
+	}
+
+	return s, auth
+}
+
+// services are the use cases behind the routes
+type services struct {
+	users    users.Service
+	products products.Service
+	vending  vending.Service
+	admin    admin.Service
+	policies policy.Service
+	webhooks webhook.Service
+	alerts   alert.Service
+	health   health.Service
+	events   *events.Bus
+	// resets and resetLockout send and throttle password resets
+	resets       *graceful.Queue
+	resetLockout lockout.Service
+}
+
+// routes registers every endpoint on r, docs/openapi.json has to describe each of them.
+// Requests are cancelled after timeout, except the event stream which lasts as long as the client stays.
+func routes(r chi.Router, s services, timeout time.Duration) {
+	// chi answers unrouted requests in text/plain, they get a problem like every other error
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) { problem.Write(w, r, problem.NotFoundErr) })
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) { problem.Write(w, r, problem.MethodNotAllowedErr) })
+
+	// probes stay outside /v1, orchestrators expect them at the root
+	probes := healthhandler.RestHandler{Service: s.health}
+	r.Get("/healthz", probes.Live)
+	r.Get("/readyz", probes.Ready)
+
+	r.Route("/v1", func(r chi.Router) {
+		stream := eventhandler.RestHandler{Bus: s.events, Products: s.products, Vending: s.vending, LoadTimeout: timeout}
+		r.Get("/events", stream.Events)
+
+		r.Group(func(r chi.Router) {
+			if timeout > 0 {
+				r.Use(middleware.Timeout(timeout))
+			}
+			r.Get("/openapi.json", docs.SpecHandler)
+			r.Get("/docs", docs.UIHandler)
+			r.Route("/user", func(r chi.Router) {
+				handler := userhandler.RestHandler{Service: s.users, Resets: s.resets, ResetLockout: s.resetLockout}
+				r.Post("/", handler.CreateUser)
+				r.Get("/{username}", handler.GetUser)
+				r.Patch("/", handler.UpdateUser)
+				// PUT takes the same merge patch, kept for clients written before PATCH was supported
+				r.Put("/", handler.UpdateUser)
+				r.Delete("/", handler.DeleteUser)
+				r.Post("/role", handler.ChangeRole)
+				r.Post("/password-reset", handler.RequestPasswordReset)
+				r.Post("/password-reset/confirm", handler.ResetPassword)
+			})
+			r.Route("/product", func(r chi.Router) {
+				handler := producthandler.RestHandler{Service: s.products}
+				r.Get("/{product_name}", handler.GetProduct)
+				r.Post("/", handler.CreateProduct)
+				r.Put("/{product_name}", handler.UpdateProduct)
+				r.Delete("/{product_name}", handler.DeleteProduct)
+
+				alerts := alerthandler.RestHandler{Service: s.alerts}
+				r.Put("/{product_name}/threshold", alerts.SetThreshold)
+			})
+			r.Route("/", func(r chi.Router) {
+				handler := vendinghandler.RestHandler{Service: s.vending}
+				r.Get("/deposit", handler.GetAccount)
+				r.Put("/deposit", handler.Deposit)
+				r.Post("/buy/{product_name}", handler.BuyProduct)
+				r.Delete("/reset", handler.ResetDeposit)
+				r.Get("/transaction/{id}", handler.GetTransaction)
+			})
+			r.Route("/webhooks", func(r chi.Router) {
+				// endpoints are looked up with the seller, so other sellers' endpoints are missing rather than forbidden
+				handler := webhookhandler.RestHandler{Service: s.webhooks}
+				r.Get("/", handler.Endpoints)
+				r.Post("/", handler.Register)
+				r.Delete("/{id}", handler.DeleteEndpoint)
+				r.Get("/{id}/deliveries", handler.Deliveries)
+				r.Post("/deliveries/{id}/redeliver", handler.Redeliver)
+			})
+			r.Route("/alerts", func(r chi.Router) {
+				handler := alerthandler.RestHandler{Service: s.alerts}
+				r.Get("/", handler.Alerts)
+				r.Post("/{id}/acknowledge", handler.Acknowledge)
+			})
+			r.Route("/admin", func(r chi.Router) {
+				handler := adminhandler.RestHandler{Service: s.admin}
+				r.Get("/users", handler.ListUsers)
+				r.Put("/users/{username}/role", handler.SetRole)
+				r.Post("/users/{username}/lock", handler.Lock)
+				r.Post("/users/{username}/unlock", handler.Unlock)
+				r.Post("/users/{username}/password-reset", handler.ForcePasswordReset)
+				r.Post("/users/{username}/deposit", handler.AdjustDeposit)
+				r.Get("/audit", handler.AuditLog)
+				r.Get("/role-requests", handler.RoleRequests)
+				r.Post("/role-requests/{id}/approve", handler.ApproveRoleRequest)
+				r.Post("/role-requests/{id}/reject", handler.RejectRoleRequest)
+
+				policies := policyhandler.RestHandler{Service: s.policies}
+				r.Get("/policies", policies.Rules)
+				r.Post("/policies", policies.AddRule)
+				r.Delete("/policies", policies.RemoveRule)
+				r.Get("/role-assignments", policies.Assignments)
+				r.Post("/role-assignments", policies.AddAssignment)
+				r.Delete("/role-assignments", policies.RemoveAssignment)
+
+				r.Get("/vars", expvar.Handler().ServeHTTP)
+			})
+		})
+	})
+}
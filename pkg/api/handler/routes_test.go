@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRoutes_Unrouted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+		code   string
+	}{
+		{name: "unknown path", method: http.MethodGet, target: "/wp-login.php", want: http.StatusNotFound, code: "not_found"},
+		{name: "unknown path below v1", method: http.MethodGet, target: "/v1/nothing", want: http.StatusNotFound, code: "not_found"},
+		{name: "unserved method", method: http.MethodDelete, target: "/v1/deposit", want: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+	}
+
+	router := chi.NewRouter()
+	routes(router, services{}, time.Minute)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Content-Type"); got != problem.ContentType {
+				t.Errorf("Content-Type = %q, want %q", got, problem.ContentType)
+			}
+			if !strings.Contains(w.Body.String(), `"code":"`+tt.code+`"`) {
+				t.Errorf("body = %s, want code %s", w.Body, tt.code)
+			}
+		})
+	}
+}
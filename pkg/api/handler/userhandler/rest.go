@@ -1,47 +1,43 @@
 package userhandler
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"github.com/artback/mvp/pkg/api/graceful"
+	"github.com/artback/mvp/pkg/api/middleware/logging"
 	"github.com/artback/mvp/pkg/api/middleware/security"
-	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/lockout"
 	"github.com/artback/mvp/pkg/users"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
 
-var InvalidUserFormErr = errors.New("invalid user form")
+var (
+	InvalidUserFormErr = fmt.Errorf("%w: invalid user form", problem.MalformedErr)
+	// RoleInPatchErr refuses a role in an account update, POST /v1/user/role changes it
+	RoleInPatchErr = fmt.Errorf("%w: role can't be patched, use POST /v1/user/role", problem.MalformedErr)
+)
 
 type RestHandler struct {
 	users.Service
-}
-
-func httpError(w http.ResponseWriter, err error) {
-	var code int
-
-	switch {
-	case errors.As(err, &repository.DuplicateError{}):
-		code = http.StatusConflict
-	case errors.As(err, &repository.EmptyError{}):
-		code = http.StatusNotFound
-	case errors.Is(err, InvalidUserFormErr):
-		code = http.StatusBadRequest
-	default:
-		code = http.StatusInternalServerError
-	}
-
-	http.Error(w, http.StatusText(code), code)
+	// Resets sends the requested password resets after the answer
+	Resets *graceful.Queue
+	// ResetLockout throttles password reset requests per username and client, nil disables throttling
+	ResetLockout lockout.Service
 }
 
 func (rest RestHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	user, err := rest.getUser(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 		return
 	}
 
 	if err := json.NewEncoder(w).Encode(&user); err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 		return
 	}
 }
@@ -50,27 +46,64 @@ func (rest RestHandler) getUser(r *http.Request) (*users.Response, error) {
 	return rest.GetResponse(r.Context(), chi.URLParam(r, "username"))
 }
 
+// UpdateUser applies a JSON merge patch (RFC 7396) to the own account
 func (rest RestHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if err := rest.updateUser(r); err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
 func (rest RestHandler) updateUser(r *http.Request) error {
-	user := users.User{}
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		return InvalidUserFormErr
+	patch, err := decodePatch(r)
+	if err != nil {
+		return err
+	}
+	// Only the user can change its own data, the username is never taken from the request
+	username := security.GetUser(r.Context()).Username
+
+	return rest.Patch(r.Context(), username, patch)
+}
+
+// decodePatch refuses the role, unknown members and nulls, no member of a user can be removed
+func decodePatch(r *http.Request) (users.Patch, error) {
+	body, err := validate.Read(r)
+	if err != nil {
+		return users.Patch{}, err
 	}
-	// Overwrite any username input from the request, Only the user can change its own data
-	user.Username = security.GetUser(r.Context()).Username
 
-	return rest.Update(r.Context(), user)
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &members); err != nil {
+		return users.Patch{}, InvalidUserFormErr
+	}
+	if _, ok := members["role"]; ok {
+		return users.Patch{}, RoleInPatchErr
+	}
+	for member, value := range members {
+		if string(value) != "null" {
+			continue
+		}
+		// contact is the only optional member, null clears it
+		if member != "contact" {
+			return users.Patch{}, InvalidUserFormErr
+		}
+		members[member] = json.RawMessage(`""`)
+	}
+	if body, err = json.Marshal(members); err != nil {
+		return users.Patch{}, InvalidUserFormErr
+	}
+
+	patch := users.Patch{}
+	if err := validate.Unmarshal(body, &patch); err != nil {
+		return users.Patch{}, err
+	}
+
+	return patch, validate.Struct(&patch)
 }
 
 func (rest RestHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	err := rest.deleteUser(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
@@ -82,15 +115,100 @@ func (rest RestHandler) deleteUser(r *http.Request) error {
 func (rest RestHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	err := rest.createUser(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
 func (rest RestHandler) createUser(r *http.Request) error {
 	user := users.User{}
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		return InvalidUserFormErr
+	if err := validate.Decode(r, &user); err != nil {
+		return err
 	}
 
 	return rest.Insert(r.Context(), user)
 }
+
+func (rest RestHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	transition, err := rest.changeRole(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if transition == users.NeedsApproval {
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (rest RestHandler) changeRole(r *http.Request) (users.Transition, error) {
+	req := struct {
+		Role security.Role `json:"role" validate:"required"`
+	}{}
+	if err := validate.Decode(r, &req); err != nil {
+		return users.Forbidden, err
+	}
+	username := security.GetUser(r.Context()).Username
+
+	return rest.Service.ChangeRole(r.Context(), username, req.Role)
+}
+
+// RequestPasswordReset answers 202 unless the username or client asked too often, the work happens afterwards
+// so neither answer nor timing tell whether the user exists
+func (rest RestHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if err := rest.requestPasswordReset(r); err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (rest RestHandler) requestPasswordReset(r *http.Request) error {
+	req := struct {
+		Username string `json:"username" validate:"required"`
+	}{}
+	if err := validate.Decode(r, &req); err != nil {
+		return err
+	}
+	if rest.ResetLockout != nil {
+		// every request counts, whether the user exists or not
+		ip := security.ClientIP(r)
+		if _, err := rest.ResetLockout.Check(r.Context(), req.Username, ip); err != nil {
+			return err
+		}
+		if err := rest.ResetLockout.Fail(r.Context(), req.Username, ip); err != nil {
+			return err
+		}
+	}
+
+	log := logging.From(r).WithField("reset_username", req.Username)
+	queued := rest.Resets.Push(func(ctx context.Context) {
+		if err := rest.Service.RequestPasswordReset(ctx, req.Username); err != nil {
+			log.WithError(err).Warn("password reset")
+		}
+	})
+	if !queued {
+		// answering otherwise would tell the request apart, the user asks again
+		log.Warn("password reset queue is full, request dropped")
+	}
+
+	return nil
+}
+
+func (rest RestHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if err := rest.resetPassword(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) resetPassword(r *http.Request) error {
+	req := struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}{}
+	if err := validate.Decode(r, &req); err != nil {
+		return err
+	}
+
+	return rest.Service.ResetPassword(r.Context(), req.Token, req.Password)
+}
@@ -10,8 +10,10 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/api/graceful"
 	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/users"
 	"github.com/golang/mock/gomock"
@@ -76,7 +78,7 @@ func TestController_GetUser(t *testing.T) {
 			defer mockCtrl.Finish()
 			service := mocks.NewUserService(mockCtrl)
 			service.EXPECT().GetResponse(gomock.Any(), gomock.Any()).Return(&tt.Service.response, tt.Service.err).Times(tt.Service.times)
-			co := RestHandler{service}
+			co := RestHandler{Service: service}
 			req, _ := http.NewRequest(http.MethodGet, "/", nil)
 			recorder := httptest.NewRecorder()
 			co.GetUser(recorder, req)
@@ -97,22 +99,30 @@ func TestController_GetUser(t *testing.T) {
 func TestController_UpdateUser(t *testing.T) {
 	t.Parallel()
 
+	password, current, empty := "new password", "old password", ""
+
 	tests := []struct {
 		name     string
 		body     []byte
 		want     int
 		username string
-		user     users.User
+		patch    users.Patch
 		Service  serviceResponse
 	}{
 		{
-			name:     "successful update",
-			body:     []byte(`{"username": "user1","password":"password","role": "buyer","deposit": 100}`),
-			want:     200,
+			name:     "successful password change",
+			body:     []byte(`{"password":"new password","current_password":"old password"}`),
+			want:     http.StatusOK,
 			username: "mike",
-			user:     users.User{Username: "mike", Password: "password", Role: security.Buyer, Deposit: 100},
+			patch:    users.Patch{Password: &password, CurrentPassword: &current},
 			Service:  serviceResponse{times: 1},
 		},
+		{
+			name:    "unsuccessful update, role is changed elsewhere",
+			body:    []byte(`{"role":"seller"}`),
+			Service: serviceResponse{times: 0},
+			want:    http.StatusBadRequest,
+		},
 		{
 			name:    "unsuccessful update, json decoder",
 			body:    []byte(`{"username: "user1"}`),
@@ -120,34 +130,63 @@ func TestController_UpdateUser(t *testing.T) {
 			want:    http.StatusBadRequest,
 		},
 		{
-			name:     "unsuccessful update, insert error",
-			body:     []byte(`{"username": "user1","password":"password","role": "buyer","deposit": 100}`),
+			name:    "unsuccessful update, read only member",
+			body:    []byte(`{"username": "user1","deposit": 100}`),
+			Service: serviceResponse{times: 0},
+			want:    http.StatusBadRequest,
+		},
+		{
+			name:     "null clears contact",
+			body:     []byte(`{"contact": null}`),
+			want:     http.StatusOK,
 			username: "mike",
-			user:     users.User{Username: "mike", Password: "password", Role: security.Buyer, Deposit: 100},
-			want:     http.StatusInternalServerError,
-			Service:  serviceResponse{err: errors.New("something happened"), times: 1},
+			patch:    users.Patch{Contact: &empty},
+			Service:  serviceResponse{times: 1},
+		},
+		{
+			name:    "unsuccessful update, member can't be removed",
+			body:    []byte(`{"password": null}`),
+			Service: serviceResponse{times: 0},
+			want:    http.StatusBadRequest,
+		},
+		{
+			name:     "unsuccessful update, missing current password",
+			body:     []byte(`{"password":"new password"}`),
+			username: "mike",
+			patch:    users.Patch{Password: &password},
+			want:     http.StatusBadRequest,
+			Service:  serviceResponse{err: users.CurrentPasswordRequiredErr, times: 1},
+		},
+		{
+			name:     "unsuccessful update, wrong current password",
+			body:     []byte(`{"password":"new password","current_password":"old password"}`),
+			username: "mike",
+			patch:    users.Patch{Password: &password, CurrentPassword: &current},
+			want:     http.StatusForbidden,
+			Service:  serviceResponse{err: users.CurrentPasswordWrongErr, times: 1},
 		},
 		{
 			name:     "unsuccessful update ,error empty response repository",
-			body:     []byte(`{"username": "user1","password":"password","role": "buyer","deposit": 100}`),
+			body:     []byte(`{"password":"new password","current_password":"old password"}`),
 			username: "mike",
-			user:     users.User{Username: "mike", Password: "password", Role: security.Buyer, Deposit: 100},
+			patch:    users.Patch{Password: &password, CurrentPassword: &current},
 			Service:  serviceResponse{err: repository.EmptyError{}, times: 1},
 			want:     http.StatusNotFound,
 		},
 	}
 
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
 			service := mocks.NewUserService(mockCtrl)
-			co := RestHandler{service}
-			service.EXPECT().Update(gomock.Any(), tt.user).Return(tt.Service.err).Times(tt.Service.times)
+			co := RestHandler{Service: service}
+			service.EXPECT().Patch(gomock.Any(), tt.username, tt.patch).Return(tt.Service.err).Times(tt.Service.times)
 			w := httptest.NewRecorder()
 			ctx := security.WithUser(context.Background(), security.User{Username: tt.username})
-			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", bytes.NewReader(tt.body))
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPatch, "/", bytes.NewReader(tt.body))
 			co.UpdateUser(w, req)
 			if status := w.Code; status != tt.want {
 				t.Errorf("handler returned wrong status code: got %v want %v",
@@ -202,7 +241,7 @@ func TestController_DeleteUser(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
 			service := mocks.NewUserService(mockCtrl)
-			co := RestHandler{service}
+			co := RestHandler{Service: service}
 			service.EXPECT().Delete(gomock.Any(), tt.username).Return(tt.Service.err).Times(tt.Service.times)
 			w := httptest.NewRecorder()
 			ctx := security.WithUser(context.Background(), security.User{Username: tt.username})
@@ -257,7 +296,7 @@ func TestController_CreateUser(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
 			service := mocks.NewUserService(mockCtrl)
-			co := RestHandler{service}
+			co := RestHandler{Service: service}
 			service.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(tt.Service.err).Times(tt.Service.times)
 			req, _ := http.NewRequest(http.MethodGet, "/", bytes.NewReader(tt.body))
 			w := httptest.NewRecorder()
@@ -269,3 +308,181 @@ func TestController_CreateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestController_ChangeRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       []byte
+		times      int
+		transition users.Transition
+		err        error
+		want       int
+	}{
+		{
+			name:       "immediate change",
+			body:       []byte(`{"role": "buyer"}`),
+			times:      1,
+			transition: users.Immediate,
+			want:       http.StatusOK,
+		},
+		{
+			name:       "change needs approval",
+			body:       []byte(`{"role": "buyer"}`),
+			times:      1,
+			transition: users.NeedsApproval,
+			want:       http.StatusAccepted,
+		},
+		{
+			name:  "change not allowed",
+			body:  []byte(`{"role": "buyer"}`),
+			times: 1,
+			err:   users.RoleNotAllowedErr,
+			want:  http.StatusForbidden,
+		},
+		{
+			name:  "pending request exists",
+			body:  []byte(`{"role": "buyer"}`),
+			times: 1,
+			err:   repository.DuplicateError{},
+			want:  http.StatusConflict,
+		},
+		{
+			name: "unsuccessful, json decoder",
+			body: []byte(`{"role: "buyer"}`),
+			want: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			service := mocks.NewUserService(mockCtrl)
+			co := RestHandler{Service: service}
+			service.EXPECT().ChangeRole(gomock.Any(), "mike", security.Buyer).Return(tt.transition, tt.err).Times(tt.times)
+			w := httptest.NewRecorder()
+			ctx := security.WithUser(context.Background(), security.User{Username: "mike"})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(tt.body))
+			co.ChangeRole(w, req)
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_RequestPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	throttled := security.RetryError{Err: security.ThrottledErr, RetryAfter: time.Minute}
+
+	tests := []struct {
+		name       string
+		body       []byte
+		checkTimes int
+		checkErr   error
+		failTimes  int
+		err        error
+		times      int
+		want       int
+	}{
+		{name: "accepted", body: []byte(`{"username":"mike"}`), checkTimes: 1, failTimes: 1, times: 1, want: http.StatusAccepted},
+		{name: "accepted whatever the outcome", body: []byte(`{"username":"mike"}`), checkTimes: 1, failTimes: 1, err: errors.New("something happened"), times: 1, want: http.StatusAccepted},
+		{name: "throttled", body: []byte(`{"username":"mike"}`), checkTimes: 1, checkErr: throttled, want: http.StatusTooManyRequests},
+		{name: "missing username", body: []byte(`{}`), want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			service := mocks.NewUserService(mockCtrl)
+			requested := make(chan struct{})
+			service.EXPECT().RequestPasswordReset(gomock.Any(), "mike").DoAndReturn(func(context.Context, string) error {
+				close(requested)
+				return tt.err
+			}).Times(tt.times)
+			lockout := mocks.NewLockoutService(mockCtrl)
+			lockout.EXPECT().Check(gomock.Any(), "mike", "10.0.0.1").Return(false, tt.checkErr).Times(tt.checkTimes)
+			lockout.EXPECT().Fail(gomock.Any(), "mike", "10.0.0.1").Return(nil).Times(tt.failTimes)
+			resets := graceful.NewQueue(1, 1, time.Second)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go resets.Run(ctx)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			req.RemoteAddr = "10.0.0.1:1234"
+			RestHandler{Service: service, Resets: resets, ResetLockout: lockout}.RequestPasswordReset(w, req)
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.want)
+			}
+			if tt.times == 0 {
+				return
+			}
+			select {
+			case <-requested:
+			case <-time.After(5 * time.Second):
+				t.Error("RequestPasswordReset() never reached the service")
+			}
+		})
+	}
+}
+
+func TestController_RequestPasswordResetQueueFull(t *testing.T) {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// nothing runs the queue, the first request fills it and the second is dropped
+	resets := graceful.NewQueue(1, 1, time.Second)
+	handler := RestHandler{Service: mocks.NewUserService(mockCtrl), Resets: resets}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"username":"mike"}`)))
+		handler.RequestPasswordReset(w, req)
+		if w.Code != http.StatusAccepted {
+			t.Errorf("request %d: handler returned wrong status code: got %v want %v", i, w.Code, http.StatusAccepted)
+		}
+	}
+}
+
+func TestController_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		body  []byte
+		err   error
+		times int
+		want  int
+	}{
+		{name: "successful", body: []byte(`{"token":"abc","password":"new password"}`), times: 1, want: http.StatusOK},
+		{name: "invalid token", body: []byte(`{"token":"abc","password":"new password"}`), err: users.InvalidResetTokenErr, times: 1, want: http.StatusBadRequest},
+		{name: "missing token", body: []byte(`{"password":"new password"}`), want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			service := mocks.NewUserService(mockCtrl)
+			service.EXPECT().ResetPassword(gomock.Any(), "abc", "new password").Return(tt.err).Times(tt.times)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			RestHandler{Service: service}.ResetPassword(w, req)
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.want)
+			}
+		})
+	}
+}
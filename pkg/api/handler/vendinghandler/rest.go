@@ -2,8 +2,9 @@ package vendinghandler
 
 import (
 	"encoding/json"
-	"errors"
 	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
 	"github.com/artback/mvp/pkg/change"
 	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/repository"
@@ -17,30 +18,15 @@ type RestHandler struct {
 	vending.Service
 }
 
-func httpError(w http.ResponseWriter, err error) {
-	var code int
-
-	switch {
-	case errors.Is(err, repository.EmptyError{}):
-		code = http.StatusNotFound
-	case errors.Is(err, repository.InvalidError{}):
-		code = http.StatusNotAcceptable
-	default:
-		code = http.StatusInternalServerError
-	}
-
-	http.Error(w, err.Error(), code)
-}
-
 func (re RestHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
 	account, err := re.getAccount(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 		return
 	}
 
 	if err := json.NewEncoder(w).Encode(&account); err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
@@ -52,7 +38,7 @@ func (re RestHandler) getAccount(r *http.Request) (*vending.Response, error) {
 func (re RestHandler) ResetDeposit(w http.ResponseWriter, r *http.Request) {
 	err := re.resetDeposit(r)
 	if err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
@@ -63,42 +49,58 @@ func (re RestHandler) resetDeposit(r *http.Request) error {
 
 func (re RestHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 	if err := re.deposit(r); err != nil {
-		httpError(w, err)
+		problem.Write(w, r, err)
 	}
 }
 
 func (re RestHandler) deposit(r *http.Request) error {
 	deposit := change.Deposit{}
-	if err := json.NewDecoder(r.Body).Decode(&deposit); err != nil {
+	if err := validate.Decode(r, &deposit); err != nil {
 		return err
 	}
 
 	username := security.GetUser(r.Context()).Username
-	return re.IncrementDeposit(r.Context(), username, deposit.ToAmount())
+	return re.Service.Deposit(r.Context(), username, deposit)
 }
 
 func (re RestHandler) BuyProduct(w http.ResponseWriter, r *http.Request) {
 	err := re.buyProduct(r)
 	if err != nil {
-		httpError(w, err)
-	}
-}
-
-func atoiWithDefault(str string, def int) int {
-	amount, err := strconv.Atoi(str)
-	if err != nil {
-		return def
+		problem.Write(w, r, err)
 	}
-
-	return amount
 }
 
 func (re RestHandler) buyProduct(r *http.Request) error {
-	amount := r.URL.Query().Get("amount")
+	query := validate.NewQuery(r)
+	amount := query.Int("amount", 1, 1)
+	if err := query.Err(); err != nil {
+		return err
+	}
 	username := security.GetUser(r.Context()).Username
 
 	return re.Service.BuyProduct(r.Context(), username, products.Product{
 		Name:   chi.URLParam(r, "product_name"),
-		Amount: atoiWithDefault(amount, 1),
+		Amount: amount,
 	})
 }
+
+func (re RestHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	transaction, err := re.getTransaction(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&transaction); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (re RestHandler) getTransaction(r *http.Request) (*vending.Transaction, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, repository.EmptyError{}
+	}
+
+	return re.Service.GetTransaction(r.Context(), id)
+}
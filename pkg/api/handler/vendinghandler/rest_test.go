@@ -17,6 +17,7 @@ import (
 	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/vending"
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 )
 
@@ -34,6 +35,7 @@ func TestController_BuyProduct(t *testing.T) {
 	tests := []struct {
 		name       string
 		buyProduct ServiceResponse
+		query      string
 		username   string
 		want       want
 	}{
@@ -69,9 +71,43 @@ func TestController_BuyProduct(t *testing.T) {
 				code: http.StatusNotAcceptable,
 			},
 		},
+		{
+			name: "unsuccessful, insufficient funds",
+			buyProduct: ServiceResponse{
+				err:   vending.InsufficientFundsError{Cost: 25, Deposit: 10},
+				times: 1,
+			},
+			username: "mike",
+			want: want{
+				code: http.StatusPaymentRequired,
+			},
+		},
+		{
+			name: "unsuccessful, zero amount",
+			buyProduct: ServiceResponse{
+				times: 0,
+			},
+			query:    "?amount=0",
+			username: "mike",
+			want: want{
+				code: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "unsuccessful, out of stock",
+			buyProduct: ServiceResponse{
+				err:   vending.OutOfStockError{Product: "cola", Requested: 3, Available: 1},
+				times: 1,
+			},
+			username: "mike",
+			want: want{
+				code: http.StatusConflict,
+			},
+		},
 	}
 
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
@@ -80,7 +116,7 @@ func TestController_BuyProduct(t *testing.T) {
 			s.EXPECT().BuyProduct(gomock.Any(), tt.username, gomock.Any()).Return(tt.buyProduct.err).Times(tt.buyProduct.times)
 			co := RestHandler{Service: s}
 			ctx := security.WithUser(context.Background(), security.User{Username: tt.username})
-			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/"+tt.query, nil)
 			w := httptest.NewRecorder()
 			co.BuyProduct(w, req)
 			if status := w.Code; status != tt.want.code {
@@ -148,7 +184,12 @@ func TestController_Deposit(t *testing.T) {
 			defer mockCtrl.Finish()
 
 			s := mocks.NewVendingService(mockCtrl)
-			s.EXPECT().IncrementDeposit(gomock.Any(), tt.username, tt.want.deposit).Return(tt.err).Times(tt.times)
+			s.EXPECT().Deposit(gomock.Any(), tt.username, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, coins change.Deposit) error {
+				if coins.ToAmount() != tt.want.deposit {
+					t.Errorf("Deposit() coins worth %v, want %v", coins.ToAmount(), tt.want.deposit)
+				}
+				return tt.err
+			}).Times(tt.times)
 			co := RestHandler{Service: s}
 			ctx := security.WithUser(context.Background(), security.User{Username: tt.username})
 			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", bytes.NewReader(tt.body))
@@ -305,50 +346,38 @@ func TestController_GetAccount(t *testing.T) {
 	}
 }
 
-func Test_toAmount(t *testing.T) {
+func TestController_GetTransaction(t *testing.T) {
 	t.Parallel()
 
-	type args struct {
-		query    string
-		defaults int
-	}
-
 	tests := []struct {
-		name string
-		args args
-		want int
+		name  string
+		id    string
+		times int
+		err   error
+		want  int
 	}{
-		{
-			name: "default amount",
-			args: args{
-				query:    "",
-				defaults: 1,
-			},
-			want: 1,
-		},
-		{
-			name: "non default amount",
-			args: args{
-				query:    "10",
-				defaults: 1,
-			},
-			want: 10,
-		},
-		{
-			name: "non number amount,return default",
-			args: args{
-				query:    "hello",
-				defaults: 1,
-			},
-			want: 1,
-		},
+		{name: "successful", id: "7", times: 1, want: http.StatusOK},
+		{name: "unsuccessful, not found", id: "7", times: 1, err: repository.EmptyError{}, want: http.StatusNotFound},
+		{name: "unsuccessful, non number id", id: "seven", want: http.StatusNotFound},
 	}
 
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			if got := atoiWithDefault(tt.args.query, tt.args.defaults); got != tt.want {
-				t.Errorf("toAmount() = %v, want %v", got, tt.want)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewVendingService(mockCtrl)
+			s.EXPECT().GetTransaction(gomock.Any(), 7).Return(&vending.Transaction{ID: 7}, tt.err).Times(tt.times)
+			co := RestHandler{Service: s}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req, _ := http.NewRequestWithContext(context.WithValue(context.Background(), chi.RouteCtxKey, rctx), http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			co.GetTransaction(w, req)
+			if status := w.Code; status != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.want)
 			}
 		})
 	}
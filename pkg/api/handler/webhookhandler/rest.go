@@ -0,0 +1,118 @@
+package webhookhandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+var InvalidIDErr = fmt.Errorf("%w: invalid webhook id", problem.MalformedErr)
+
+type RestHandler struct {
+	webhook.Service
+}
+
+type registerRequest struct {
+	URL    string         `json:"url" validate:"required,max=2048"`
+	Events []webhook.Kind `json:"events"`
+}
+
+func (rest RestHandler) Register(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := rest.register(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(endpoint); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) register(r *http.Request) (*webhook.Endpoint, error) {
+	req := registerRequest{}
+	if err := validate.Decode(r, &req); err != nil {
+		return nil, err
+	}
+	seller := security.GetUser(r.Context()).Username
+
+	return rest.Service.Register(r.Context(), seller, req.URL, req.Events)
+}
+
+func (rest RestHandler) Endpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := rest.Service.Endpoints(r.Context(), security.GetUser(r.Context()).Username)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&endpoints); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	if err := rest.deleteEndpoint(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) deleteEndpoint(r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return InvalidIDErr
+	}
+
+	return rest.Service.DeleteEndpoint(r.Context(), security.GetUser(r.Context()).Username, id)
+}
+
+func (rest RestHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := rest.deliveries(r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&deliveries); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) deliveries(r *http.Request) ([]webhook.Delivery, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, InvalidIDErr
+	}
+	status := webhook.Status(r.URL.Query().Get("status"))
+	switch status {
+	case "", webhook.Pending, webhook.Delivered, webhook.Dead:
+	default:
+		return nil, problem.ValidationError{Fields: []problem.FieldError{
+			{Field: "status", Code: "oneof", Message: "must be one of pending, delivered, dead"},
+		}}
+	}
+
+	return rest.Service.Deliveries(r.Context(), security.GetUser(r.Context()).Username, id, status)
+}
+
+func (rest RestHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if err := rest.redeliver(r); err != nil {
+		problem.Write(w, r, err)
+	}
+}
+
+func (rest RestHandler) redeliver(r *http.Request) error {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return InvalidIDErr
+	}
+
+	return rest.Service.Redeliver(r.Context(), security.GetUser(r.Context()).Username, id)
+}
@@ -0,0 +1,91 @@
+package webhookhandler
+
+import (
+	"bytes"
+	"context"
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestHandler_Register(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		body  string
+		times int
+		err   error
+		want  int
+	}{
+		{name: "registered", body: `{"url":"https://seller.example/hook","events":["product.sold"]}`, times: 1, want: http.StatusCreated},
+		{name: "missing url", body: `{"events":["product.sold"]}`, want: http.StatusBadRequest},
+		{name: "invalid url", body: `{"url":"seller.example"}`, times: 1, err: webhook.InvalidURLErr, want: http.StatusBadRequest},
+		{name: "unknown kind", body: `{"url":"https://seller.example/hook","events":["x"]}`, times: 1, err: webhook.UnknownKindErr, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewWebhookService(mockCtrl)
+			s.EXPECT().Register(gomock.Any(), "sam", gomock.Any(), gomock.Any()).Return(&webhook.Endpoint{ID: 1}, tt.err).Times(tt.times)
+			ctx := security.WithUser(context.Background(), security.User{Username: "sam", Role: security.Seller})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+			RestHandler{Service: s}.Register(w, req)
+			if w.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestHandler_Deliveries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		times  int
+		status webhook.Status
+		err    error
+		want   int
+	}{
+		{name: "every status", id: "1", times: 1, want: http.StatusOK},
+		{name: "dead letters", id: "1", query: "?status=dead", times: 1, status: webhook.Dead, want: http.StatusOK},
+		{name: "unknown status", id: "1", query: "?status=lost", want: http.StatusBadRequest},
+		{name: "invalid id", id: "one", want: http.StatusBadRequest},
+		{name: "endpoint of another seller", id: "2", times: 1, err: repository.EmptyError{}, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			s := mocks.NewWebhookService(mockCtrl)
+			s.EXPECT().Deliveries(gomock.Any(), "sam", gomock.Any(), tt.status).Return([]webhook.Delivery{}, tt.err).Times(tt.times)
+			ctx := security.WithUser(context.Background(), security.User{Username: "sam", Role: security.Seller})
+			route := chi.NewRouteContext()
+			route.URLParams.Add("id", tt.id)
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, route)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/"+tt.query, nil)
+			w := httptest.NewRecorder()
+			RestHandler{Service: s}.Deliveries(w, req)
+			if w.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package logging
 
 import (
-	"log"
 	"net/http"
 	"time"
+
+	"github.com/artback/mvp/pkg/api/pattern"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/go-chi/chi/middleware"
+	"github.com/sirupsen/logrus"
 )
 
+// RequestIDHeader carries the id of a request, middleware.RequestID takes it from the client or generates it
+const RequestIDHeader = "X-Request-ID"
+
 type statusResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -16,6 +23,13 @@ func (r *statusResponseWriter) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
 }
 
+// Flush lets streaming handlers push what they wrote so far
+func (r *statusResponseWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // newStatusResponseWriter returns pointer to a new statusResponseWriter object.
 func newStatusResponseWriter(w http.ResponseWriter) *statusResponseWriter {
 	return &statusResponseWriter{
@@ -24,21 +38,41 @@ func newStatusResponseWriter(w http.ResponseWriter) *statusResponseWriter {
 	}
 }
 
-func RequestLoggerMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		start := time.Now()
-		sw := newStatusResponseWriter(w)
-		defer func() {
-			log.Printf(
-				"[%s] [%v] [%d] %s %s %s",
-				req.Method,
-				time.Since(start),
-				sw.statusCode,
-				req.Host,
-				req.URL.Path,
-				req.URL.RawQuery,
-			)
-		}()
-		next.ServeHTTP(sw, req)
-	})
+// RequestLogger puts a logger with the request id in the context of every request and logs the request once it is answered.
+// It has to run after middleware.RequestID, the authentication adds the user to the logger later on.
+func RequestLogger(l *logrus.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			id := middleware.GetReqID(req.Context())
+			w.Header().Set(RequestIDHeader, id)
+			ctx := logger.NewContext(req.Context(), l.WithFields(logrus.Fields{
+				"request_id": id,
+				"method":     req.Method,
+				"path":       req.URL.Path,
+			}))
+			req = req.WithContext(ctx)
+
+			sw := newStatusResponseWriter(w)
+			defer func() {
+				From(req).WithFields(logrus.Fields{
+					"status":      sw.statusCode,
+					"duration_ms": time.Since(start).Milliseconds(),
+					"host":        req.Host,
+					"query":       req.URL.RawQuery,
+				}).Info("request")
+			}()
+			next.ServeHTTP(sw, req)
+		})
+	}
+}
+
+// From returns the logger of r with the route it matched
+func From(r *http.Request) *logrus.Entry {
+	entry := logger.From(r.Context())
+	if route := pattern.Of(r); route != "" {
+		entry = entry.WithField("route", route)
+	}
+
+	return entry
 }
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequestLogger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		requestID string
+		path      string
+		route     string
+		status    float64
+	}{
+		{name: "generated request id", path: "/v1/product/cola", route: "/v1/product/{product_name}", status: http.StatusOK},
+		{name: "request id of the client", requestID: "abc-1", path: "/v1/product/cola", route: "/v1/product/{product_name}", status: http.StatusOK},
+		{name: "unknown path", path: "/wp-login.php", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			l, err := logger.New(&buf, logger.Options{Format: logger.JSON, Level: "info"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID, RequestLogger(l), func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					// like the authentication, which runs inside the logger
+					logger.With(r.Context(), logrus.Fields{"username": "mike"})
+					next.ServeHTTP(w, r)
+				})
+			})
+			router.Get("/v1/product/{product_name}", func(w http.ResponseWriter, r *http.Request) {
+				From(r).Info("handled")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if id == "" || (tt.requestID != "" && id != tt.requestID) {
+				t.Errorf("%s = %q, want %q", RequestIDHeader, id, tt.requestID)
+			}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			var last map[string]interface{}
+			for _, line := range lines {
+				last = map[string]interface{}{}
+				if err := json.Unmarshal([]byte(line), &last); err != nil {
+					t.Fatal(err)
+				}
+				if last["request_id"] != id || last["username"] != "mike" {
+					t.Errorf("line %s lacks request_id %q or username mike", line, id)
+				}
+				if tt.route != "" && last["route"] != tt.route {
+					t.Errorf("line %s has route %v, want %q", line, last["route"], tt.route)
+				}
+			}
+			if last["status"] != tt.status {
+				t.Errorf("request line has status %v, want %v", last["status"], tt.status)
+			}
+		})
+	}
+}
@@ -2,31 +2,114 @@ package security
 
 import (
 	"errors"
-	"log"
+	"fmt"
+	"net"
 	"net/http"
+	"time"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = otel.Tracer("github.com/artback/mvp/pkg/api/middleware/security")
+
 var (
 	WrongPasswordErr = errors.New("password is wrong")
 	MissingHeaderErr = errors.New("missing auth header")
+	// MissingCertificateErr is returned for requests without a verified client certificate
+	MissingCertificateErr = errors.New("missing client certificate")
+	LockedErr             = errors.New("account is locked")
+	ResetRequiredErr      = errors.New("password reset required")
+	ThrottledErr          = errors.New("too many failed login attempts")
+	ForbiddenErr          = errors.New("access denied")
 )
 
+// ErrorWriter renders the errors of the middlewares, it keeps this package free of a response format
+type ErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+
+// RetryError is returned while a username or client is throttled, Err is LockedErr or ThrottledErr
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (r RetryError) Error() string {
+	return fmt.Sprintf("%v, retry in %v", r.Err, r.RetryAfter.Round(time.Second))
+}
+
+func (r RetryError) Unwrap() error {
+	return r.Err
+}
+
+// ClientIP is the address of the connection, forwarding headers are not trusted
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
 // Auth interface is for allowing extension of other authentication protocols
 type Auth interface {
 	GetUser(r *http.Request) (*User, error)
 }
 
-func Authenticate(a Auth) func(next http.Handler) http.Handler {
+// Chain tries each Auth in order, the first one finding credentials in the request decides.
+// A request without any credentials is missing its header.
+type Chain []Auth
+
+func (c Chain) GetUser(r *http.Request) (*User, error) {
+	for _, a := range c {
+		user, err := a.GetUser(r)
+		if errors.Is(err, MissingHeaderErr) || errors.Is(err, MissingCertificateErr) {
+			continue
+		}
+		return user, err
+	}
+
+	return nil, MissingHeaderErr
+}
+
+// Identify returns the user of r, requests without valid credentials are anonymous.
+// The only errors are LockedErr and ThrottledErr, they refuse the request.
+// The user is added to the logger of the request, so the following lines tell who made it.
+func Identify(a Auth, r *http.Request) (User, error) {
+	ctx, span := tracer.Start(r.Context(), "security.Authenticate")
+	defer span.End()
+
+	user, err := a.GetUser(r.WithContext(ctx))
+	switch {
+	case errors.Is(err, LockedErr), errors.Is(err, ThrottledErr):
+		logger.From(ctx).WithError(err).Info("login refused")
+		return User{}, err
+	case err != nil:
+		if !errors.Is(err, MissingHeaderErr) {
+			logger.From(ctx).WithError(err).Debug("authentication failed, continuing as anonymous")
+		}
+		span.SetAttributes(attribute.String("enduser.role", string(Anonymous)))
+		logger.With(ctx, logrus.Fields{"role": Anonymous})
+		return User{Role: Anonymous}, nil
+	}
+	span.SetAttributes(attribute.String("enduser.id", user.Username), attribute.String("enduser.role", string(user.Role)))
+	logger.With(ctx, logrus.Fields{"username": user.Username, "role": user.Role})
+
+	return *user, nil
+}
+
+// Authenticate puts the user of a request in its context, requests without valid credentials continue as anonymous
+func Authenticate(a Auth, writeError ErrorWriter) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			user, err := a.GetUser(r)
-			log.Println(err)
+			user, err := Identify(a, r)
 			if err != nil {
-				user = &User{
-					Role: Anonymous,
-				}
+				writeError(w, r, err)
+				return
 			}
-			r = r.WithContext(WithUser(r.Context(), *user))
+			r = r.WithContext(WithUser(r.Context(), user))
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -0,0 +1,105 @@
+package security_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+)
+
+type authFunc func(r *http.Request) (*security.User, error)
+
+func (f authFunc) GetUser(r *http.Request) (*security.User, error) {
+	return f(r)
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		want       int
+		retryAfter string
+	}{
+		{name: "authenticated", want: http.StatusOK},
+		{name: "wrong password is anonymous", err: security.WrongPasswordErr, want: http.StatusOK},
+		{name: "locked account", err: security.LockedErr, want: http.StatusLocked},
+		{name: "temporary lockout", err: security.RetryError{Err: security.LockedErr, RetryAfter: 90 * time.Second}, want: http.StatusLocked, retryAfter: "90"},
+		{name: "throttled", err: security.RetryError{Err: security.ThrottledErr, RetryAfter: 1500 * time.Millisecond}, want: http.StatusTooManyRequests, retryAfter: "2"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			auth := authFunc(func(r *http.Request) (*security.User, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &security.User{Username: "mike", Role: security.Buyer}, nil
+			})
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			w := httptest.NewRecorder()
+			security.Authenticate(auth, problem.Write)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.want {
+				t.Errorf("Authenticate() status = %v, want %v", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Authenticate() Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestChain_GetUser(t *testing.T) {
+	t.Parallel()
+
+	machine := &security.User{Username: "importer", Role: security.Seller}
+	tests := []struct {
+		name    string
+		basic   error
+		cert    error
+		want    *security.User
+		wantErr error
+	}{
+		{name: "basic decides", want: &security.User{Username: "mike", Role: security.Buyer}},
+		{name: "wrong password isn't tried further", basic: security.WrongPasswordErr, wantErr: security.WrongPasswordErr},
+		{name: "certificate without header", basic: security.MissingHeaderErr, want: machine},
+		{name: "no credentials", basic: security.MissingHeaderErr, cert: security.MissingCertificateErr, wantErr: security.MissingHeaderErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			chain := security.Chain{
+				authFunc(func(r *http.Request) (*security.User, error) {
+					if tt.basic != nil {
+						return nil, tt.basic
+					}
+					return &security.User{Username: "mike", Role: security.Buyer}, nil
+				}),
+				authFunc(func(r *http.Request) (*security.User, error) {
+					if tt.cert != nil {
+						return nil, tt.cert
+					}
+					return machine, nil
+				}),
+			}
+
+			got, err := chain.GetUser(httptest.NewRequest(http.MethodGet, "/", nil))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
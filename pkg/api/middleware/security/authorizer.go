@@ -2,27 +2,64 @@ package security
 
 import (
 	"fmt"
-	"github.com/casbin/casbin/v2"
 	"net/http"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/artback/mvp/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func Authorize(e *casbin.Enforcer) func(next http.Handler) http.Handler {
+// Enforcer decides a request, implemented by the casbin enforcers
+type Enforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// isAccountUpdate tells if r may carry a new password, the user service refuses account updates without one
+func isAccountUpdate(r *http.Request) bool {
+	return (r.Method == http.MethodPatch || r.Method == http.MethodPut) && r.URL.Path == "/v1/user"
+}
+
+// Check decides whether the user in the context of r may make r, a refusal wraps ForbiddenErr or ResetRequiredErr
+func Check(e Enforcer, owners Owners, r *http.Request) (err error) {
+	ctx, span := tracer.Start(r.Context(), "security.Authorize")
+	defer func() { tracing.End(span, err) }()
+	r = r.WithContext(ctx)
+
+	user := GetUser(ctx)
+	// A user with a forced password reset may only change its password, through the account update
+	if user.ResetRequired && !isAccountUpdate(r) {
+		return ResetRequiredErr
+	}
+	role := user.Role
+	method := r.Method
+	path := r.URL.Path
+	owner, err := owners.Owner(r)
+	if err != nil {
+		return fmt.Errorf("owner of %s: %w", r.URL.Path, err)
+	}
+	_, enforce := tracer.Start(ctx, "casbin.Enforce")
+	ok, err := e.Enforce(Subject{Name: user.Username, Role: string(role)}, Object{Path: path, Owner: owner}, method)
+	enforce.End()
+	logger.From(ctx).WithField("allowed", ok).Debug("authorization decided")
+	span.SetAttributes(attribute.Bool("authz.allowed", ok))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ForbiddenErr, err)
+	}
+	if !ok {
+		return ForbiddenErr
+	}
+
+	return nil
+}
+
+func Authorize(e Enforcer, owners Owners, writeError ErrorWriter) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			role := GetUser(r.Context()).Role
-			method := r.Method
-			path := r.URL.Path
-			ok, err := e.Enforce(string(role), path, method)
-			fmt.Println(ok, role, path, path)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusForbidden)
+			if err := Check(e, owners, r); err != nil {
+				writeError(w, r, err)
 				return
 			}
-			if ok {
-				next.ServeHTTP(w, r)
-			} else {
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-			}
+			next.ServeHTTP(w, r)
 		}
 
 		return http.HandlerFunc(fn)
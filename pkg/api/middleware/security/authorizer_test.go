@@ -0,0 +1,96 @@
+package security_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/casbin/casbin/v2"
+)
+
+// testOwners mirrors the owner routes of the api, products named "mine" belong to mike
+var testOwners = security.OwnerRoutes{
+	{Pattern: regexp.MustCompile(`^/v1/user/?$`), Owner: security.Self},
+	{Method: http.MethodPost, Pattern: regexp.MustCompile(`^/v1/user/role$`), Owner: security.Self},
+	{Pattern: regexp.MustCompile(`^/v1/user/([^/]+)$`), Owner: func(_ context.Context, key string) (string, error) {
+		return key, nil
+	}},
+	{Pattern: regexp.MustCompile(`^/v1/product/([^/]+)$`), Owner: mineOwner},
+	{Pattern: regexp.MustCompile(`^/v1/product/([^/]+)/threshold$`), Owner: mineOwner},
+	{Pattern: regexp.MustCompile(`^/v1/(deposit|reset|buy/[^/]+)$`), Owner: security.Self},
+}
+
+func mineOwner(_ context.Context, key string) (string, error) {
+	if key == "mine" {
+		return "mike", nil
+	}
+	return "", nil
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	e, err := casbin.NewEnforcer("../../../../config/rbac_model.conf", "../../../../config/auth_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		user   security.User
+		method string
+		path   string
+		want   int
+	}{
+		{name: "anonymous creates user", user: security.User{Role: security.Anonymous}, method: http.MethodPost, path: "/v1/user", want: http.StatusOK},
+		{name: "anonymous reads user", user: security.User{Role: security.Anonymous}, method: http.MethodGet, path: "/v1/user/mike", want: http.StatusForbidden},
+		{name: "anonymous confirms password reset", user: security.User{Role: security.Anonymous}, method: http.MethodPost, path: "/v1/user/password-reset/confirm", want: http.StatusOK},
+		{name: "buyer reads itself", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodGet, path: "/v1/user/mike", want: http.StatusOK},
+		{name: "buyer reads other user", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodGet, path: "/v1/user/sven", want: http.StatusForbidden},
+		{name: "buyer changes own role", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodPost, path: "/v1/user/role", want: http.StatusOK},
+		{name: "buyer reads account named role", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodGet, path: "/v1/user/role", want: http.StatusForbidden},
+		{name: "buyer updates itself", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodPut, path: "/v1/user", want: http.StatusOK},
+		{name: "buyer deposits", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodPut, path: "/v1/deposit", want: http.StatusOK},
+		{name: "seller deposits", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodPut, path: "/v1/deposit", want: http.StatusForbidden},
+		{name: "seller updates own product", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodPut, path: "/v1/product/mine", want: http.StatusOK},
+		{name: "seller updates other product", user: security.User{Username: "sven", Role: security.Seller}, method: http.MethodPut, path: "/v1/product/mine", want: http.StatusForbidden},
+		{name: "seller deletes missing product", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodDelete, path: "/v1/product/missing", want: http.StatusForbidden},
+		{name: "seller creates product", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodPost, path: "/v1/product", want: http.StatusOK},
+		{name: "seller streams events", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodGet, path: "/v1/events", want: http.StatusOK},
+		{name: "anonymous streams events", user: security.User{Role: security.Anonymous}, method: http.MethodGet, path: "/v1/events", want: http.StatusForbidden},
+		{name: "seller lists webhook deliveries", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodGet, path: "/v1/webhooks/1/deliveries", want: http.StatusOK},
+		{name: "buyer registers webhook", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodPost, path: "/v1/webhooks", want: http.StatusForbidden},
+		{name: "seller sets threshold of own product", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodPut, path: "/v1/product/mine/threshold", want: http.StatusOK},
+		{name: "seller sets threshold of other product", user: security.User{Username: "sven", Role: security.Seller}, method: http.MethodPut, path: "/v1/product/mine/threshold", want: http.StatusForbidden},
+		{name: "seller acknowledges alert", user: security.User{Username: "mike", Role: security.Seller}, method: http.MethodPost, path: "/v1/alerts/1/acknowledge", want: http.StatusOK},
+		{name: "buyer lists alerts", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodGet, path: "/v1/alerts", want: http.StatusForbidden},
+		{name: "anonymous probes readiness", user: security.User{Role: security.Anonymous}, method: http.MethodGet, path: "/readyz", want: http.StatusOK},
+		{name: "admin probes liveness", user: security.User{Username: "mike", Role: security.Admin}, method: http.MethodGet, path: "/healthz", want: http.StatusOK},
+		{name: "admin reads other user", user: security.User{Username: "root", Role: security.Admin}, method: http.MethodGet, path: "/v1/user/mike", want: http.StatusOK},
+		{name: "buyer uses admin api", user: security.User{Username: "mike", Role: security.Buyer}, method: http.MethodGet, path: "/v1/admin/users", want: http.StatusForbidden},
+		{name: "reset required blocks", user: security.User{Username: "mike", Role: security.Buyer, ResetRequired: true}, method: http.MethodGet, path: "/v1/deposit", want: http.StatusForbidden},
+		{name: "reset required may update", user: security.User{Username: "mike", Role: security.Buyer, ResetRequired: true}, method: http.MethodPut, path: "/v1/user", want: http.StatusOK},
+		{name: "reset required may patch", user: security.User{Username: "mike", Role: security.Buyer, ResetRequired: true}, method: http.MethodPatch, path: "/v1/user", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			req, _ := http.NewRequestWithContext(security.WithUser(context.Background(), tt.user), tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			security.Authorize(e, testOwners, problem.Write)(next).ServeHTTP(w, req)
+			if status := w.Code; status != tt.want {
+				t.Errorf("Authorize() returned wrong status code: got %v want %v", status, tt.want)
+			}
+			if got := w.Header().Get("Content-Type"); tt.want != http.StatusOK && got != problem.ContentType {
+				t.Errorf("Authorize() Content-Type = %q, want %q", got, problem.ContentType)
+			}
+		})
+	}
+}
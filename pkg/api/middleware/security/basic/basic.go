@@ -1,14 +1,30 @@
 package basic
 
 import (
+	"errors"
 	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/logger"
 	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/users"
 	"net/http"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/artback/mvp/pkg/api/middleware/security/basic")
+
+// dummyHash is compared against for unknown usernames, so they take as long to refuse as a wrong password and the
+// answer time doesn't tell which usernames exist. It is an argon2id hash with the default parameters.
+const dummyHash = "$argon2id$v=19$m=19456,t=2,p=1$mfrwjvMh/sEMD0OyDIiyyA$TJBRV/2YJ43IQdlaBdPYJPHxMXodaaXjWDerzJhTae0"
+
 type Basic struct {
 	Service users.Service
+	// Lockout throttles failed logins, nil disables throttling
+	Lockout lockout.Service
+	// Cache skips the lookup for recently verified credentials, nil disables caching
+	Cache *Cache
 }
 
 func (b Basic) GetUser(r *http.Request) (*security.User, error) {
@@ -16,12 +32,72 @@ func (b Basic) GetUser(r *http.Request) (*security.User, error) {
 	if !ok {
 		return nil, security.MissingHeaderErr
 	}
+	var generation Generation
+	if b.Cache != nil {
+		if user, ok := b.Cache.Get(u, p); ok {
+			return user, nil
+		}
+		// an invalidation during the lookup below must keep its result out of the cache
+		generation = b.Cache.Generation(u)
+		defer b.Cache.Release(generation)
+	}
+
+	ip := security.ClientIP(r)
+	var failed bool
+	if b.Lockout != nil {
+		var err error
+		if failed, err = b.Lockout.Check(r.Context(), u, ip); err != nil {
+			return nil, err
+		}
+	}
+
 	user, err := b.Service.Get(r.Context(), u)
+	if errors.As(err, &repository.EmptyError{}) {
+		compare(r, dummyHash, p)
+		// Unknown usernames only count against the client, so guessing names doesn't fill the attempt table
+		return nil, b.fail(r, "", ip, err)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if !pass.Compare(user.Password, p) {
-		return nil, security.WrongPasswordErr
+	if !compare(r, user.Password, p) {
+		return nil, b.fail(r, u, ip, security.WrongPasswordErr)
+	}
+	if user.Locked {
+		return nil, security.LockedErr
+	}
+	if err := b.Service.RehashPassword(r.Context(), *user, p); err != nil {
+		// The old hash still works, the upgrade is retried on the next login
+		logger.From(r.Context()).WithError(err).Warn("rehash password")
 	}
-	return &security.User{Username: user.Username, Role: user.Role}, nil
+	if failed {
+		if err := b.Lockout.Reset(r.Context(), u); err != nil {
+			return nil, err
+		}
+	}
+	verified := security.User{Username: user.Username, Role: user.Role, ResetRequired: user.ResetRequired}
+	if b.Cache != nil {
+		b.Cache.Add(p, verified, generation)
+	}
+	return &verified, nil
+}
+
+// compare is pass.Compare, hashing is slow on purpose so its own span tells it apart from the lookups
+func compare(r *http.Request, hash, password string) bool {
+	_, span := tracer.Start(r.Context(), "pass.Compare")
+	defer span.End()
+
+	return pass.Compare(hash, password)
+}
+
+// fail records the failed attempt and returns cause, unless recording failed
+func (b Basic) fail(r *http.Request, username, ip string, cause error) error {
+	if b.Lockout == nil {
+		return cause
+	}
+	if err := b.Lockout.Fail(r.Context(), username, ip); err != nil {
+		return err
+	}
+
+	return cause
 }
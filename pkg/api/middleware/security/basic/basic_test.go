@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/artback/mvp/mocks"
 	"github.com/artback/mvp/pkg/users"
@@ -20,6 +21,18 @@ type ServiceResponse struct {
 	err   error
 }
 
+func TestDummyHash(t *testing.T) {
+	t.Parallel()
+
+	// an unknown username costs a compare with the default parameters, like most stored hashes
+	if !pass.DefaultArgon2id.Current(dummyHash) {
+		t.Errorf("dummyHash isn't a hash with the default argon2id parameters")
+	}
+	if pass.Compare(dummyHash, "") {
+		t.Errorf("dummyHash matches an empty password")
+	}
+}
+
 func TestBasic_GetUser(t *testing.T) {
 	t.Parallel()
 	type fields struct {
@@ -59,6 +72,28 @@ func TestBasic_GetUser(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unsuccessful authorization locked account",
+			args: args{
+				User: &users.User{Username: "mike", Password: "password"},
+			},
+			ServiceResponse: ServiceResponse{
+				user:  &users.User{Username: "mike", Password: "password", Role: security.Seller, Locked: true},
+				times: 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "successful authorization reset required",
+			args: args{
+				User: &users.User{Username: "mike", Password: "password"},
+			},
+			ServiceResponse: ServiceResponse{
+				user:  &users.User{Username: "mike", Password: "password", Role: security.Buyer, ResetRequired: true},
+				times: 1,
+			},
+			want: &security.User{Username: "mike", Role: security.Buyer, ResetRequired: true},
+		},
 		{
 			name: "unsuccessful authorization error service",
 			ServiceResponse: ServiceResponse{
@@ -101,6 +136,11 @@ func TestBasic_GetUser(t *testing.T) {
 				tt.ServiceResponse.user.Password, _ = pass.HashAndSalt(tt.ServiceResponse.user.Password)
 			}
 			s.EXPECT().Get(gomock.Any(), gomock.Any()).Return(tt.ServiceResponse.user, tt.ServiceResponse.err).Times(tt.ServiceResponse.times)
+			verified := 0
+			if tt.want != nil {
+				verified = 1
+			}
+			s.EXPECT().RehashPassword(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(verified)
 			b := Basic{
 				Service: s,
 			}
@@ -119,3 +159,105 @@ func TestBasic_GetUser(t *testing.T) {
 		})
 	}
 }
+
+func TestBasic_GetUser_Lockout(t *testing.T) {
+	t.Parallel()
+
+	throttled := security.RetryError{Err: security.ThrottledErr, RetryAfter: time.Second}
+
+	tests := []struct {
+		name       string
+		password   string
+		checkErr   error
+		failed     bool
+		getTimes   int
+		getErr     error
+		failUser   string
+		failTimes  int
+		resetTimes int
+		wantErr    error
+	}{
+		{name: "successful without failures", password: "password", getTimes: 1},
+		{name: "successful resets failures", password: "password", failed: true, getTimes: 1, resetTimes: 1},
+		{name: "throttled skips lookup", password: "password", checkErr: throttled, wantErr: security.ThrottledErr},
+		{name: "wrong password counts failure", password: "pass", getTimes: 1, failUser: "mike", failTimes: 1, wantErr: security.WrongPasswordErr},
+		{name: "unknown username counts against client", password: "password", getTimes: 1, getErr: repository.EmptyError{}, failTimes: 1, wantErr: repository.EmptyError{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			hash, _ := pass.HashAndSalt("password")
+			var user *users.User
+			if tt.getErr == nil {
+				user = &users.User{Username: "mike", Password: hash, Role: security.Buyer}
+			}
+			s := mocks.NewUserService(mockCtrl)
+			s.EXPECT().Get(gomock.Any(), "mike").Return(user, tt.getErr).Times(tt.getTimes)
+			verified := 0
+			if tt.wantErr == nil {
+				verified = 1
+			}
+			s.EXPECT().RehashPassword(gomock.Any(), gomock.Any(), "password").Return(nil).Times(verified)
+			l := mocks.NewLockoutService(mockCtrl)
+			l.EXPECT().Check(gomock.Any(), "mike", "10.0.0.1").Return(tt.failed, tt.checkErr)
+			l.EXPECT().Fail(gomock.Any(), tt.failUser, "10.0.0.1").Return(nil).Times(tt.failTimes)
+			l.EXPECT().Reset(gomock.Any(), "mike").Return(nil).Times(tt.resetTimes)
+			b := Basic{Service: s, Lockout: l}
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:52000"
+			req.SetBasicAuth("mike", tt.password)
+			_, err := b.GetUser(req)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBasic_GetUser_Cache(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	hash, _ := pass.HashAndSalt("password")
+	s := mocks.NewUserService(mockCtrl)
+	// only the first request reaches the service
+	s.EXPECT().Get(gomock.Any(), "mike").Return(&users.User{Username: "mike", Password: hash, Role: security.Buyer}, nil).Times(1)
+	s.EXPECT().RehashPassword(gomock.Any(), gomock.Any(), "password").Return(nil).Times(1)
+	cache, _ := NewCache(10, time.Minute)
+	b := Basic{Service: s, Cache: cache}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth("mike", "password")
+		got, err := b.GetUser(req)
+		if err != nil {
+			t.Fatalf("GetUser() error = %v", err)
+		}
+		if want := (&security.User{Username: "mike", Role: security.Buyer}); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetUser() got = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBasic_GetUser_Rehash(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	hash, _ := pass.HashAndSalt("password")
+	user := &users.User{Username: "mike", Password: hash, Role: security.Buyer}
+	s := mocks.NewUserService(mockCtrl)
+	s.EXPECT().Get(gomock.Any(), "mike").Return(user, nil)
+	s.EXPECT().RehashPassword(gomock.Any(), *user, "password").Return(errors.New("something happened"))
+	b := Basic{Service: s}
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("mike", "password")
+	// a failed upgrade doesn't fail the login
+	if _, err := b.GetUser(req); err != nil {
+		t.Errorf("GetUser() error = %v", err)
+	}
+}
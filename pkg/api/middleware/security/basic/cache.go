@@ -0,0 +1,245 @@
+package basic
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key     cacheKey
+	user    security.User
+	expires time.Time
+}
+
+// CacheStats are the counters of a Cache since it was created
+type CacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+}
+
+// Generation is the number of times a user was invalidated and the cache purged, taken before a lookup.
+// Add refuses the lookup's result once either moved on, the user may have changed in the meantime.
+type Generation struct {
+	username      string
+	purges        uint64
+	invalidations uint64
+}
+
+// lookups are the running lookups of a user and the invalidations since the first of them started
+type lookups struct {
+	running       int
+	invalidations uint64
+}
+
+// Cache remembers verified credentials for a short time, so repeated requests skip the user lookup and password hash.
+// Credentials are only kept as a keyed hash, the key never leaves the process.
+// Entries are dropped after ttl, the least recently used entry makes room once size is reached.
+type Cache struct {
+	size   int
+	ttl    time.Duration
+	secret []byte
+	// Now defaults to time.Now
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	byUser  map[string]map[cacheKey]struct{}
+	// recent holds the entries, most recently used in front
+	recent *list.List
+	stats  CacheStats
+	// purges counts the Purge calls, running holds the users with a lookup between Generation and Release.
+	// Invalidations only matter to running lookups, so a user leaves running with its last one.
+	purges  uint64
+	running map[string]*lookups
+}
+
+func NewCache(size int, ttl time.Duration) (*Cache, error) {
+	secret := make([]byte, sha256.Size)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		secret:  secret,
+		entries: make(map[cacheKey]*list.Element),
+		byUser:  make(map[string]map[cacheKey]struct{}),
+		recent:  list.New(),
+		running: make(map[string]*lookups),
+	}, nil
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+func (c *Cache) key(username, password string) cacheKey {
+	mac := hmac.New(sha256.New, c.secret)
+	// The length prefix keeps "ab"+"c" and "a"+"bc" apart
+	_ = binary.Write(mac, binary.BigEndian, uint64(len(username)))
+	mac.Write([]byte(username))
+	mac.Write([]byte(password))
+
+	var key cacheKey
+	copy(key[:], mac.Sum(nil))
+
+	return key
+}
+
+// Get returns the user verified with exactly these credentials, if it hasn't expired
+func (c *Cache) Get(username, password string) (*security.User, bool) {
+	key := c.key(username, password)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok && c.now().Before(elem.Value.(*cacheEntry).expires) {
+		c.stats.Hits++
+		c.recent.MoveToFront(elem)
+		user := elem.Value.(*cacheEntry).user
+
+		return &user, true
+	}
+	if ok {
+		c.remove(elem)
+	}
+	c.stats.Misses++
+
+	return nil, false
+}
+
+// Generation returns the current generation of username, take it before looking the user up
+// and Release it once the lookup is done
+func (c *Cache) Generation(username string) Generation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.running[username]
+	if !ok {
+		l = &lookups{}
+		c.running[username] = l
+	}
+	l.running++
+
+	return Generation{username: username, purges: c.purges, invalidations: l.invalidations}
+}
+
+// Release ends the lookup generation was taken for, Add has to be called before
+func (c *Cache) Release(generation Generation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.running[generation.username]
+	if !ok {
+		return
+	}
+	if l.running--; l.running == 0 {
+		delete(c.running, generation.username)
+	}
+}
+
+// current is the generation of a running lookup of username
+func (c *Cache) current(username string) Generation {
+	var invalidations uint64
+	if l, ok := c.running[username]; ok {
+		invalidations = l.invalidations
+	}
+
+	return Generation{username: username, purges: c.purges, invalidations: invalidations}
+}
+
+// Add remembers that password was verified for user, unless user was invalidated after generation was taken
+func (c *Cache) Add(password string, user security.User, generation Generation) {
+	if c.size <= 0 || c.ttl <= 0 {
+		return
+	}
+	key := c.key(user.Username, password)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.current(user.Username) {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	for c.recent.Len() >= c.size {
+		c.remove(c.recent.Back())
+		c.stats.Evictions++
+	}
+
+	c.entries[key] = c.recent.PushFront(&cacheEntry{key: key, user: user, expires: c.now().Add(c.ttl)})
+	if c.byUser[user.Username] == nil {
+		c.byUser[user.Username] = make(map[cacheKey]struct{})
+	}
+	c.byUser[user.Username][key] = struct{}{}
+}
+
+// Invalidate forgets every entry of username, used after its password, role or account changed
+func (c *Cache) Invalidate(username string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l, ok := c.running[username]; ok {
+		l.invalidations++
+	}
+	for key := range c.byUser[username] {
+		c.remove(c.entries[key])
+	}
+}
+
+// Purge forgets every entry
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[cacheKey]*list.Element)
+	c.byUser = make(map[string]map[cacheKey]struct{})
+	c.recent.Init()
+	c.purges++
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	entry := c.recent.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+
+	keys := c.byUser[entry.user.Username]
+	delete(keys, entry.key)
+	if len(keys) == 0 {
+		delete(c.byUser, entry.user.Username)
+	}
+}
+
+// Stats returns a snapshot of the counters, it matches expvar.Func
+func (c *Cache) Stats() interface{} {
+	return c.Snapshot()
+}
+
+// Snapshot returns the counters
+func (c *Cache) Snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.recent.Len()
+
+	return stats
+}
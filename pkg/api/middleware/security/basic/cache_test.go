@@ -0,0 +1,196 @@
+package basic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+// add adds user right after it was looked up
+func add(c *Cache, password string, user security.User) {
+	generation := c.Generation(user.Username)
+	c.Add(password, user, generation)
+	c.Release(generation)
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	mike := security.User{Username: "mike", Role: security.Buyer}
+	anna := security.User{Username: "anna", Role: security.Seller}
+
+	tests := []struct {
+		name     string
+		size     int
+		fill     func(c *Cache)
+		elapsed  time.Duration
+		username string
+		password string
+		want     *security.User
+		stats    CacheStats
+	}{
+		{
+			name:     "hit",
+			size:     2,
+			fill:     func(c *Cache) { add(c, "secret", mike) },
+			username: "mike", password: "secret",
+			want:  &mike,
+			stats: CacheStats{Hits: 1, Size: 1},
+		},
+		{
+			name:     "wrong password misses",
+			size:     2,
+			fill:     func(c *Cache) { add(c, "secret", mike) },
+			username: "mike", password: "guess",
+			stats: CacheStats{Misses: 1, Size: 1},
+		},
+		{
+			name:     "expired",
+			size:     2,
+			fill:     func(c *Cache) { add(c, "secret", mike) },
+			elapsed:  time.Minute,
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1},
+		},
+		{
+			name: "invalidated",
+			size: 2,
+			fill: func(c *Cache) {
+				add(c, "secret", mike)
+				add(c, "other", anna)
+				c.Invalidate("mike")
+			},
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1, Size: 1},
+		},
+		{
+			name: "invalidated during the lookup",
+			size: 2,
+			fill: func(c *Cache) {
+				generation := c.Generation("mike")
+				c.Invalidate("mike")
+				c.Add("secret", mike, generation)
+			},
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1},
+		},
+		{
+			name: "invalidated while an earlier lookup ran",
+			size: 2,
+			fill: func(c *Cache) {
+				earlier := c.Generation("mike")
+				generation := c.Generation("mike")
+				c.Invalidate("mike")
+				c.Release(earlier)
+				c.Add("secret", mike, generation)
+			},
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1},
+		},
+		{
+			name: "invalidated before the lookup",
+			size: 2,
+			fill: func(c *Cache) {
+				c.Invalidate("mike")
+				add(c, "secret", mike)
+			},
+			username: "mike", password: "secret",
+			want:  &mike,
+			stats: CacheStats{Hits: 1, Size: 1},
+		},
+		{
+			name: "other user invalidated during the lookup",
+			size: 2,
+			fill: func(c *Cache) {
+				generation := c.Generation("mike")
+				c.Invalidate("anna")
+				c.Add("secret", mike, generation)
+			},
+			username: "mike", password: "secret",
+			want:  &mike,
+			stats: CacheStats{Hits: 1, Size: 1},
+		},
+		{
+			name: "purged during the lookup",
+			size: 2,
+			fill: func(c *Cache) {
+				generation := c.Generation("mike")
+				c.Purge()
+				c.Add("secret", mike, generation)
+			},
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1},
+		},
+		{
+			name: "least recently used evicted",
+			size: 2,
+			fill: func(c *Cache) {
+				add(c, "secret", mike)
+				add(c, "other", anna)
+				c.Get("mike", "secret")
+				add(c, "third", security.User{Username: "john"})
+			},
+			username: "anna", password: "other",
+			stats: CacheStats{Hits: 1, Misses: 1, Evictions: 1, Size: 2},
+		},
+		{
+			name: "purged",
+			size: 2,
+			fill: func(c *Cache) {
+				add(c, "secret", mike)
+				c.Purge()
+				add(c, "other", anna)
+			},
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1, Size: 1},
+		},
+		{
+			name:     "disabled",
+			fill:     func(c *Cache) { add(c, "secret", mike) },
+			username: "mike", password: "secret",
+			stats: CacheStats{Misses: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			now := now
+			c, err := NewCache(tt.size, 30*time.Second)
+			if err != nil {
+				t.Fatalf("NewCache() error = %v", err)
+			}
+			c.Now = func() time.Time { return now }
+			tt.fill(c)
+			now = now.Add(tt.elapsed)
+			got, _ := c.Get(tt.username, tt.password)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+			if stats := c.Stats(); stats != tt.stats {
+				t.Errorf("Stats() = %+v, want %+v", stats, tt.stats)
+			}
+		})
+	}
+}
+
+func TestCache_Release(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCache(2, 30*time.Second)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	for _, username := range []string{"mike", "anna", "john"} {
+		generation := c.Generation(username)
+		c.Invalidate(username)
+		c.Release(generation)
+	}
+	if len(c.running) != 0 {
+		t.Errorf("Release() kept %d users, want none once their lookups are done", len(c.running))
+	}
+}
@@ -0,0 +1,33 @@
+package cert
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+var (
+	NoNameErr = errors.New("client certificate has no common name")
+	NoRoleErr = errors.New("client certificate names no role")
+)
+
+// Cert identifies machines by the client certificate verified in the TLS handshake. The common name of the
+// certificate is the username and its first organizational unit the role, so the CA decides what a machine may do.
+// Certificates are only verified when the server has client CAs, see tlsconfig.Options.ClientCAFile.
+type Cert struct{}
+
+func (Cert) GetUser(r *http.Request) (*security.User, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, security.MissingCertificateErr
+	}
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, NoNameErr
+	}
+	if len(subject.OrganizationalUnit) == 0 || subject.OrganizationalUnit[0] == "" {
+		return nil, NoRoleErr
+	}
+
+	return &security.User{Username: subject.CommonName, Role: security.Role(subject.OrganizationalUnit[0])}, nil
+}
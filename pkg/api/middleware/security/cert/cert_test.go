@@ -0,0 +1,56 @@
+package cert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+func TestCert_GetUser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		state   *tls.ConnectionState
+		want    *security.User
+		wantErr error
+	}{
+		{name: "plain text", wantErr: security.MissingCertificateErr},
+		{name: "no certificate", state: &tls.ConnectionState{}, wantErr: security.MissingCertificateErr},
+		{name: "machine", state: verified(pkix.Name{CommonName: "importer", OrganizationalUnit: []string{"seller"}}),
+			want: &security.User{Username: "importer", Role: security.Seller}},
+		{name: "no role", state: verified(pkix.Name{CommonName: "importer"}), wantErr: NoRoleErr},
+		{name: "no name", state: verified(pkix.Name{OrganizationalUnit: []string{"seller"}}), wantErr: NoNameErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.TLS = tt.state
+
+			got, err := Cert{}.GetUser(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// verified is a connection whose client presented a certificate for subject that the server verified
+func verified(subject pkix.Name) *tls.ConnectionState {
+	leaf := &x509.Certificate{Subject: subject}
+
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}, VerifiedChains: [][]*x509.Certificate{{leaf}}}
+}
@@ -0,0 +1,65 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+// Subject is the principal handed to the enforcer as r.sub
+type Subject struct {
+	Name string
+	Role string
+}
+
+// Object is the resource handed to the enforcer as r.obj, Owner is empty when the resource has none or doesn't exist
+type Object struct {
+	Path  string
+	Owner string
+}
+
+// Owners resolves the owner of the resource a request targets
+type Owners interface {
+	Owner(r *http.Request) (string, error)
+}
+
+// OwnerFunc loads the owner of the resource identified by key
+type OwnerFunc func(ctx context.Context, key string) (string, error)
+
+// OwnerRoute resolves the owner for paths matching Pattern, the first submatch is passed to Owner as key.
+// Method limits the route to requests of that method, an empty Method matches all of them.
+type OwnerRoute struct {
+	Method  string
+	Pattern *regexp.Regexp
+	Owner   OwnerFunc
+}
+
+type OwnerRoutes []OwnerRoute
+
+// Owner uses the first route matching the path, requests matching no route target a resource without owner
+func (o OwnerRoutes) Owner(r *http.Request) (string, error) {
+	for _, route := range o {
+		if route.Method != "" && route.Method != r.Method {
+			continue
+		}
+
+		match := route.Pattern.FindStringSubmatch(r.URL.Path)
+		if match == nil {
+			continue
+		}
+
+		var key string
+		if len(match) > 1 {
+			key = match[1]
+		}
+
+		return route.Owner(r.Context(), key)
+	}
+
+	return "", nil
+}
+
+// Self is the OwnerFunc for resources that always belong to the authenticated user, like its own account
+func Self(ctx context.Context, _ string) (string, error) {
+	return GetUser(ctx).Username, nil
+}
@@ -5,5 +5,16 @@ type Role string
 const (
 	Buyer     Role = "buyer"
 	Seller    Role = "seller"
+	Admin     Role = "admin"
 	Anonymous Role = "anonymous"
 )
+
+// Valid reports whether r is one of the roles a stored user can have
+func (r Role) Valid() bool {
+	switch r {
+	case Buyer, Seller, Admin:
+		return true
+	default:
+		return false
+	}
+}
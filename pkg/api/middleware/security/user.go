@@ -3,8 +3,10 @@ package security
 import "context"
 
 type User struct {
-	Username string `json:"username" binding:"required"`
-	Role     Role   `json:"role"  binding:"required"`
+	Username string `json:"username"`
+	Role     Role   `json:"role"`
+	// ResetRequired is set when an admin forced the user to choose a new password
+	ResetRequired bool `json:"-"`
 }
 type nameKey string
 
@@ -0,0 +1,26 @@
+package pattern
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Of returns the chi route pattern serving r, e.g. /v1/product/{product_name}, or an empty string when no route does.
+// Requests refused by a middleware never reach the routing, for them the pattern is looked up.
+func Of(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+
+	lookup := chi.NewRouteContext()
+	if rctx.Routes != nil && rctx.Routes.Match(lookup, r.Method, r.URL.Path) {
+		return lookup.RoutePattern()
+	}
+
+	return ""
+}
@@ -0,0 +1,138 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/artback/mvp/pkg/api/middleware/logging"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/go-chi/chi/middleware"
+)
+
+// ContentType is the media type of a problem (RFC 7807)
+const ContentType = "application/problem+json"
+
+var (
+	// MalformedErr is wrapped by the errors of request bodies that can't be decoded
+	MalformedErr = errors.New("malformed request")
+	TooLargeErr  = errors.New("request body is too large")
+	// NotFoundErr and MethodNotAllowedErr answer requests no route serves
+	NotFoundErr         = errors.New("no route serves the path")
+	MethodNotAllowedErr = errors.New("method not allowed")
+	// ReloadFailedErr is wrapped by settings the running service refused to reload
+	ReloadFailedErr = errors.New("reload refused")
+)
+
+// Problem is the body of every error response, Code stays the same for an error while Detail may be reworded
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Extensions are written as additional members of the problem
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON appends the extensions after the standard members
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	body, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	extensions, err := json.Marshal(p.Extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(body[:len(body)-1], ','), extensions[1:]...), nil
+}
+
+// FieldError points at one invalid member of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every invalid member of a request, so a client can fix them all at once
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (v ValidationError) Error() string {
+	messages := make([]string, 0, len(v.Fields))
+	for _, field := range v.Fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+
+	return "invalid request: " + strings.Join(messages, ", ")
+}
+
+// New returns the problem for err, errors without a rule become an internal error that reveals nothing
+func New(err error) Problem {
+	var validation ValidationError
+	if errors.As(err, &validation) {
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusBadRequest),
+			Status: http.StatusBadRequest,
+			Code:   "validation_failed",
+			Detail: "request has invalid fields",
+			Errors: validation.Fields,
+		}
+	}
+
+	r := match(err)
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(r.status),
+		Status: r.status,
+		Code:   r.code,
+		Detail: r.detail,
+	}
+	if p.Detail == "" {
+		p.Detail = err.Error()
+	}
+	if r.field != "" {
+		p.Errors = []FieldError{{Field: r.field, Code: r.code, Message: p.Detail}}
+	}
+	if r.extend != nil {
+		p.Extensions = r.extend(err)
+	}
+
+	return p
+}
+
+// Write renders err as a problem, it is the single place where api errors become responses
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := New(err)
+	p.Instance = r.URL.Path
+	p.RequestID = middleware.GetReqID(r.Context())
+
+	if p.Status >= http.StatusInternalServerError {
+		logging.From(r).WithError(err).Error("request failed")
+	}
+
+	var retry security.RetryError
+	if errors.As(err, &retry) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logging.From(r).WithError(err).Warn("write problem")
+	}
+}
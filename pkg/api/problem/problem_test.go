@@ -0,0 +1,204 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/go-chi/chi/middleware"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+		fields []FieldError
+		extra  map[string]interface{}
+	}{
+		{
+			name:   "malformed body",
+			err:    fmt.Errorf("%w: invalid user form", MalformedErr),
+			status: http.StatusBadRequest,
+			code:   "malformed_request",
+			detail: "malformed request: invalid user form",
+		},
+		{
+			name:   "short password points at the field",
+			err:    fmt.Errorf("insert user: %w", pass.TooShortErr),
+			status: http.StatusBadRequest,
+			code:   "password_too_short",
+			detail: "insert user: password does not meet the policy: too short",
+			fields: []FieldError{{Field: "password", Code: "password_too_short", Message: "insert user: password does not meet the policy: too short"}},
+		},
+		{
+			name:   "wrong current password",
+			err:    users.CurrentPasswordWrongErr,
+			status: http.StatusForbidden,
+			code:   "current_password_wrong",
+			detail: "current password is wrong",
+			fields: []FieldError{{Field: "current_password", Code: "current_password_wrong", Message: "current password is wrong"}},
+		},
+		{
+			name:   "missing reason",
+			err:    admin.MissingReasonErr,
+			status: http.StatusBadRequest,
+			code:   "reason_required",
+			detail: "a reason is required",
+			fields: []FieldError{{Field: "reason", Code: "reason_required", Message: "a reason is required"}},
+		},
+		{
+			name:   "validation error lists every field",
+			err:    ValidationError{Fields: []FieldError{{Field: "price", Code: "required", Message: "is required"}, {Field: "amount", Code: "min", Message: "must be at least 0"}}},
+			status: http.StatusBadRequest,
+			code:   "validation_failed",
+			detail: "request has invalid fields",
+			fields: []FieldError{{Field: "price", Code: "required", Message: "is required"}, {Field: "amount", Code: "min", Message: "must be at least 0"}},
+		},
+		{
+			name:   "throttled login",
+			err:    security.RetryError{Err: security.ThrottledErr, RetryAfter: time.Second},
+			status: http.StatusTooManyRequests,
+			code:   "login_throttled",
+			detail: "too many failed login attempts, retry in 1s",
+		},
+		{
+			name:   "insufficient funds tells the shortfall",
+			err:    vending.InsufficientFundsError{Cost: 25, Deposit: 10},
+			status: http.StatusPaymentRequired,
+			code:   "insufficient_funds",
+			detail: "cost 25 is higher than deposit 10",
+			extra:  map[string]interface{}{"cost": 25, "deposit": 10, "shortfall": 15},
+		},
+		{
+			name:   "out of stock tells what is left",
+			err:    vending.OutOfStockError{Product: "cola", Requested: 3, Available: 1},
+			status: http.StatusConflict,
+			code:   "out_of_stock",
+			detail: "3 of cola requested, 1 available",
+			extra:  map[string]interface{}{"product": "cola", "requested": 3, "available": 1},
+		},
+		{
+			name:   "missing resource",
+			err:    repository.EmptyError{},
+			status: http.StatusNotFound,
+			code:   "not_found",
+			detail: "resource does not exist",
+		},
+		{
+			name:   "duplicate hides the constraint",
+			err:    repository.DuplicateError{Constraint: "users_pkey"},
+			status: http.StatusConflict,
+			code:   "conflict",
+			detail: "resource already exists or is still referenced",
+		},
+		{
+			name:   "store refusal hides the database message",
+			err:    repository.InvalidError{Title: `relation "users" does not exist`},
+			status: http.StatusNotAcceptable,
+			code:   "not_acceptable",
+			detail: "request was refused by the store",
+		},
+		{
+			name:   "unknown error reveals nothing",
+			err:    errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			status: http.StatusInternalServerError,
+			code:   "internal",
+			detail: "an unexpected error occurred",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := New(tt.err)
+			if got.Status != tt.status || got.Code != tt.code || got.Detail != tt.detail {
+				t.Errorf("New() = %v %q %q, want %v %q %q", got.Status, got.Code, got.Detail, tt.status, tt.code, tt.detail)
+			}
+			if got.Title != http.StatusText(tt.status) {
+				t.Errorf("New() title = %q, want %q", got.Title, http.StatusText(tt.status))
+			}
+			if !reflect.DeepEqual(got.Errors, tt.fields) {
+				t.Errorf("New() errors = %v, want %v", got.Errors, tt.fields)
+			}
+			if !reflect.DeepEqual(got.Extensions, tt.extra) {
+				t.Errorf("New() extensions = %v, want %v", got.Extensions, tt.extra)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "host/abc-000001")
+	req := httptest.NewRequest(http.MethodPost, "/v1/user", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	Write(w, req, security.RetryError{Err: security.LockedErr, RetryAfter: 1500 * time.Millisecond})
+
+	if w.Code != http.StatusLocked {
+		t.Errorf("Write() status = %v, want %v", w.Code, http.StatusLocked)
+	}
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Write() Content-Type = %q, want %q", got, ContentType)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Write() Retry-After = %q, want %q", got, "2")
+	}
+
+	var got Problem
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(http.StatusLocked),
+		Status:    http.StatusLocked,
+		Code:      "account_locked",
+		Detail:    "account is locked, retry in 2s",
+		Instance:  "/v1/user",
+		RequestID: "host/abc-000001",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Write() body = %+v, want %+v", got, want)
+	}
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	p := Problem{
+		Type:       "about:blank",
+		Title:      "Payment Required",
+		Status:     http.StatusPaymentRequired,
+		Code:       "insufficient_funds",
+		Extensions: map[string]interface{}{"shortfall": 15},
+	}
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"about:blank","title":"Payment Required","status":402,"code":"insufficient_funds","shortfall":15}`
+	if string(got) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", got, want)
+	}
+}
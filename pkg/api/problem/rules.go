@@ -0,0 +1,109 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/artback/mvp/pkg/webhook"
+)
+
+// rule maps the errors accepted by match to a response, field names the request member at fault.
+// An empty detail uses the error message, a detail is fixed where the message comes from the database.
+// extend adds members a client can act on, like the amount still missing for a purchase.
+type rule struct {
+	match  func(err error) bool
+	status int
+	code   string
+	field  string
+	detail string
+	extend func(err error) map[string]interface{}
+}
+
+func is(target error) func(err error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// rules are tried in order, more specific errors come before the errors they wrap
+var rules = []rule{
+	{match: is(MalformedErr), status: http.StatusBadRequest, code: "malformed_request"},
+	{match: is(TooLargeErr), status: http.StatusRequestEntityTooLarge, code: "request_too_large"},
+	{match: is(NotFoundErr), status: http.StatusNotFound, code: "not_found"},
+	{match: is(MethodNotAllowedErr), status: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+	{match: is(ReloadFailedErr), status: http.StatusUnprocessableEntity, code: "reload_failed"},
+	{match: is(change.InvalidDepositErr), status: http.StatusBadRequest, code: "deposit_invalid"},
+	{match: is(pass.TooShortErr), status: http.StatusBadRequest, code: "password_too_short", field: "password"},
+	{match: is(pass.TooLongErr), status: http.StatusBadRequest, code: "password_too_long", field: "password"},
+	{match: is(pass.BreachedErr), status: http.StatusBadRequest, code: "password_breached", field: "password"},
+	{match: is(pass.SameAsUsernameErr), status: http.StatusBadRequest, code: "password_same_as_username", field: "password"},
+	{match: is(pass.WeakPasswordErr), status: http.StatusBadRequest, code: "password_weak", field: "password"},
+	{match: is(users.InvalidRoleErr), status: http.StatusBadRequest, code: "role_invalid", field: "role"},
+	{match: is(users.CurrentPasswordRequiredErr), status: http.StatusBadRequest, code: "current_password_required", field: "current_password"},
+	{match: is(users.InvalidResetTokenErr), status: http.StatusBadRequest, code: "reset_token_invalid", field: "token"},
+	{match: is(admin.MissingReasonErr), status: http.StatusBadRequest, code: "reason_required", field: "reason"},
+	{match: is(policy.InvalidRuleErr), status: http.StatusBadRequest, code: "policy_rule_invalid"},
+	{match: is(webhook.InvalidURLErr), status: http.StatusBadRequest, code: "webhook_url_invalid", field: "url"},
+	{match: is(webhook.UnknownKindErr), status: http.StatusBadRequest, code: "webhook_event_unknown", field: "events"},
+	{match: is(alert.InvalidThresholdErr), status: http.StatusBadRequest, code: "threshold_invalid", field: "threshold"},
+	{match: is(users.RoleNotAllowedErr), status: http.StatusForbidden, code: "role_not_allowed", field: "role"},
+	{match: is(users.CurrentPasswordWrongErr), status: http.StatusForbidden, code: "current_password_wrong", field: "current_password"},
+	{match: is(admin.SelfActionErr), status: http.StatusForbidden, code: "self_action"},
+	{match: is(security.ResetRequiredErr), status: http.StatusForbidden, code: "password_reset_required"},
+	{match: is(security.ForbiddenErr), status: http.StatusForbidden, code: "forbidden"},
+	{match: is(security.LockedErr), status: http.StatusLocked, code: "account_locked"},
+	{match: is(security.ThrottledErr), status: http.StatusTooManyRequests, code: "login_throttled"},
+	{
+		match:  func(err error) bool { return errors.As(err, &vending.InsufficientFundsError{}) },
+		status: http.StatusPaymentRequired, code: "insufficient_funds",
+		extend: func(err error) map[string]interface{} {
+			e := vending.InsufficientFundsError{}
+			errors.As(err, &e)
+
+			return map[string]interface{}{"cost": e.Cost, "deposit": e.Deposit, "shortfall": e.Shortfall()}
+		},
+	},
+	{
+		match:  func(err error) bool { return errors.As(err, &vending.OutOfStockError{}) },
+		status: http.StatusConflict, code: "out_of_stock",
+		extend: func(err error) map[string]interface{} {
+			e := vending.OutOfStockError{}
+			errors.As(err, &e)
+
+			return map[string]interface{}{"product": e.Product, "requested": e.Requested, "available": e.Available}
+		},
+	},
+	{
+		match:  func(err error) bool { return errors.As(err, &repository.EmptyError{}) },
+		status: http.StatusNotFound, code: "not_found", detail: "resource does not exist",
+	},
+	{
+		match:  func(err error) bool { return errors.As(err, &repository.DuplicateError{}) },
+		status: http.StatusConflict, code: "conflict", detail: "resource already exists or is still referenced",
+	},
+	{
+		match:  func(err error) bool { return errors.As(err, &repository.InvalidError{}) },
+		status: http.StatusNotAcceptable, code: "not_acceptable", detail: "request was refused by the store",
+	},
+}
+
+var internal = rule{status: http.StatusInternalServerError, code: "internal", detail: "an unexpected error occurred"}
+
+func match(err error) rule {
+	for _, r := range rules {
+		if r.match(err) {
+			return r
+		}
+	}
+
+	return internal
+}
@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// InvalidNameErr refuses a name with a slash, it would become a separator of the path the call is authorized as
+var InvalidNameErr = fmt.Errorf("%w: name contains a slash", problem.MalformedErr)
+
+// route is the REST request a call is authorized as, so both apis share one policy.
+// req is nil for streams, their routes can't depend on the request.
+type route func(req interface{}) (method, path string, err error)
+
+func fixed(method, path string) route {
+	return func(interface{}) (string, string, error) {
+		return method, path, nil
+	}
+}
+
+// named is the route of method on prefix followed by the name of a request
+func named(method, prefix string, name func(req interface{}) string) route {
+	return func(req interface{}) (string, string, error) {
+		n := name(req)
+		if strings.Contains(n, "/") {
+			return "", "", InvalidNameErr
+		}
+
+		return method, prefix + n, nil
+	}
+}
+
+var routes = map[string]route{
+	"/mvp.v1.UserService/CreateUser": fixed(http.MethodPost, "/v1/user"),
+	"/mvp.v1.UserService/GetUser": named(http.MethodGet, "/v1/user/", func(req interface{}) string {
+		return req.(*pb.GetUserRequest).GetUsername()
+	}),
+	"/mvp.v1.UserService/DeleteUser": fixed(http.MethodDelete, "/v1/user"),
+	"/mvp.v1.ProductService/GetProduct": named(http.MethodGet, "/v1/product/", func(req interface{}) string {
+		return req.(*pb.GetProductRequest).GetName()
+	}),
+	"/mvp.v1.ProductService/CreateProduct": fixed(http.MethodPost, "/v1/product"),
+	"/mvp.v1.ProductService/UpdateProduct": named(http.MethodPut, "/v1/product/", func(req interface{}) string {
+		return req.(*pb.Product).GetName()
+	}),
+	"/mvp.v1.ProductService/DeleteProduct": named(http.MethodDelete, "/v1/product/", func(req interface{}) string {
+		return req.(*pb.DeleteProductRequest).GetName()
+	}),
+	"/mvp.v1.VendingService/GetAccount": fixed(http.MethodGet, "/v1/deposit"),
+	"/mvp.v1.VendingService/Deposit":    fixed(http.MethodPut, "/v1/deposit"),
+	"/mvp.v1.VendingService/BuyProduct": named(http.MethodPost, "/v1/buy/", func(req interface{}) string {
+		return req.(*pb.BuyProductRequest).GetProductName()
+	}),
+	"/mvp.v1.VendingService/ResetDeposit": fixed(http.MethodDelete, "/v1/reset"),
+	"/mvp.v1.VendingService/GetTransaction": named(http.MethodGet, "/v1/transaction/", func(req interface{}) string {
+		return strconv.FormatInt(req.(*pb.GetTransactionRequest).GetId(), 10)
+	}),
+}
+
+// request translates a call into the REST request it mirrors, carrying the credentials, the client address
+// and the TLS state of the connection with the client certificate
+func request(ctx context.Context, fullMethod string, req interface{}) (*http.Request, error) {
+	route, ok := routes[fullMethod]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no route", security.ForbiddenErr, fullMethod)
+	}
+	method, path, err := route(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// the path is set as is, parsing it would decode escapes into separators
+	r, err := http.NewRequestWithContext(ctx, method, "/", nil)
+	if err != nil {
+		return nil, err
+	}
+	r.URL.Path = path
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, authorization := range md.Get("authorization") {
+			r.Header.Add("Authorization", authorization)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+
+	return r, nil
+}
+
+// authorize puts the user of a call in its context and checks it like security.Authenticate and security.Authorize check REST requests
+func authorize(ctx context.Context, a security.Auth, e security.Enforcer, owners security.Owners, fullMethod string, req interface{}) (context.Context, error) {
+	r, err := request(ctx, fullMethod, req)
+	if err != nil {
+		return ctx, err
+	}
+	user, err := security.Identify(a, r)
+	if err != nil {
+		return ctx, err
+	}
+	ctx = security.WithUser(ctx, user)
+
+	err = security.Check(e, owners, r.WithContext(ctx))
+	if errors.Is(err, security.ForbiddenErr) && user.Role == security.Anonymous {
+		// REST answers 403 for both, gRPC clients expect to be told that credentials are missing
+		return ctx, status.Error(codes.Unauthenticated, "credentials are required")
+	}
+
+	return ctx, err
+}
+
+// UnaryAuth authenticates and authorizes every unary call, refusals are answered with a status
+func UnaryAuth(a security.Auth, e security.Enforcer, owners security.Owners) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, a, e, owners, info.FullMethod, req)
+		if err != nil {
+			return nil, Status(err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth authenticates and authorizes every stream when it is opened
+func StreamAuth(a security.Auth, e security.Enforcer, owners security.Owners) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), a, e, owners, info.FullMethod, nil)
+		if err != nil {
+			return Status(err)
+		}
+
+		return handler(srv, userStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// userStream hands the context with the user to the stream handler
+type userStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s userStream) Context() context.Context {
+	return s.ctx
+}
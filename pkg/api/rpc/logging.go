@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key of the request id, the gRPC spelling of the X-Request-ID header
+const requestIDKey = "x-request-id"
+
+// requestID is the id the client sent or a new random one
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 && ids[0] != "" {
+			return ids[0]
+		}
+	}
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// withLogger puts a logger with the request id and the method in the context of a call and answers the id to the client
+func withLogger(ctx context.Context, l *logrus.Logger, fullMethod string) context.Context {
+	id := requestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	return logger.NewContext(ctx, l.WithFields(logrus.Fields{"request_id": id, "route": fullMethod}))
+}
+
+func logCall(ctx context.Context, start time.Time, err error) {
+	logger.From(ctx).WithFields(logrus.Fields{
+		"code":        status.Code(err).String(),
+		"duration_ms": time.Since(start).Milliseconds(),
+	}).Info("call")
+}
+
+// UnaryLogger logs every unary call once it is answered, it has to run ahead of UnaryAuth which adds the user
+func UnaryLogger(l *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = withLogger(ctx, l, info.FullMethod)
+		resp, err := handler(ctx, req)
+		logCall(ctx, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamLogger logs every stream once it is closed
+func StreamLogger(l *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := withLogger(ss.Context(), l, info.FullMethod)
+		err := handler(srv, userStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, start, err)
+
+		return err
+	}
+}
@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/rpc/pb"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/products"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type ProductServer struct {
+	pb.UnimplementedProductServiceServer
+	products.Service
+}
+
+func (s ProductServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	product, err := s.Get(ctx, req.GetName())
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return fromProduct(*product), nil
+}
+
+func (s ProductServer) CreateProduct(ctx context.Context, req *pb.Product) (*emptypb.Empty, error) {
+	product, err := toProduct(ctx, req)
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &emptypb.Empty{}, Status(s.Insert(ctx, product))
+}
+
+func (s ProductServer) UpdateProduct(ctx context.Context, req *pb.Product) (*emptypb.Empty, error) {
+	product, err := toProduct(ctx, req)
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &emptypb.Empty{}, Status(s.Update(ctx, product))
+}
+
+func (s ProductServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*emptypb.Empty, error) {
+	username := security.GetUser(ctx).Username
+	return &emptypb.Empty{}, Status(s.Delete(ctx, username, req.GetName()))
+}
+
+// toProduct makes the caller the seller, like the REST handlers do
+func toProduct(ctx context.Context, req *pb.Product) (products.Product, error) {
+	product := products.Product{
+		Name:     req.GetName(),
+		SellerID: security.GetUser(ctx).Username,
+		Price:    int(req.GetPrice()),
+		Amount:   int(req.GetAmount()),
+	}
+
+	return product, validate.Struct(&product)
+}
+
+func fromProduct(product products.Product) *pb.Product {
+	return &pb.Product{
+		Name:     product.Name,
+		SellerId: product.SellerID,
+		Price:    int32(product.Price),
+		Amount:   int32(product.Amount),
+	}
+}
@@ -0,0 +1,36 @@
+// Package rpc serves the use cases over gRPC, see pb/mvp.proto.
+// Calls are authorized as the REST request they mirror, so the casbin policy and the owner routes apply unchanged.
+package rpc
+
+import (
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/rpc/pb"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// Services are the use cases behind the gRPC services
+type Services struct {
+	Users    users.Service
+	Products products.Service
+	Vending  vending.Service
+}
+
+// NewServer registers the user, product and vending services behind the auth interceptors.
+// Every call continues the trace of its caller from the traceparent metadata and is logged with l.
+func NewServer(s Services, a security.Auth, e security.Enforcer, owners security.Owners, l *logrus.Logger, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), UnaryLogger(l), UnaryAuth(a, e, owners)),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), StreamLogger(l), StreamAuth(a, e, owners)),
+	)
+	server := grpc.NewServer(opts...)
+	pb.RegisterUserServiceServer(server, UserServer{Service: s.Users})
+	pb.RegisterProductServiceServer(server, ProductServer{Service: s.Products})
+	pb.RegisterVendingServiceServer(server, VendingServer{Service: s.Vending})
+
+	return server
+}
@@ -0,0 +1,328 @@
+package rpc_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/rpc"
+	"github.com/artback/mvp/pkg/api/rpc/pb"
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/casbin/casbin/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// testAuth knows mike the buyer and sam the seller, every password is "secret" and locked is locked
+type testAuth struct{}
+
+func (testAuth) GetUser(r *http.Request) (*security.User, error) {
+	username, password, ok := r.BasicAuth()
+	switch {
+	case !ok:
+		return nil, security.MissingHeaderErr
+	case username == "locked":
+		return nil, security.RetryError{Err: security.LockedErr, RetryAfter: time.Minute}
+	case password != "secret":
+		return nil, security.WrongPasswordErr
+	case username == "sam":
+		return &security.User{Username: username, Role: security.Seller}, nil
+	default:
+		return &security.User{Username: username, Role: security.Buyer}, nil
+	}
+}
+
+var testOwners = security.OwnerRoutes{
+	{Pattern: regexp.MustCompile(`^/v1/(deposit|reset|buy/[^/]+)$`), Owner: security.Self},
+}
+
+type clients struct {
+	users    pb.UserServiceClient
+	products pb.ProductServiceClient
+	vending  pb.VendingServiceClient
+}
+
+type serviceMocks struct {
+	users    *mocks.UserService
+	products *mocks.ProductService
+	vending  *mocks.VendingService
+}
+
+func TestServer(t *testing.T) {
+	e, err := casbin.NewSyncedEnforcer("../../../config/rbac_model.conf", "../../../config/auth_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anyArg := gomock.Any()
+
+	tests := []struct {
+		name     string
+		username string
+		setup    func(m serviceMocks)
+		call     func(ctx context.Context, c clients) error
+		code     codes.Code
+		reason   string
+		metadata map[string]string
+	}{
+		{
+			name:  "anonymous creates user",
+			setup: func(m serviceMocks) { m.users.EXPECT().Insert(anyArg, anyArg).Return(nil) },
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.users.CreateUser(ctx, &pb.CreateUserRequest{Username: "mike", Password: "secret"})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name: "anonymous user without password",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.users.CreateUser(ctx, &pb.CreateUserRequest{Username: "mike"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: "validation_failed",
+		},
+		{
+			name: "anonymous reads account",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.GetAccount(ctx, &emptypb.Empty{})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "locked account",
+			username: "locked",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.GetAccount(ctx, &emptypb.Empty{})
+				return err
+			},
+			code:   codes.PermissionDenied,
+			reason: "account_locked",
+		},
+		{
+			name:     "buyer deposits",
+			username: "mike",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().Deposit(anyArg, "mike", change.Deposit{5: 2, 100: 1}).Return(nil)
+			},
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.Deposit(ctx, &pb.DepositRequest{Coins: map[int32]int32{5: 2, 100: 1}})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "buyer deposits negative coins",
+			username: "mike",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.Deposit(ctx, &pb.DepositRequest{Coins: map[int32]int32{5: -2}})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: "deposit_invalid",
+		},
+		{
+			name:     "buyer buys without funds",
+			username: "mike",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().BuyProduct(anyArg, "mike", products.Product{Name: "cola", Amount: 1}).Return(vending.InsufficientFundsError{Cost: 25, Deposit: 10})
+			},
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.BuyProduct(ctx, &pb.BuyProductRequest{ProductName: "cola"})
+				return err
+			},
+			code:     codes.FailedPrecondition,
+			reason:   "insufficient_funds",
+			metadata: map[string]string{"cost": "25", "deposit": "10", "shortfall": "15"},
+		},
+		{
+			name:     "buyer buys more than stocked",
+			username: "mike",
+			setup: func(m serviceMocks) {
+				m.vending.EXPECT().BuyProduct(anyArg, "mike", products.Product{Name: "cola", Amount: 3}).Return(vending.OutOfStockError{Product: "cola", Requested: 3, Available: 1})
+			},
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.BuyProduct(ctx, &pb.BuyProductRequest{ProductName: "cola", Amount: 3})
+				return err
+			},
+			code:     codes.FailedPrecondition,
+			reason:   "out_of_stock",
+			metadata: map[string]string{"product": "cola", "requested": "3", "available": "1"},
+		},
+		{
+			name:     "seller buys",
+			username: "sam",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.vending.BuyProduct(ctx, &pb.BuyProductRequest{ProductName: "cola"})
+				return err
+			},
+			code:   codes.PermissionDenied,
+			reason: "forbidden",
+		},
+		{
+			name:     "buyer reads missing product",
+			username: "mike",
+			setup:    func(m serviceMocks) { m.products.EXPECT().Get(anyArg, "cola").Return(nil, repository.EmptyError{}) },
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.products.GetProduct(ctx, &pb.GetProductRequest{Name: "cola"})
+				return err
+			},
+			code:   codes.NotFound,
+			reason: "not_found",
+		},
+		{
+			name:     "buyer reads product with a slash",
+			username: "mike",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.products.GetProduct(ctx, &pb.GetProductRequest{Name: "cola/../../user/sam"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: "malformed_request",
+		},
+		{
+			name:     "buyer reads product with escapes",
+			username: "mike",
+			setup: func(m serviceMocks) {
+				m.products.EXPECT().Get(anyArg, "cola%2Fzero").Return(nil, repository.EmptyError{})
+			},
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.products.GetProduct(ctx, &pb.GetProductRequest{Name: "cola%2Fzero"})
+				return err
+			},
+			code:   codes.NotFound,
+			reason: "not_found",
+		},
+		{
+			name:     "seller creates product",
+			username: "sam",
+			setup: func(m serviceMocks) {
+				m.products.EXPECT().Insert(anyArg, products.Product{Name: "cola", SellerID: "sam", Price: 25, Amount: 10}).Return(nil)
+			},
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.products.CreateProduct(ctx, &pb.Product{Name: "cola", SellerId: "mike", Price: 25, Amount: 10})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "seller creates duplicate product",
+			username: "sam",
+			setup:    func(m serviceMocks) { m.products.EXPECT().Insert(anyArg, anyArg).Return(repository.DuplicateError{}) },
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.products.CreateProduct(ctx, &pb.Product{Name: "cola", Price: 25, Amount: 10})
+				return err
+			},
+			code:   codes.AlreadyExists,
+			reason: "conflict",
+		},
+		{
+			name:     "buyer creates product",
+			username: "mike",
+			call: func(ctx context.Context, c clients) error {
+				_, err := c.products.CreateProduct(ctx, &pb.Product{Name: "cola", Price: 25, Amount: 10})
+				return err
+			},
+			code:   codes.PermissionDenied,
+			reason: "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			m := serviceMocks{
+				users:    mocks.NewUserService(ctrl),
+				products: mocks.NewProductService(ctrl),
+				vending:  mocks.NewVendingService(ctrl),
+			}
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			c := dial(t, rpc.NewServer(rpc.Services{Users: m.users, Products: m.products, Vending: m.vending}, testAuth{}, e, testOwners, quiet()))
+
+			ctx := context.Background()
+			if tt.username != "" {
+				credentials := base64.StdEncoding.EncodeToString([]byte(tt.username + ":secret"))
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Basic "+credentials)
+			}
+
+			st := status.Convert(tt.call(ctx, c))
+			if st.Code() != tt.code {
+				t.Fatalf("code = %v (%v), want %v", st.Code(), st.Message(), tt.code)
+			}
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if d, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = d
+				}
+			}
+			if tt.reason == "" {
+				return
+			}
+			if info == nil || info.Reason != tt.reason || info.Domain != rpc.Domain {
+				t.Fatalf("ErrorInfo = %v, want reason %q", info, tt.reason)
+			}
+			for name, value := range tt.metadata {
+				if info.Metadata[name] != value {
+					t.Errorf("ErrorInfo metadata %s = %q, want %q", name, info.Metadata[name], value)
+				}
+			}
+		})
+	}
+}
+
+// quiet is a logger for the servers under test that writes nowhere
+func quiet() *logrus.Logger {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+
+	return l
+}
+
+// dial serves server in memory and returns clients connected to it
+func dial(t *testing.T, server *grpc.Server) clients {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return clients{
+		users:    pb.NewUserServiceClient(conn),
+		products: pb.NewProductServiceClient(conn),
+		vending:  pb.NewVendingServiceClient(conn),
+	}
+}
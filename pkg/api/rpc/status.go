@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain names the error codes in the ErrorInfo detail of a status
+const Domain = "mvp"
+
+var codeOf = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusPaymentRequired:       codes.FailedPrecondition,
+	http.StatusNotAcceptable:         codes.FailedPrecondition,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusLocked:                codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+}
+
+// Status turns err into a gRPC status. Errors are classified like problem.New classifies them for REST,
+// the stable problem code travels as the reason of an ErrorInfo detail, invalid fields as a BadRequest detail
+// and the wait of a throttled login as a RetryInfo detail. Errors that are a status already are kept.
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	p := problem.New(err)
+	code, ok := codeOf[p.Status]
+	switch {
+	case p.Code == "out_of_stock":
+		// a conflict with the stock, not with an existing resource
+		code = codes.FailedPrecondition
+	case !ok:
+		code = codes.Internal
+		logger.Default().WithError(err).Error("rpc failed")
+	}
+
+	info := &errdetails.ErrorInfo{Reason: p.Code, Domain: Domain}
+	for name, value := range p.Extensions {
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
+		}
+		info.Metadata[name] = fmt.Sprint(value)
+	}
+	details := []proto.Message{info}
+	if len(p.Errors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range p.Errors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+		}
+		details = append(details, badRequest)
+	}
+	var retry security.RetryError
+	if errors.As(err, &retry) {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retry.RetryAfter)})
+	}
+
+	st, detailErr := status.New(code, p.Detail).WithDetails(details...)
+	if detailErr != nil {
+		return status.Error(code, p.Detail)
+	}
+
+	return st.Err()
+}
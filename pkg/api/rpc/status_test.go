@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/repository"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "no error", code: codes.OK},
+		{name: "missing resource", err: repository.EmptyError{}, code: codes.NotFound, message: "resource does not exist"},
+		{name: "duplicate", err: repository.DuplicateError{Constraint: "users_pkey"}, code: codes.AlreadyExists, message: "resource already exists or is still referenced"},
+		{name: "store refusal", err: repository.InvalidError{Title: "check violation"}, code: codes.FailedPrecondition, message: "request was refused by the store"},
+		{name: "forbidden", err: security.ForbiddenErr, code: codes.PermissionDenied, message: "access denied"},
+		{name: "throttled", err: security.RetryError{Err: security.ThrottledErr, RetryAfter: time.Second}, code: codes.ResourceExhausted, message: "too many failed login attempts, retry in 1s"},
+		{name: "status is kept", err: status.Error(codes.Unauthenticated, "credentials are required"), code: codes.Unauthenticated, message: "credentials are required"},
+		{name: "unknown error reveals nothing", err: errors.New("dial tcp 10.0.0.1:5432: connection refused"), code: codes.Internal, message: "an unexpected error occurred"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			st := status.Convert(Status(tt.err))
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Errorf("Status() = %v %q, want %v %q", st.Code(), st.Message(), tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestStatus_details(t *testing.T) {
+	t.Parallel()
+
+	err := Status(problem.ValidationError{Fields: []problem.FieldError{{Field: "price", Code: "min", Message: "must be at least 1"}}})
+
+	var badRequest *errdetails.BadRequest
+	for _, detail := range status.Convert(err).Details() {
+		if d, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = d
+		}
+	}
+	if badRequest == nil || len(badRequest.FieldViolations) != 1 {
+		t.Fatalf("Status() BadRequest = %v, want one violation", badRequest)
+	}
+	if got := badRequest.FieldViolations[0]; got.Field != "price" || got.Description != "must be at least 1" {
+		t.Errorf("Status() violation = %v", got)
+	}
+
+	err = Status(security.RetryError{Err: security.LockedErr, RetryAfter: time.Minute})
+	for _, detail := range status.Convert(err).Details() {
+		if d, ok := detail.(*errdetails.RetryInfo); ok && d.RetryDelay.AsDuration() == time.Minute {
+			return
+		}
+	}
+	t.Errorf("Status() has no RetryInfo of a minute")
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/rpc/pb"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/users"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type UserServer struct {
+	pb.UnimplementedUserServiceServer
+	users.Service
+}
+
+func (s UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*emptypb.Empty, error) {
+	user := users.User{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		Role:     security.Role(req.GetRole()),
+		Contact:  req.GetContact(),
+	}
+	if err := validate.Struct(&user); err != nil {
+		return nil, Status(err)
+	}
+
+	return &emptypb.Empty{}, Status(s.Insert(ctx, user))
+}
+
+func (s UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	user, err := s.GetResponse(ctx, req.GetUsername())
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &pb.User{
+		Username: user.Username,
+		Role:     string(user.Role),
+		Deposit:  fromDeposit(user.Deposit),
+		Contact:  user.Contact,
+	}, nil
+}
+
+func (s UserServer) DeleteUser(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	username := security.GetUser(ctx).Username
+	return &emptypb.Empty{}, Status(s.Delete(ctx, username))
+}
+
+func fromDeposit(deposit change.Deposit) map[int32]int32 {
+	coins := make(map[int32]int32, len(deposit))
+	for c, count := range deposit {
+		coins[int32(c)] = int32(count)
+	}
+
+	return coins
+}
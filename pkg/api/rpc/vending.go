@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/api/rpc/pb"
+	"github.com/artback/mvp/pkg/api/validate"
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/vending"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type VendingServer struct {
+	pb.UnimplementedVendingServiceServer
+	vending.Service
+}
+
+func (s VendingServer) GetAccount(ctx context.Context, _ *emptypb.Empty) (*pb.Account, error) {
+	account, err := s.Service.GetAccount(ctx, security.GetUser(ctx).Username)
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	bought := make([]*pb.Product, 0, len(account.Products))
+	for _, product := range account.Products {
+		bought = append(bought, fromProduct(product))
+	}
+
+	return &pb.Account{Deposit: fromDeposit(account.Deposit), Products: bought, Spent: int32(account.Spent)}, nil
+}
+
+func (s VendingServer) Deposit(ctx context.Context, req *pb.DepositRequest) (*emptypb.Empty, error) {
+	deposit := make(change.Deposit, len(req.GetCoins()))
+	for c, count := range req.GetCoins() {
+		deposit[coin.Coin(c)] = int(count)
+	}
+	if err := validate.Struct(deposit); err != nil {
+		return nil, Status(err)
+	}
+
+	username := security.GetUser(ctx).Username
+	return &emptypb.Empty{}, Status(s.Service.Deposit(ctx, username, deposit))
+}
+
+func (s VendingServer) BuyProduct(ctx context.Context, req *pb.BuyProductRequest) (*emptypb.Empty, error) {
+	amount := int(req.GetAmount())
+	switch {
+	case amount == 0:
+		amount = 1
+	case amount < 0:
+		return nil, Status(problem.ValidationError{Fields: []problem.FieldError{
+			{Field: "amount", Code: "min", Message: "must be at least 1"},
+		}})
+	}
+
+	username := security.GetUser(ctx).Username
+	return &emptypb.Empty{}, Status(s.Service.BuyProduct(ctx, username, products.Product{Name: req.GetProductName(), Amount: amount}))
+}
+
+func (s VendingServer) ResetDeposit(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	username := security.GetUser(ctx).Username
+	return &emptypb.Empty{}, Status(s.SetDeposit(ctx, username, 0))
+}
+
+func (s VendingServer) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.Transaction, error) {
+	transaction, err := s.Service.GetTransaction(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &pb.Transaction{
+		Id:          int64(transaction.ID),
+		ProductName: transaction.ProductName,
+		Username:    transaction.Username,
+		Amount:      int32(transaction.Amount),
+		Price:       int32(transaction.Price),
+	}, nil
+}
@@ -0,0 +1,94 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/artback/mvp/pkg/logger"
+)
+
+// Reloader hands out the certificate of a certificate and a key file and loads it again once the files changed,
+// so rotated certificates are served without a restart. A rotation that can't be loaded, like a certificate
+// written before its key, keeps the previous certificate until the next check.
+type Reloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modified    time.Time
+	checked     time.Time
+}
+
+// NewReloader loads the certificate, the files are checked for changes at most every interval
+func NewReloader(certFile, keyFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	modified, err := r.modTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modified); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate is the tls.Config.GetCertificate of the reloaded certificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checked) >= r.interval {
+		r.checked = now
+		if err := r.reload(); err != nil {
+			logger.Default().WithError(err).WithField("certificate", r.certFile).Warn("reload TLS certificate")
+		}
+	}
+
+	return r.certificate, nil
+}
+
+func (r *Reloader) reload() error {
+	modified, err := r.modTime()
+	if err != nil {
+		return err
+	}
+	if !modified.After(r.modified) {
+		return nil
+	}
+	if err := r.load(modified); err != nil {
+		return err
+	}
+	logger.Default().WithField("certificate", r.certFile).Info("reloaded TLS certificate")
+
+	return nil
+}
+
+func (r *Reloader) load(modified time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.certificate, r.modified = &certificate, modified
+
+	return nil
+}
+
+// modTime is the time the later of both files changed
+func (r *Reloader) modTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,71 @@
+// Package tlsconfig builds the TLS configuration of the servers from certificate files
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	UnknownVersionErr = errors.New("unknown TLS version")
+	MissingKeyErr     = errors.New("a TLS certificate needs a key")
+	NoCertificatesErr = errors.New("no PEM certificates found")
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Options configure TLS, servers without a CertFile serve plain text
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is the oldest version accepted, e.g. "1.2"
+	MinVersion string
+	// ClientCAFile holds the CAs client certificates are verified with, empty asks for no client certificates.
+	// Clients without a certificate are still served and authenticate otherwise.
+	ClientCAFile string
+	// ReloadInterval is how often the certificate files are checked for a rotation
+	ReloadInterval time.Duration
+}
+
+// New returns the TLS configuration of o, nil when o has no certificate
+func New(o Options) (*tls.Config, error) {
+	if o.CertFile == "" {
+		return nil, nil
+	}
+	if o.KeyFile == "" {
+		return nil, MissingKeyErr
+	}
+	version, ok := versions[o.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", UnknownVersionErr, o.MinVersion)
+	}
+	certificate, err := NewReloader(o.CertFile, o.KeyFile, o.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{MinVersion: version, GetCertificate: certificate.GetCertificate}
+	if o.ClientCAFile != "" {
+		pem, err := os.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w in %s", NoCertificatesErr, o.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
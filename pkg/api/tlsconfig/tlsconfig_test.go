@@ -0,0 +1,151 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self signed certificate with serial and its key to dir
+func writeCertificate(t *testing.T, dir string, serial int64) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir, 1)
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		options    Options
+		wantErr    error
+		plain      bool
+		clientAuth tls.ClientAuthType
+	}{
+		{name: "plain text", options: Options{}, plain: true},
+		{name: "tls", options: Options{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2"}, clientAuth: tls.NoClientCert},
+		{name: "mutual tls", options: Options{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3", ClientCAFile: certFile},
+			clientAuth: tls.VerifyClientCertIfGiven},
+		{name: "missing key", options: Options{CertFile: certFile, MinVersion: "1.2"}, wantErr: MissingKeyErr},
+		{name: "unknown version", options: Options{CertFile: certFile, KeyFile: keyFile, MinVersion: "2"}, wantErr: UnknownVersionErr},
+		{name: "client CA without certificates", options: Options{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2", ClientCAFile: notPEM},
+			wantErr: NoCertificatesErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			config, err := New(tt.options)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (config == nil) != tt.plain {
+				t.Fatalf("New() = %v, want plain text %v", config, tt.plain)
+			}
+			if config != nil && config.ClientAuth != tt.clientAuth {
+				t.Errorf("New() ClientAuth = %v, want %v", config.ClientAuth, tt.clientAuth)
+			}
+		})
+	}
+}
+
+func TestReloader_GetCertificate(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir, 1)
+	r, err := NewReloader(certFile, keyFile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial := func() int64 {
+		t.Helper()
+		certificate, err := r.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+	// file times may be coarser than the test is fast
+	touch := func() {
+		t.Helper()
+		later := time.Now().Add(time.Minute)
+		for _, file := range []string{certFile, keyFile} {
+			if err := os.Chtimes(file, later, later); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if got := serial(); got != 1 {
+		t.Fatalf("serial = %d, want 1", got)
+	}
+
+	writeCertificate(t, dir, 2)
+	touch()
+	if got := serial(); got != 2 {
+		t.Errorf("serial after rotation = %d, want 2", got)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("half written"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(keyFile, time.Now().Add(2*time.Minute), time.Now().Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got := serial(); got != 2 {
+		t.Errorf("serial after a broken rotation = %d, want the previous 2", got)
+	}
+}
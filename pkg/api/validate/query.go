@@ -0,0 +1,69 @@
+package validate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/artback/mvp/pkg/api/problem"
+)
+
+// Query reads query parameters of a request and collects every invalid one, Err reports them after all are read
+type Query struct {
+	values url.Values
+	fields []problem.FieldError
+}
+
+func NewQuery(r *http.Request) *Query {
+	return &Query{values: r.URL.Query()}
+}
+
+// Int returns the integer parameter name, def when it is absent
+func (q *Query) Int(name string, def, min int) int {
+	str := q.values.Get(name)
+	if str == "" {
+		return def
+	}
+
+	i, err := strconv.Atoi(str)
+	switch {
+	case err != nil:
+		q.fields = append(q.fields, problem.FieldError{Field: name, Code: "type", Message: "must be an integer"})
+	case i < min:
+		q.fields = append(q.fields, problem.FieldError{Field: name, Code: "min", Message: fmt.Sprintf("must be at least %d", min)})
+	default:
+		return i
+	}
+
+	return def
+}
+
+// Strings returns every value of the repeated parameter name, each has to be between 1 and max characters long
+func (q *Query) Strings(name string, max int) []string {
+	values := q.values[name]
+	for _, v := range values {
+		switch n := utf8.RuneCountInString(v); {
+		case n == 0:
+			q.fields = append(q.fields, problem.FieldError{Field: name, Code: "required", Message: "is required"})
+		case n > max:
+			q.fields = append(q.fields, problem.FieldError{Field: name, Code: "max", Message: fmt.Sprintf("must have at most %d characters", max)})
+		default:
+			continue
+		}
+
+		return nil
+	}
+
+	return values
+}
+
+// Err is a problem.ValidationError when a parameter was invalid
+func (q *Query) Err() error {
+	if len(q.fields) == 0 {
+		return nil
+	}
+
+	return problem.ValidationError{Fields: q.fields}
+}
@@ -0,0 +1,210 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/artback/mvp/pkg/api/problem"
+)
+
+// MaxBodySize bounds every json request body
+const MaxBodySize = 64 << 10
+
+// Validator is implemented by types with rules that struct tags can't express
+type Validator interface {
+	Validate() error
+}
+
+// Decode reads the json body of r into v and checks it with Struct
+func Decode(r *http.Request, v interface{}) error {
+	if err := Body(r, v); err != nil {
+		return err
+	}
+
+	return Struct(v)
+}
+
+// Body reads the json body of r into v without checking it, for handlers that complete v from the path first
+func Body(r *http.Request, v interface{}) error {
+	body, err := Read(r)
+	if err != nil {
+		return err
+	}
+
+	return Unmarshal(body, v)
+}
+
+// Read returns the body of r, refusing bodies larger than MaxBodySize
+func Read(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", problem.MalformedErr, err)
+	}
+	if len(body) > MaxBodySize {
+		return nil, problem.TooLargeErr
+	}
+
+	return body, nil
+}
+
+// Unmarshal decodes exactly one json value into v, unknown members and trailing data are refused
+func Unmarshal(body []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if err := decoder.Decode(&json.RawMessage{}); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after the json body", problem.MalformedErr)
+	}
+
+	return nil
+}
+
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: empty body", problem.MalformedErr)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return problem.ValidationError{Fields: []problem.FieldError{
+			{Field: typeErr.Field, Code: "type", Message: "must be " + describe(typeErr.Type)},
+		}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return problem.ValidationError{Fields: []problem.FieldError{
+			{Field: field, Code: "unknown", Message: "is not allowed"},
+		}}
+	default:
+		return fmt.Errorf("%w: %v", problem.MalformedErr, err)
+	}
+}
+
+func describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "a " + t.String()
+	}
+}
+
+// Struct checks the validate tags of the fields of v and reports every failing field at once.
+// Rules are separated by commas: required, min=n and max=n (the value of integers, the length of strings)
+// and oneof=a b. Nil pointers are only checked by required, embedded structs are checked as part of v.
+// When every field is valid and v is a Validator its own rules run last.
+func Struct(v interface{}) error {
+	var fields []problem.FieldError
+	if value := reflect.Indirect(reflect.ValueOf(v)); value.Kind() == reflect.Struct {
+		check(value, &fields)
+	}
+	if len(fields) > 0 {
+		return problem.ValidationError{Fields: fields}
+	}
+
+	if validator, ok := v.(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+func check(v reflect.Value, fields *[]problem.FieldError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Anonymous && value.Kind() == reflect.Struct {
+			check(value, fields)
+			continue
+		}
+
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			if code, message, ok := apply(rule, value); !ok {
+				*fields = append(*fields, problem.FieldError{Field: name(field), Code: code, Message: message})
+				break
+			}
+		}
+	}
+}
+
+// name is the json name of field, the name clients know it by
+func name(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+		return tag
+	}
+
+	return field.Name
+}
+
+func apply(rule string, value reflect.Value) (code, message string, ok bool) {
+	code, arg := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		code, arg = rule[:i], rule[i+1:]
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return code, "is required", code != "required"
+		}
+		value = value.Elem()
+	}
+
+	switch code {
+	case "required":
+		return code, "is required", !value.IsZero()
+	case "min":
+		n := bound(rule, arg)
+		if value.Kind() == reflect.String {
+			return code, fmt.Sprintf("must have at least %d characters", n), utf8.RuneCountInString(value.String()) >= n
+		}
+		return code, fmt.Sprintf("must be at least %d", n), value.Int() >= int64(n)
+	case "max":
+		n := bound(rule, arg)
+		if value.Kind() == reflect.String {
+			return code, fmt.Sprintf("must have at most %d characters", n), utf8.RuneCountInString(value.String()) <= n
+		}
+		return code, fmt.Sprintf("must be at most %d", n), value.Int() <= int64(n)
+	case "oneof":
+		options := strings.Fields(arg)
+		for _, option := range options {
+			if value.String() == option {
+				return code, "", true
+			}
+		}
+		return code, "must be one of " + strings.Join(options, ", "), false
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", rule))
+	}
+}
+
+func bound(rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("validate: rule %q needs an integer", rule))
+	}
+
+	return n
+}
@@ -0,0 +1,176 @@
+package validate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/artback/mvp/pkg/api/problem"
+)
+
+var noCoinsErr = errors.New("no coins")
+
+type item struct {
+	Name    string  `json:"name" validate:"required,max=5"`
+	Price   int     `json:"price" validate:"min=1"`
+	Kind    string  `json:"kind" validate:"oneof=can bottle"`
+	Note    *string `json:"note" validate:"max=3"`
+	Ignored int     `json:"-"`
+}
+
+type order struct {
+	item
+	Coins int `json:"coins"`
+}
+
+func (o order) Validate() error {
+	if o.Coins == 0 {
+		return noCoinsErr
+	}
+
+	return nil
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	long := "long note"
+
+	tests := []struct {
+		name string
+		body string
+		into interface{}
+		want interface{}
+		err  error
+	}{
+		{
+			name: "valid",
+			body: `{"name": "cola", "price": 5, "kind": "can"}`,
+			into: &item{},
+			want: &item{Name: "cola", Price: 5, Kind: "can"},
+		},
+		{
+			name: "every invalid field is reported",
+			body: `{"name": "", "price": -1, "kind": "box", "note": "long note"}`,
+			into: &item{},
+			err: problem.ValidationError{Fields: []problem.FieldError{
+				{Field: "name", Code: "required", Message: "is required"},
+				{Field: "price", Code: "min", Message: "must be at least 1"},
+				{Field: "kind", Code: "oneof", Message: "must be one of can, bottle"},
+				{Field: "note", Code: "max", Message: "must have at most 3 characters"},
+			}},
+			want: &item{Price: -1, Kind: "box", Note: &long},
+		},
+		{
+			name: "unknown field",
+			body: `{"name": "cola", "seller": "mike"}`,
+			into: &item{},
+			err:  problem.ValidationError{Fields: []problem.FieldError{{Field: "seller", Code: "unknown", Message: "is not allowed"}}},
+			want: &item{Name: "cola"},
+		},
+		{
+			name: "wrong type",
+			body: `{"price": "five"}`,
+			into: &item{},
+			err:  problem.ValidationError{Fields: []problem.FieldError{{Field: "price", Code: "type", Message: "must be an integer"}}},
+			want: &item{},
+		},
+		{
+			name: "embedded fields are checked before Validate",
+			body: `{"name": "cola", "price": 0, "kind": "can"}`,
+			into: &order{},
+			err:  problem.ValidationError{Fields: []problem.FieldError{{Field: "price", Code: "min", Message: "must be at least 1"}}},
+			want: &order{item: item{Name: "cola", Kind: "can"}},
+		},
+		{
+			name: "Validate runs last",
+			body: `{"name": "cola", "price": 5, "kind": "can"}`,
+			into: &order{},
+			err:  noCoinsErr,
+			want: &order{item: item{Name: "cola", Price: 5, Kind: "can"}},
+		},
+		{
+			name: "trailing data",
+			body: `{"name": "cola", "price": 5, "kind": "can"} {}`,
+			into: &item{},
+			err:  problem.MalformedErr,
+			want: &item{Name: "cola", Price: 5, Kind: "can"},
+		},
+		{
+			name: "empty body",
+			into: &item{},
+			err:  problem.MalformedErr,
+			want: &item{},
+		},
+		{
+			name: "too large",
+			body: `{"name": "` + strings.Repeat("a", MaxBodySize) + `"}`,
+			into: &item{},
+			err:  problem.TooLargeErr,
+			want: &item{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			err := Decode(r, tt.into)
+			if !errors.Is(err, tt.err) && !reflect.DeepEqual(err, tt.err) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(tt.into, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", tt.into, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		query  string
+		amount int
+		limit  int
+		names  []string
+		err    error
+	}{
+		{name: "defaults", amount: 1, limit: 0},
+		{name: "values", query: "?amount=3&limit=10&name=cola&name=tea", amount: 3, limit: 10, names: []string{"cola", "tea"}},
+		{
+			name:   "every invalid parameter is reported",
+			query:  "?amount=0&limit=ten&name=cola&name=",
+			amount: 1,
+			limit:  0,
+			err: problem.ValidationError{Fields: []problem.FieldError{
+				{Field: "amount", Code: "min", Message: "must be at least 1"},
+				{Field: "limit", Code: "type", Message: "must be an integer"},
+				{Field: "name", Code: "required", Message: "is required"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			q := NewQuery(httptest.NewRequest(http.MethodGet, "/"+tt.query, nil))
+			amount, limit := q.Int("amount", 1, 1), q.Int("limit", 0, 0)
+			if amount != tt.amount || limit != tt.limit {
+				t.Errorf("Int() = %v %v, want %v %v", amount, limit, tt.amount, tt.limit)
+			}
+			if names := q.Strings("name", 4); !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("Strings() = %v, want %v", names, tt.names)
+			}
+			if err := q.Err(); !reflect.DeepEqual(err, tt.err) {
+				t.Errorf("Err() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Package backoff computes exponential waits between retries
+package backoff
+
+import "time"
+
+// Exponential is the wait after the given number of failures: base after the first, doubled after every further
+// one and capped at max. No failures need no wait.
+func Exponential(base, max time.Duration, failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+
+	delay := base
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay
+}
@@ -0,0 +1,35 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		base     time.Duration
+		max      time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", base: time.Second, max: time.Minute, failures: 0, want: 0},
+		{name: "first failure", base: time.Second, max: time.Minute, failures: 1, want: time.Second},
+		{name: "doubles", base: time.Second, max: time.Minute, failures: 4, want: 8 * time.Second},
+		{name: "capped", base: time.Second, max: 5 * time.Second, failures: 4, want: 5 * time.Second},
+		{name: "doesn't overflow", base: time.Second, max: time.Hour, failures: 1000, want: time.Hour},
+		{name: "base above max", base: time.Minute, max: time.Second, failures: 1, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Exponential(tt.base, tt.max, tt.failures); got != tt.want {
+				t.Errorf("Exponential() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
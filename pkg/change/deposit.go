@@ -1,12 +1,26 @@
 package change
 
 import (
+	"errors"
 	"github.com/artback/mvp/pkg/coin"
 	"sort"
 )
 
+var InvalidDepositErr = errors.New("coin counts can not be negative")
+
 type Deposit map[coin.Coin]int
 
+// Validate refuses negative counts, which would take coins out of the deposit
+func (d Deposit) Validate() error {
+	for _, count := range d {
+		if count < 0 {
+			return InvalidDepositErr
+		}
+	}
+
+	return nil
+}
+
 func (d Deposit) ToAmount() int {
 	var amount int
 	for c, a := range d {
@@ -16,11 +30,13 @@ func (d Deposit) ToAmount() int {
 	return amount
 }
 
+// New breaks amount into the largest coins first, coins is left in its order
 func New(coins coin.Coins, amount int) Deposit {
 	deposit := make(Deposit, len(coins))
-	sort.Sort(coins)
+	sorted := append(coin.Coins(nil), coins...)
+	sort.Sort(sorted)
 
-	for _, c := range coins {
+	for _, c := range sorted {
 		if amount/c > 0 {
 			deposit[coin.Coin(c)], amount = amount/c, amount%c
 		}
@@ -1,10 +1,24 @@
 package coin
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+var InvalidCoinsErr = errors.New("invalid coins")
+
 type (
 	Coin  int
 	Coins []int
 )
 
+// Source gives the coins accepted at the moment
+type Source interface {
+	Coins() Coins
+}
+
 func (c Coins) Len() int {
 	return len(c)
 }
@@ -16,3 +30,59 @@ func (c Coins) Less(i, j int) bool {
 func (c Coins) Swap(i, j int) {
 	c[i], c[j] = c[j], c[i]
 }
+
+// Coins makes a fixed set of coins a Source
+func (c Coins) Coins() Coins {
+	return c
+}
+
+// Validate refuses an empty set, coins that aren't positive and coins listed twice
+func (c Coins) Validate() error {
+	if len(c) == 0 {
+		return fmt.Errorf("%w: no coins", InvalidCoinsErr)
+	}
+	seen := make(map[int]bool, len(c))
+	for _, coin := range c {
+		if coin <= 0 {
+			return fmt.Errorf("%w: coin %d must be positive", InvalidCoinsErr, coin)
+		}
+		if seen[coin] {
+			return fmt.Errorf("%w: coin %d is listed twice", InvalidCoinsErr, coin)
+		}
+		seen[coin] = true
+	}
+
+	return nil
+}
+
+// Set holds the accepted coins and lets them be replaced while requests read them.
+// A reader keeps the coins it got for as long as it needs them, it never sees half a replacement.
+type Set struct {
+	coins atomic.Value
+}
+
+func NewSet(coins Coins) (*Set, error) {
+	s := &Set{}
+	if err := s.Replace(coins); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Coins returns the current coins sorted from large to small, they must not be modified
+func (s *Set) Coins() Coins {
+	return s.coins.Load().(Coins)
+}
+
+// Replace swaps in a copy of coins, invalid coins leave the current ones in place
+func (s *Set) Replace(coins Coins) error {
+	if err := coins.Validate(); err != nil {
+		return err
+	}
+	sorted := append(Coins(nil), coins...)
+	sort.Sort(sorted)
+	s.coins.Store(sorted)
+
+	return nil
+}
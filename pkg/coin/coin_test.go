@@ -0,0 +1,93 @@
+package coin
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCoins_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		coins   Coins
+		wantErr error
+	}{
+		{name: "valid", coins: Coins{5, 10, 20}},
+		{name: "empty", wantErr: InvalidCoinsErr},
+		{name: "zero", coins: Coins{0, 5}, wantErr: InvalidCoinsErr},
+		{name: "negative", coins: Coins{5, -10}, wantErr: InvalidCoinsErr},
+		{name: "twice", coins: Coins{5, 10, 5}, wantErr: InvalidCoinsErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.coins.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSet_Replace(t *testing.T) {
+	t.Parallel()
+	given := Coins{5, 50, 10}
+	s, err := NewSet(given)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Coins(); !reflect.DeepEqual(got, Coins{50, 10, 5}) {
+		t.Errorf("Coins() = %v, want [50 10 5]", got)
+	}
+	if !reflect.DeepEqual(given, Coins{5, 50, 10}) {
+		t.Errorf("NewSet() sorted the coins it was given: %v", given)
+	}
+
+	if err := s.Replace(Coins{25, 0}); !errors.Is(err, InvalidCoinsErr) {
+		t.Errorf("Replace() error = %v, want %v", err, InvalidCoinsErr)
+	}
+	if got := s.Coins(); !reflect.DeepEqual(got, Coins{50, 10, 5}) {
+		t.Errorf("Coins() after an invalid replacement = %v, want the previous [50 10 5]", got)
+	}
+
+	if err := s.Replace(Coins{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Coins(); !reflect.DeepEqual(got, Coins{2, 1}) {
+		t.Errorf("Coins() = %v, want [2 1]", got)
+	}
+}
+
+func TestSet_ConcurrentReplace(t *testing.T) {
+	t.Parallel()
+	s, err := NewSet(Coins{5, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if coins := s.Coins(); len(coins) != 2 {
+					t.Errorf("Coins() = %v, want one of the sets", coins)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := s.Replace(Coins{20, 50}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
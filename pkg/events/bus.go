@@ -0,0 +1,122 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Bus fans events out to the subscribers of this process.
+// With a Relay, published events go through it and come back through Deliver, e.g. from every replica.
+type Bus struct {
+	Relay Publisher
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewBus(relay Publisher) *Bus {
+	return &Bus{Relay: relay, subscribers: make(map[*Subscription]struct{})}
+}
+
+func (b *Bus) Publish(ctx context.Context, e Event) error {
+	if b.Relay != nil {
+		return b.Relay.Publish(ctx, e)
+	}
+	b.Deliver(e)
+
+	return nil
+}
+
+// Deliver hands e to every subscriber, it never blocks on a slow one
+func (b *Bus) Deliver(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers {
+		s.add(e)
+	}
+}
+
+// Subscribe receives every event delivered from now on, until the subscription is closed
+func (b *Bus) Subscribe() *Subscription {
+	s := &Subscription{bus: b, ready: make(chan struct{}, 1), done: make(chan struct{}), queued: make(map[Event]struct{})}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.done)
+		return s
+	}
+	b.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// Close ends every subscription, e.g. so that a server shutdown doesn't wait for streams that never finish
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subscribers {
+		close(s.done)
+		delete(b.subscribers, s)
+	}
+}
+
+// Subscription queues events until they are taken. An event that is still queued is not queued twice,
+// so a slow subscriber catches up with one load per changed product instead of one per change.
+type Subscription struct {
+	bus   *Bus
+	ready chan struct{}
+	done  chan struct{}
+
+	mu      sync.Mutex
+	pending []Event
+	queued  map[Event]struct{}
+}
+
+func (s *Subscription) add(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.queued[e]; ok {
+		return
+	}
+	s.queued[e] = struct{}{}
+	s.pending = append(s.pending, e)
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready receives when events are waiting to be taken
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Done is closed when the bus is closed, no more events follow
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Take returns the waiting events in the order they were delivered
+func (s *Subscription) Take() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending
+	s.pending = nil
+	s.queued = make(map[Event]struct{})
+
+	return pending
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subscribers, s)
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type relay struct {
+	bus *Bus
+}
+
+func (r relay) Publish(_ context.Context, e Event) error {
+	r.bus.Deliver(e)
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		relayed bool
+		publish []Event
+		want    []Event
+	}{
+		{
+			name:    "delivered in order",
+			publish: []Event{{Type: Product, Key: "cola"}, {Type: Deposit, Key: "mike"}},
+			want:    []Event{{Type: Product, Key: "cola"}, {Type: Deposit, Key: "mike"}},
+		},
+		{
+			name:    "queued events are not queued twice",
+			publish: []Event{{Type: Product, Key: "cola"}, {Type: Deposit, Key: "mike"}, {Type: Product, Key: "cola"}},
+			want:    []Event{{Type: Product, Key: "cola"}, {Type: Deposit, Key: "mike"}},
+		},
+		{
+			name:    "relayed events come back through Deliver",
+			relayed: true,
+			publish: []Event{{Type: Product, Key: "cola"}},
+			want:    []Event{{Type: Product, Key: "cola"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bus := NewBus(nil)
+			if tt.relayed {
+				bus.Relay = relay{bus: bus}
+			}
+			s := bus.Subscribe()
+			defer s.Close()
+
+			for _, e := range tt.publish {
+				if err := bus.Publish(context.Background(), e); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			<-s.Ready()
+			if got := s.Take(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Take() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscription_Close(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus(nil)
+	s := bus.Subscribe()
+	s.Close()
+
+	bus.Deliver(Event{Type: Product, Key: "cola"})
+
+	select {
+	case <-s.Ready():
+		t.Error("closed subscription received an event")
+	default:
+	}
+}
+
+func TestSubscription_Take(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus(nil)
+	s := bus.Subscribe()
+	defer s.Close()
+
+	bus.Deliver(Event{Type: Product, Key: "cola"})
+	s.Take()
+	bus.Deliver(Event{Type: Product, Key: "cola"})
+
+	if got := s.Take(); len(got) != 1 {
+		t.Errorf("Take() after taking = %v, want the event again", got)
+	}
+}
+
+func TestBus_Close(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus(nil)
+	s := bus.Subscribe()
+	bus.Close()
+	s.Close()
+
+	select {
+	case <-s.Done():
+	default:
+		t.Error("subscription is not done after the bus closed")
+	}
+	select {
+	case <-bus.Subscribe().Done():
+	default:
+		t.Error("subscription to a closed bus is not done")
+	}
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+type Type string
+
+const (
+	// Product tells that a product was created, changed, bought or deleted, Key is its name
+	Product Type = "product"
+	// Deposit tells that the deposit of a user changed, Key is the username
+	Deposit Type = "deposit"
+	// Resync tells subscribers that events may have been lost, e.g. while a replica reconnected to postgres
+	Resync Type = "resync"
+)
+
+// Event only names what changed, subscribers load the current state themselves.
+// That keeps events small enough for postgres NOTIFY and never delivers a stale state.
+type Event struct {
+	Type Type   `json:"type"`
+	Key  string `json:"key"`
+}
+
+//go:generate mockgen -destination=../../mocks/mock_events_publisher.go -mock_names=Publisher=EventPublisher -package=mocks github.com/artback/mvp/pkg/events Publisher
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Publish hands e to p unless p is nil. The change it announces already happened,
+// so a failure is only logged, subscribers miss the update but nothing else breaks.
+func Publish(ctx context.Context, p Publisher, e Event) {
+	if p == nil {
+		return
+	}
+	if err := p.Publish(ctx, e); err != nil {
+		logger.From(ctx).WithError(err).WithFields(logrus.Fields{"event": e.Type, "key": e.Key}).Warn("publish event")
+	}
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	NotReadyErr = errors.New("not ready")
+	DrainingErr = errors.New("shutting down")
+	NoPolicyErr = errors.New("no authorization policy is loaded")
+)
+
+// SchemaError is returned while the database schema is older than the one the service was built for
+type SchemaError struct {
+	Have, Want int
+}
+
+func (s SchemaError) Error() string {
+	return fmt.Sprintf("schema version is %d, want at least %d", s.Have, s.Want)
+}
+
+type Status string
+
+const (
+	Up   Status = "up"
+	Down Status = "down"
+)
+
+// Check is the state of one thing the service depends on
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report answers a probe, the service is Up when every check is
+type Report struct {
+	Status Status  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Add appends the check name, it is Down when err isn't nil and takes the report down with it
+func (r *Report) Add(name string, err error) {
+	check := Check{Name: name, Status: Up}
+	if err != nil {
+		check.Status, check.Error = Down, err.Error()
+		r.Status = Down
+	}
+	if r.Status == "" {
+		r.Status = Up
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// Drain is started when the service is told to stop, readiness fails from then on
+// so load balancers move traffic away before the listeners close
+type Drain struct {
+	draining int32
+}
+
+func (d *Drain) Start() {
+	atomic.StoreInt32(&d.draining, 1)
+}
+
+func (d *Drain) Draining() bool {
+	return atomic.LoadInt32(&d.draining) == 1
+}
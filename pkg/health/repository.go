@@ -0,0 +1,16 @@
+package health
+
+import "context"
+
+//go:generate mockgen -destination=../../mocks/mock_health_repository.go -mock_names=Repository=HealthRepository -package=mocks github.com/artback/mvp/pkg/health Repository
+type Repository interface {
+	// Ping checks that the database answers
+	Ping(ctx context.Context) error
+	// SchemaVersion is the version of the schema the database was set up with
+	SchemaVersion(ctx context.Context) (int, error)
+}
+
+// Policy is the authorization policy, the casbin enforcers implement it
+type Policy interface {
+	GetPolicy() [][]string
+}
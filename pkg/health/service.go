@@ -0,0 +1,9 @@
+package health
+
+import "context"
+
+//go:generate mockgen -destination=../../mocks/mock_health_service.go -mock_names=Service=HealthService -package=mocks github.com/artback/mvp/pkg/health Service
+type Service interface {
+	// Ready checks whether the service can take requests
+	Ready(ctx context.Context) Report
+}
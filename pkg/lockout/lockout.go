@@ -0,0 +1,79 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/artback/mvp/pkg/backoff"
+)
+
+// Policy decides how failed logins are throttled.
+// Every failure doubles the wait before the next attempt, starting at BaseDelay and capped at MaxDelay,
+// until MaxFailures is reached and the key is locked for LockoutFor.
+type Policy struct {
+	MaxFailures int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	LockoutFor  time.Duration
+	// Window after the last failure in which failures are remembered
+	Window time.Duration
+}
+
+var DefaultPolicy = Policy{
+	MaxFailures: 5,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	LockoutFor:  15 * time.Minute,
+	Window:      15 * time.Minute,
+}
+
+// ResetScope keeps password reset requests apart from logins, ResetPolicy throttles them.
+// Every request counts, there is no lockout, a username or client only waits longer the more it asks.
+const ResetScope = "reset:"
+
+var ResetPolicy = Policy{
+	BaseDelay: time.Minute,
+	MaxDelay:  time.Hour,
+	Window:    24 * time.Hour,
+}
+
+// State is the failure history of one username or client
+type State struct {
+	Key         string
+	Failures    int
+	LastFailure time.Time
+}
+
+// Expired reports whether the failures of s are forgotten at now, the window after the last one has passed
+func (p Policy) Expired(s State, now time.Time) bool {
+	return s.LastFailure.Before(now.Add(-p.Window))
+}
+
+// Locked reports whether s reached the lockout threshold
+func (p Policy) Locked(s State) bool {
+	return p.MaxFailures > 0 && s.Failures >= p.MaxFailures
+}
+
+// RetryAt is the earliest time the next attempt is accepted, the zero time when s is not throttled
+func (p Policy) RetryAt(s State) time.Time {
+	if s.Failures == 0 {
+		return time.Time{}
+	}
+	if p.Locked(s) {
+		return s.LastFailure.Add(p.LockoutFor)
+	}
+
+	return s.LastFailure.Add(p.Delay(s.Failures))
+}
+
+// Delay is the wait after the given number of failures, ignoring the lockout
+func (p Policy) Delay(failures int) time.Duration {
+	return backoff.Exponential(p.BaseDelay, p.MaxDelay, failures)
+}
+
+func UserKey(username string) string {
+	return "user:" + username
+}
+
+func ClientKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,97 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+var policy = Policy{MaxFailures: 4, BaseDelay: time.Second, MaxDelay: 5 * time.Second, LockoutFor: time.Hour, Window: 10 * time.Minute}
+
+func TestPolicy_Delay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "first failure", failures: 1, want: time.Second},
+		{name: "doubles", failures: 2, want: 2 * time.Second},
+		{name: "doubles again", failures: 3, want: 4 * time.Second},
+		{name: "capped", failures: 4, want: 5 * time.Second},
+		{name: "stays capped", failures: 60, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := policy.Delay(tt.failures); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_RetryAt(t *testing.T) {
+	t.Parallel()
+
+	last := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		failures   int
+		wantLocked bool
+		want       time.Time
+	}{
+		{name: "not throttled", policy: policy, failures: 0, want: time.Time{}},
+		{name: "delayed", policy: policy, failures: 2, want: last.Add(2 * time.Second)},
+		{name: "locked at the threshold", policy: policy, failures: 4, wantLocked: true, want: last.Add(time.Hour)},
+		{name: "locked past the threshold", policy: policy, failures: 7, wantLocked: true, want: last.Add(time.Hour)},
+		{name: "locking disabled", policy: Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, failures: 7, want: last.Add(5 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := State{Key: UserKey("mike"), Failures: tt.failures, LastFailure: last}
+			if got := tt.policy.Locked(s); got != tt.wantLocked {
+				t.Errorf("Locked() = %v, want %v", got, tt.wantLocked)
+			}
+			if got := tt.policy.RetryAt(s); !got.Equal(tt.want) {
+				t.Errorf("RetryAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_Expired(t *testing.T) {
+	t.Parallel()
+
+	last := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		since time.Duration
+		want  bool
+	}{
+		{name: "just failed", since: 0, want: false},
+		{name: "inside the window", since: 9 * time.Minute, want: false},
+		{name: "end of the window", since: 10 * time.Minute, want: false},
+		{name: "past the window", since: 10*time.Minute + time.Second, want: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := State{Key: ClientKey("10.0.0.1"), Failures: 3, LastFailure: last}
+			if got := policy.Expired(s, last.Add(tt.since)); got != tt.want {
+				t.Errorf("Expired() after %v = %v, want %v", tt.since, got, tt.want)
+			}
+		})
+	}
+}
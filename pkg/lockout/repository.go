@@ -0,0 +1,15 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/mock_lockout_repository.go -mock_names=Repository=LockoutRepository -package=mocks github.com/artback/mvp/pkg/lockout Repository
+type Repository interface {
+	// Get returns the states of the keys with recorded failures
+	Get(ctx context.Context, keys ...string) ([]State, error)
+	// Fail records a failure at now, failures before since are forgotten first
+	Fail(ctx context.Context, key string, now, since time.Time) (State, error)
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,11 @@
+package lockout
+
+import "context"
+
+//go:generate mockgen -destination=../../mocks/mock_lockout_service.go -mock_names=Service=LockoutService -package=mocks github.com/artback/mvp/pkg/lockout Service
+type Service interface {
+	// Check fails while username or ip are throttled, failed reports whether username has failures to reset
+	Check(ctx context.Context, username, ip string) (failed bool, err error)
+	Fail(ctx context.Context, username, ip string) error
+	Reset(ctx context.Context, username string) error
+}
@@ -0,0 +1,107 @@
+// Package logger builds the structured logger of the service and carries it through contexts.
+// Middlewares put a logger with the fields of a request in its context, everything below logs through From(ctx),
+// so every line of a request tells its request id, user and route.
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats of the log lines
+const (
+	JSON = "json"
+	Text = "text"
+)
+
+var UnknownFormatErr = errors.New("unknown log format")
+
+// Options choose how lines are written and the least severe level that is written
+type Options struct {
+	Format string
+	Level  string
+}
+
+// New returns a logger writing to w, credentials and passwords in its lines are redacted
+func New(w io.Writer, o Options) (*logrus.Logger, error) {
+	level, err := logrus.ParseLevel(o.Level)
+	if err != nil {
+		return nil, err
+	}
+	var formatter logrus.Formatter
+	switch strings.ToLower(o.Format) {
+	case JSON:
+		formatter = &logrus.JSONFormatter{}
+	case Text:
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
+	default:
+		return nil, fmt.Errorf("%w: %q", UnknownFormatErr, o.Format)
+	}
+
+	l := logrus.New()
+	l.SetOutput(w)
+	l.SetLevel(level)
+	l.SetFormatter(redactor{Formatter: formatter})
+
+	return l, nil
+}
+
+var (
+	mu     sync.RWMutex
+	std, _ = New(os.Stderr, Options{Format: Text, Level: logrus.InfoLevel.String()})
+)
+
+// SetDefault makes l the logger of contexts without one
+func SetDefault(l *logrus.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	std = l
+}
+
+// Default is the logger of contexts without one, a text logger on stderr until SetDefault is called
+func Default() *logrus.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return std
+}
+
+type contextKey struct{}
+
+// fields is shared by a context and the contexts derived from it, so fields added deep inside a request,
+// like the user found by the authentication, show up in the line the outermost middleware writes at the end
+type fields struct {
+	mu    sync.Mutex
+	entry *logrus.Entry
+}
+
+// NewContext returns a copy of ctx whose lines are written by entry
+func NewContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, &fields{entry: entry})
+}
+
+// With adds f to the lines of ctx and of every context sharing its logger, a context without one is left alone
+func With(ctx context.Context, f logrus.Fields) {
+	if fs, ok := ctx.Value(contextKey{}).(*fields); ok {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		fs.entry = fs.entry.WithFields(f)
+	}
+}
+
+// From returns the logger of ctx, Default when ctx has none
+func From(ctx context.Context) *logrus.Entry {
+	if fs, ok := ctx.Value(contextKey{}).(*fields); ok {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		return fs.entry
+	}
+
+	return logrus.NewEntry(Default())
+}
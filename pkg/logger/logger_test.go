@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+		lines   int
+		json    bool
+	}{
+		{name: "json", options: Options{Format: JSON, Level: "info"}, lines: 2, json: true},
+		{name: "text", options: Options{Format: Text, Level: "info"}, lines: 2},
+		{name: "level filters", options: Options{Format: JSON, Level: "warn"}, lines: 1, json: true},
+		{name: "unknown format", options: Options{Format: "xml", Level: "info"}, wantErr: true},
+		{name: "unknown level", options: Options{Format: JSON, Level: "loud"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			l, err := New(&buf, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			l.Debug("debug")
+			l.Info("info")
+			l.Warn("warn")
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != tt.lines {
+				t.Fatalf("wrote %d lines, want %d: %q", len(lines), tt.lines, lines)
+			}
+			if got := json.Valid([]byte(lines[0])); got != tt.json {
+				t.Errorf("line %q is json = %v, want %v", lines[0], got, tt.json)
+			}
+		})
+	}
+}
+
+func TestRedactor_Format(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		fields  logrus.Fields
+		hidden  string
+	}{
+		{name: "password field", message: "login", fields: logrus.Fields{"password": "hunter2"}, hidden: "hunter2"},
+		{name: "field name containing token", message: "reset", fields: logrus.Fields{"reset_token": "abc123"}, hidden: "abc123"},
+		{name: "basic credentials in message", message: "header Authorization: Basic bWlrZTpodW50ZXIy", hidden: "bWlrZTpodW50ZXIy"},
+		{name: "bearer credentials in field", message: "request", fields: logrus.Fields{"header": "Bearer eyJhbGciOi.x.y"}, hidden: "eyJhbGciOi"},
+		{name: "query secret", message: "request", fields: logrus.Fields{"query": "user=mike&password=hunter2"}, hidden: "hunter2"},
+		{name: "error", message: "request", fields: logrus.Fields{logrus.ErrorKey: errors.New("token=abc123 expired")}, hidden: "abc123"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			l, err := New(&buf, Options{Format: JSON, Level: "info"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			l.WithFields(tt.fields).Info(tt.message)
+			if strings.Contains(buf.String(), tt.hidden) {
+				t.Errorf("line %q shows %q", buf.String(), tt.hidden)
+			}
+			if !strings.Contains(buf.String(), Redacted) {
+				t.Errorf("line %q has nothing redacted", buf.String())
+			}
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	l, err := New(&buf, Options{Format: JSON, Level: "info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), l.WithField("request_id", "1"))
+	inner, cancel := context.WithCancel(ctx)
+	defer cancel()
+	With(inner, logrus.Fields{"username": "mike"})
+	From(ctx).Info("done")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["request_id"] != "1" || line["username"] != "mike" {
+		t.Errorf("line = %v, want request_id 1 and username mike", line)
+	}
+}
+
+func TestFrom_Default(t *testing.T) {
+	t.Parallel()
+	if got := From(context.Background()).Logger; got != Default() {
+		t.Errorf("From() logger = %v, want Default()", got)
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces secrets in log lines
+const Redacted = "[REDACTED]"
+
+// sensitive are parts of field names whose values are never written
+var sensitive = []string{"password", "passwd", "secret", "token", "authorization", "credential", "cookie", "api_key"}
+
+var (
+	// credentials of an Authorization header, e.g. "Basic dXNlcjpwYXNz"
+	schemePattern = regexp.MustCompile(`(?i)\b(basic|bearer)\s+[A-Za-z0-9+/=._~-]+`)
+	// secrets in queries and key value pairs, e.g. "password=hunter2"
+	pairPattern = regexp.MustCompile(`(?i)\b([a-z_]*(?:password|passwd|secret|token)[a-z_]*)=[^\s&]+`)
+)
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitive {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactText(s string) string {
+	s = schemePattern.ReplaceAllString(s, "$1 "+Redacted)
+	return pairPattern.ReplaceAllString(s, "$1="+Redacted)
+}
+
+// redactor hides secrets from the Formatter, values of sensitive fields are dropped, credentials in texts are masked
+type redactor struct {
+	logrus.Formatter
+}
+
+func (r redactor) Format(e *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(e.Data))
+	for key, value := range e.Data {
+		switch v := value.(type) {
+		case string:
+			value = redactText(v)
+		case error:
+			value = redactText(v.Error())
+		}
+		if isSensitive(key) {
+			value = Redacted
+		}
+		data[key] = value
+	}
+	redacted := *e
+	redacted.Data = data
+	redacted.Message = redactText(e.Message)
+
+	return r.Formatter.Format(&redacted)
+}
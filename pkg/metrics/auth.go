@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/middleware/security/basic"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Auth counts the outcome of every authentication of Auth, requests without credentials aren't attempts
+type Auth struct {
+	security.Auth
+	Metrics *Metrics
+}
+
+func (a Auth) GetUser(r *http.Request) (*security.User, error) {
+	user, err := a.Auth.GetUser(r)
+	if !errors.Is(err, security.MissingHeaderErr) {
+		a.Metrics.logins.WithLabelValues(result(err)).Inc()
+	}
+
+	return user, err
+}
+
+// AuthCache registers the counters of cache, they are read from it on every scrape
+func (m *Metrics) AuthCache(cache *basic.Cache) {
+	counter := func(name, help string, value func(s basic.CacheStats) int64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "auth_cache", Name: name, Help: help,
+		}, func() float64 { return float64(value(cache.Snapshot())) })
+	}
+	m.registry.MustRegister(
+		counter("hits_total", "Logins answered from the credential cache.", func(s basic.CacheStats) int64 { return s.Hits }),
+		counter("misses_total", "Logins that had to look the user up.", func(s basic.CacheStats) int64 { return s.Misses }),
+		counter("evictions_total", "Credentials dropped to make room.", func(s basic.CacheStats) int64 { return s.Evictions }),
+	)
+}
+
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, security.WrongPasswordErr), errors.As(err, &repository.EmptyError{}):
+		return "failure"
+	case errors.Is(err, security.LockedErr):
+		return "locked"
+	case errors.Is(err, security.ThrottledErr):
+		return "throttled"
+	default:
+		return "error"
+	}
+}
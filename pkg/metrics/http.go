@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/pattern"
+	"github.com/go-chi/chi/middleware"
+)
+
+// unmatched labels requests no route serves, raw paths would give every scanner its own series
+const unmatched = "unmatched"
+
+// Middleware counts and times the requests by route pattern, it has to run inside a chi router
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			// nothing written means an empty 200
+			status = http.StatusOK
+		}
+		m.observe(r.Method, route(r), status, time.Since(start))
+	})
+}
+
+func route(r *http.Request) string {
+	if p := pattern.Of(r); p != "" {
+		return p
+	}
+
+	return unmatched
+}
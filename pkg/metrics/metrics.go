@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mvp"
+
+// Metrics are the collectors of one process. They live on their own registry instead of the global one,
+// so tests can create as many as they like.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	logins   *prometheus.CounterVec
+	coins    *prometheus.CounterVec
+	sold     *prometheus.CounterVec
+	revenue  *prometheus.CounterVec
+}
+
+// New registers the collectors, the pool stats of db are read on every scrape
+func New(db *sql.DB) *Metrics {
+	labels := []string{"method", "route", "status"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "http", Name: "requests_total",
+			Help: "HTTP requests by method, route pattern and status.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+			Help:    "HTTP request latency by method, route pattern and status.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "auth", Name: "attempts_total",
+			Help: "Authentication attempts by result: success, failure, locked, throttled or error.",
+		}, []string{"result"}),
+		coins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "coins_deposited_total",
+			Help: "Coins deposited by buyers, by denomination.",
+		}, []string{"coin"}),
+		sold: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "units_sold_total",
+			Help: "Units sold by product.",
+		}, []string{"product"}),
+		revenue: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "revenue_total",
+			Help: "Revenue by product, in the unit of the coins.",
+		}, []string{"product"}),
+	}
+	m.registry.MustRegister(
+		m.requests, m.duration, m.logins, m.coins, m.sold, m.revenue,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+	}
+
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Deposited implements vending.Recorder
+func (m *Metrics) Deposited(coins change.Deposit) {
+	for c, count := range coins {
+		if count > 0 {
+			m.coins.WithLabelValues(strconv.Itoa(int(c))).Add(float64(count))
+		}
+	}
+}
+
+// Sold implements vending.Recorder
+func (m *Metrics) Sold(t vending.Transaction) {
+	m.sold.WithLabelValues(t.ProductName).Add(float64(t.Amount))
+	m.revenue.WithLabelValues(t.ProductName).Add(float64(t.Amount * t.Price))
+}
+
+func (m *Metrics) observe(method, route string, status int, took time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.duration.WithLabelValues(method, route, code).Observe(took.Seconds())
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/api/middleware/security/basic"
+	"github.com/artback/mvp/pkg/change"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/vending"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		route  string
+		status string
+	}{
+		{name: "route pattern", method: http.MethodGet, path: "/v1/product/cola", route: "/v1/product/{product_name}", status: "200"},
+		{name: "refused before routing", method: http.MethodDelete, path: "/v1/product/cola", route: "/v1/product/{product_name}", status: "403"},
+		{name: "unknown path", method: http.MethodGet, path: "/wp-login.php", route: unmatched, status: "404"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := New(nil)
+			router := chi.NewRouter()
+			router.Use(m.Middleware, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Method == http.MethodDelete {
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			router.Route("/v1/product", func(r chi.Router) {
+				r.Get("/{product_name}", func(w http.ResponseWriter, r *http.Request) {})
+				r.Delete("/{product_name}", func(w http.ResponseWriter, r *http.Request) {})
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+			if got := testutil.ToFloat64(m.requests.WithLabelValues(tt.method, tt.route, tt.status)); got != 1 {
+				t.Errorf("requests{%s %s %s} = %v, want 1", tt.method, tt.route, tt.status, got)
+			}
+			if got := testutil.CollectAndCount(m.duration); got != 1 {
+				t.Errorf("duration series = %v, want 1", got)
+			}
+		})
+	}
+}
+
+type stubAuth struct {
+	err error
+}
+
+func (s stubAuth) GetUser(_ *http.Request) (*security.User, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &security.User{Username: "mike", Role: security.Buyer}, nil
+}
+
+func TestAuth_GetUser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		result string
+	}{
+		{name: "success", result: "success"},
+		{name: "wrong password", err: security.WrongPasswordErr, result: "failure"},
+		{name: "unknown user", err: repository.EmptyError{}, result: "failure"},
+		{name: "locked", err: security.RetryError{Err: security.LockedErr}, result: "locked"},
+		{name: "throttled", err: security.RetryError{Err: security.ThrottledErr}, result: "throttled"},
+		{name: "database down", err: errors.New("connection refused"), result: "error"},
+		{name: "no credentials", err: security.MissingHeaderErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := New(nil)
+			_, err := Auth{Auth: stubAuth{err: tt.err}, Metrics: m}.GetUser(httptest.NewRequest(http.MethodGet, "/", nil))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("GetUser() error = %v, want %v", err, tt.err)
+			}
+			if tt.result == "" {
+				if got := testutil.CollectAndCount(m.logins); got != 0 {
+					t.Errorf("attempts series = %v, want none", got)
+				}
+				return
+			}
+			if got := testutil.ToFloat64(m.logins.WithLabelValues(tt.result)); got != 1 {
+				t.Errorf("attempts{%s} = %v, want 1", tt.result, got)
+			}
+		})
+	}
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	t.Parallel()
+
+	m := New(nil)
+	m.Deposited(change.Deposit{5: 2, 100: 1, 50: 0})
+	m.Sold(vending.Transaction{ProductName: "cola", Amount: 3, Price: 25})
+	cache, err := basic.NewCache(1, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	m.AuthCache(cache)
+	cache.Get("mike", "secret")
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`mvp_coins_deposited_total{coin="5"} 2`,
+		`mvp_coins_deposited_total{coin="100"} 1`,
+		`mvp_units_sold_total{product="cola"} 3`,
+		`mvp_revenue_total{product="cola"} 75`,
+		`mvp_auth_cache_misses_total 1`,
+		`mvp_auth_cache_hits_total 0`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("Handler() body misses %s", want)
+		}
+	}
+	if strings.Contains(w.Body.String(), `coin="50"`) {
+		t.Errorf("Handler() body counts coins that weren't deposited")
+	}
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Message is addressed to a contact, which is whatever the delivering Notifier understands, e.g. an email address
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+//go:generate mockgen -destination=../../mocks/mock_notifier.go -mock_names=Notifier=Notifier -package=mocks github.com/artback/mvp/pkg/notify Notifier
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Log writes messages to the standard logger, meant for local development only since it prints the body
+type Log struct{}
+
+func (Log) Notify(ctx context.Context, msg Message) error {
+	logger.From(ctx).WithFields(logrus.Fields{"to": msg.To, "subject": msg.Subject}).Info(msg.Body)
+
+	return nil
+}
+
+// File appends messages as JSON lines to Path, a local stand-in for a mailbox
+type File struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (f *File) Notify(_ context.Context, msg Message) error {
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFile_Notify(t *testing.T) {
+	t.Parallel()
+
+	f := &File{Path: filepath.Join(t.TempDir(), "notifications.jsonl")}
+	for _, to := range []string{"mike@example.com", "anna@example.com"} {
+		if err := f.Notify(context.Background(), Message{To: to, Subject: "Password reset", Body: "token"}); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+
+	file, err := os.Open(f.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var got []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("Notify() wrote %q: %v", scanner.Text(), err)
+		}
+		if msg.SentAt.IsZero() {
+			t.Errorf("Notify() message %v has no time", msg)
+		}
+		got = append(got, msg.To)
+	}
+	if len(got) != 2 || got[0] != "mike@example.com" || got[1] != "anna@example.com" {
+		t.Errorf("Notify() appended %v, want both messages in order", got)
+	}
+}
@@ -1,12 +1,11 @@
 package pass
 
-import (
-	"golang.org/x/crypto/bcrypt"
-)
-
+// Compare checks pwd against a hash of any known scheme
 func Compare(hashedPwd string, plainPwd string) bool {
-	if err := bcrypt.CompareHashAndPassword([]byte(hashedPwd), []byte(plainPwd)); err != nil {
-		return false
+	for _, s := range schemes {
+		if s.Owns(hashedPwd) {
+			return s.Compare(hashedPwd, plainPwd)
+		}
 	}
-	return true
+	return false
 }
@@ -1,10 +1,6 @@
 package pass
 
-import (
-	"golang.org/x/crypto/bcrypt"
-)
-
+// HashAndSalt hashes pwd with the DefaultScheme
 func HashAndSalt(pwd string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
-	return string(hash), err
+	return Hasher{}.Hash(pwd)
 }
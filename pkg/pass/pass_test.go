@@ -0,0 +1,107 @@
+package pass
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cheap parameters keep the tests fast
+var (
+	testBcrypt   = Bcrypt{Cost: 4}
+	testArgon2id = Argon2id{Time: 1, Memory: 64, Threads: 1, SaltLength: 8, KeyLength: 16}
+)
+
+func TestHasher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		made         Scheme
+		current      Scheme
+		wantOutdated bool
+	}{
+		{name: "bcrypt current", made: testBcrypt, current: testBcrypt},
+		{name: "bcrypt cost raised", made: testBcrypt, current: Bcrypt{Cost: 5}, wantOutdated: true},
+		{name: "bcrypt to argon2id", made: testBcrypt, current: testArgon2id, wantOutdated: true},
+		{name: "argon2id current", made: testArgon2id, current: testArgon2id},
+		{name: "argon2id memory raised", made: testArgon2id, current: Argon2id{Time: 1, Memory: 128, Threads: 1, SaltLength: 8, KeyLength: 16}, wantOutdated: true},
+		{name: "argon2id to bcrypt", made: testArgon2id, current: testBcrypt, wantOutdated: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			hash, err := Hasher{Scheme: tt.made}.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !Compare(hash, "correct horse") {
+				t.Errorf("Compare() with the password = false, want true")
+			}
+			if Compare(hash, "battery staple") {
+				t.Errorf("Compare() with another password = true, want false")
+			}
+			if got := (Hasher{Scheme: tt.current}).Outdated(hash); got != tt.wantOutdated {
+				t.Errorf("Outdated() = %v, want %v", got, tt.wantOutdated)
+			}
+		})
+	}
+}
+
+func TestCompare_malformed(t *testing.T) {
+	t.Parallel()
+
+	for _, hash := range []string{
+		"", "plain", "$argon2id$v=19$m=64,t=1,p=1$!!$!!", "$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=0$c2FsdA$a2V5", "$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$",
+	} {
+		if Compare(hash, "plain") {
+			t.Errorf("Compare(%q) = true, want false", hash)
+		}
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("# breached\nPassword1\n\nletmein\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blocklist, err := LoadBlocklist(path)
+	if err != nil {
+		t.Fatalf("LoadBlocklist() error = %v", err)
+	}
+	policy := Policy{MinLength: 8, MaxLength: 16, Blocklist: blocklist}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "accepted", password: "correct horse"},
+		{name: "too short", password: "short", wantErr: TooShortErr},
+		{name: "multibyte counted as characters", password: "ääääääää"},
+		{name: "too long", password: "correct horse battery staple", wantErr: TooLongErr},
+		{name: "breached ignoring case", password: "PASSWORD1", wantErr: BreachedErr},
+		{name: "username", password: "mikemike", wantErr: SameAsUsernameErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := policy.Validate("mikemike", tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, WeakPasswordErr) {
+				t.Errorf("Validate() error = %v, want a WeakPasswordErr", err)
+			}
+		})
+	}
+}
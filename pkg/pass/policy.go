@@ -0,0 +1,67 @@
+package pass
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	WeakPasswordErr   = errors.New("password does not meet the policy")
+	TooShortErr       = fmt.Errorf("%w: too short", WeakPasswordErr)
+	TooLongErr        = fmt.Errorf("%w: too long", WeakPasswordErr)
+	BreachedErr       = fmt.Errorf("%w: found in a breach", WeakPasswordErr)
+	SameAsUsernameErr = fmt.Errorf("%w: same as the username", WeakPasswordErr)
+)
+
+// Blocklist holds lower cased passwords known from breaches
+type Blocklist map[string]struct{}
+
+// LoadBlocklist reads one password per line, empty lines and lines starting with # are skipped
+func LoadBlocklist(path string) (Blocklist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	blocklist := make(Blocklist)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		blocklist[strings.ToLower(line)] = struct{}{}
+	}
+
+	return blocklist, scanner.Err()
+}
+
+// Policy is what a new password has to satisfy, the zero Policy only rejects the username as password
+type Policy struct {
+	// MinLength and MaxLength count characters, a zero MaxLength has no limit
+	MinLength int
+	MaxLength int
+	Blocklist Blocklist
+}
+
+func (p Policy) Validate(username, pwd string) error {
+	length := utf8.RuneCountInString(pwd)
+	switch {
+	case length < p.MinLength:
+		return TooShortErr
+	case p.MaxLength > 0 && length > p.MaxLength:
+		return TooLongErr
+	case strings.EqualFold(pwd, username):
+		return SameAsUsernameErr
+	}
+	if _, ok := p.Blocklist[strings.ToLower(pwd)]; ok {
+		return BreachedErr
+	}
+
+	return nil
+}
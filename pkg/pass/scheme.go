@@ -0,0 +1,41 @@
+package pass
+
+import "golang.org/x/crypto/bcrypt"
+
+// Scheme is one way of hashing passwords, the hashes it makes name the scheme and its parameters
+type Scheme interface {
+	Hash(pwd string) (string, error)
+	// Owns reports whether hash was made by this kind of scheme, whatever its parameters
+	Owns(hash string) bool
+	Compare(hash, pwd string) bool
+	// Current reports whether hash was made with exactly the parameters of this scheme
+	Current(hash string) bool
+}
+
+// schemes recognise every hash that could be stored, they read their parameters from the hash
+var schemes = []Scheme{Bcrypt{}, Argon2id{}}
+
+// DefaultScheme is used by a zero Hasher
+var DefaultScheme Scheme = Bcrypt{Cost: bcrypt.DefaultCost}
+
+// Hasher hashes new passwords with Scheme, or DefaultScheme when it is nil
+type Hasher struct {
+	Scheme Scheme
+}
+
+func (h Hasher) scheme() Scheme {
+	if h.Scheme == nil {
+		return DefaultScheme
+	}
+	return h.Scheme
+}
+
+func (h Hasher) Hash(pwd string) (string, error) {
+	return h.scheme().Hash(pwd)
+}
+
+// Outdated reports whether hash should be replaced by a fresh one, because it uses another scheme or other parameters
+func (h Hasher) Outdated(hash string) bool {
+	s := h.scheme()
+	return !s.Owns(hash) || !s.Current(hash)
+}
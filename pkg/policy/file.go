@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/casbin/casbin/v2/model"
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+)
+
+var NoRulesErr = errors.New("policy file has no rules")
+
+// ReloadActor is the audit actor of a policy file reload, no user asks for it
+const ReloadActor = "system"
+
+// File is the policy file operators edit. A reload stores the edits made to the file since it was applied,
+// the rules admins added or removed over the api are left alone.
+type File struct {
+	Model string
+	Path  string
+	// Repository stores the edits, Enforcer serves the stored rules
+	Repository Repository
+	Enforcer   Enforcer
+	// Notifier is nil without other replicas
+	Notifier Notifier
+
+	mu sync.Mutex
+	// applied is the checksum and lines the content of the stored rules came from
+	applied [sha256.Size]byte
+	lines   []Change
+}
+
+// Applied marks the current content of the file as the one the stored rules came from
+func (f *File) Applied() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+	lines, err := f.parse()
+	if err != nil {
+		return err
+	}
+	f.applied, f.lines = sha256.Sum256(content), lines
+
+	return nil
+}
+
+// Reload stores the lines added to and removed from the file since it was applied, together with an audit entry.
+// A line an admin already added or removed over the api is skipped, other stored rules stay as they are.
+// A file that doesn't parse or holds an invalid rule is refused and the current rules stay in place,
+// requests see either the old or the new rules, never a mix of both.
+func (f *File) Reload(ctx context.Context) (changed bool, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256(content)
+	if sum == f.applied {
+		return false, nil
+	}
+
+	lines, err := f.parse()
+	if err != nil {
+		return false, err
+	}
+	// the latest api changes of other replicas are diffed against as well
+	if err := f.Enforcer.LoadPolicy(); err != nil {
+		return false, err
+	}
+	changes, added := f.diff(lines)
+	if len(changes) == 0 {
+		f.applied, f.lines = sum, lines
+		return false, nil
+	}
+
+	entry := admin.Entry{
+		Actor:  ReloadActor,
+		Action: admin.ReloadPolicy,
+		Target: "policy",
+		Reason: "policy file edited",
+		Detail: fmt.Sprintf("%s: %d added, %d removed", f.Path, added, len(changes)-added),
+	}
+	if err := f.Repository.Apply(ctx, entry, changes...); err != nil {
+		return false, err
+	}
+	f.applied, f.lines = sum, lines
+	if err := f.Enforcer.LoadPolicy(); err != nil {
+		return true, err
+	}
+	if f.Notifier != nil {
+		return true, f.Notifier.Update()
+	}
+
+	return true, nil
+}
+
+// diff lists the lines to store: the ones added to the file unless they are stored already, then the ones removed
+// from it unless they are gone already
+func (f *File) diff(lines []Change) (changes []Change, added int) {
+	stored := make(map[string]bool)
+	for _, line := range f.Enforcer.GetPolicy() {
+		stored[key(Change{PType: RuleType, Values: line})] = true
+	}
+	for _, line := range f.Enforcer.GetGroupingPolicy() {
+		stored[key(Change{PType: AssignmentType, Values: line})] = true
+	}
+	before, after := keys(f.lines), keys(lines)
+
+	for _, line := range lines {
+		k := key(line)
+		if !before[k] && !stored[k] {
+			changes = append(changes, line)
+			stored[k] = true
+		}
+	}
+	added = len(changes)
+	for _, line := range f.lines {
+		k := key(line)
+		if !after[k] && stored[k] {
+			line.Remove = true
+			changes = append(changes, line)
+			stored[k] = false
+		}
+	}
+
+	return changes, added
+}
+
+func keys(lines []Change) map[string]bool {
+	set := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		set[key(line)] = true
+	}
+
+	return set
+}
+
+func key(c Change) string {
+	return c.PType + ", " + strings.Join(c.Values, ", ")
+}
+
+// parse loads the lines of the file and validates every one the api would refuse
+func (f *File) parse() ([]Change, error) {
+	m, err := model.NewModelFromFile(f.Model)
+	if err != nil {
+		return nil, err
+	}
+	if err := fileadapter.NewAdapter(f.Path).LoadPolicy(m); err != nil {
+		return nil, err
+	}
+
+	var lines []Change
+	for _, line := range m.GetPolicy("p", RuleType) {
+		if len(line) != 4 {
+			return nil, fmt.Errorf("%w: %v", InvalidRuleErr, line)
+		}
+		rule := Rule{Role: security.Role(line[0]), Path: line[1], Method: line[2], Ownership: line[3]}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", err, line)
+		}
+		lines = append(lines, Change{PType: RuleType, Values: line})
+	}
+	if len(lines) == 0 {
+		return nil, NoRulesErr
+	}
+	if _, ok := m["g"][AssignmentType]; ok {
+		for _, line := range m.GetPolicy("g", AssignmentType) {
+			if len(line) != 2 {
+				return nil, fmt.Errorf("%w: %v", InvalidRuleErr, line)
+			}
+			assignment := Assignment{Role: security.Role(line[0]), Inherits: security.Role(line[1])}
+			if err := assignment.Validate(); err != nil {
+				return nil, fmt.Errorf("%w: %v", err, line)
+			}
+			lines = append(lines, Change{PType: AssignmentType, Values: line})
+		}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,177 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/artback/mvp/pkg/admin"
+)
+
+const modelFile = "../../config/rbac_model.conf"
+
+// store is the repository, the enforcer and the notifier, it keeps the stored lines and counts the updates
+type store struct {
+	lines   map[string]Change
+	entries []admin.Entry
+	updates int
+	err     error
+}
+
+func newStore(lines ...Change) *store {
+	s := &store{lines: make(map[string]Change)}
+	for _, line := range lines {
+		s.lines[key(line)] = line
+	}
+	return s
+}
+
+func (s *store) get(ptype string) [][]string {
+	var lines [][]string
+	for _, line := range s.lines {
+		if line.PType == ptype {
+			lines = append(lines, line.Values)
+		}
+	}
+	return lines
+}
+
+func (s *store) GetPolicy() [][]string         { return s.get(RuleType) }
+func (s *store) GetGroupingPolicy() [][]string { return s.get(AssignmentType) }
+func (s *store) LoadPolicy() error             { return nil }
+
+func (s *store) Update() error {
+	s.updates++
+	return nil
+}
+
+func (s *store) Apply(_ context.Context, entry admin.Entry, changes ...Change) error {
+	if s.err != nil {
+		return s.err
+	}
+	for _, c := range changes {
+		if c.Remove {
+			delete(s.lines, key(c))
+			continue
+		}
+		s.lines[key(c)] = c
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *store) keys() []string {
+	var keys []string
+	for k := range s.lines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func rule(values ...string) Change { return Change{PType: RuleType, Values: values} }
+
+func TestFile_Reload(t *testing.T) {
+	t.Parallel()
+
+	const applied = "p, buyer, ^/v1/deposit$, POST, any\np, buyer, ^/v1/buy$, POST, any\n"
+	deposit, buy := rule("buyer", "^/v1/deposit$", "POST", "any"), rule("buyer", "^/v1/buy$", "POST", "any")
+	product := rule("seller", "^/v1/product$", "POST", "any")
+	// api is a rule an admin added over the api
+	api := rule("seller", "^/v1/alerts$", "GET", "any")
+	failed := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		stored      []Change
+		content     string
+		applyErr    error
+		wantChanged bool
+		wantErr     error
+		want        []Change
+	}{
+		{name: "unchanged", stored: []Change{deposit, buy, api}, content: applied, want: []Change{deposit, buy, api}},
+		{
+			name:    "edited",
+			stored:  []Change{deposit, buy, api},
+			content: applied + "p, seller, ^/v1/product$, POST, any\ng, admin, seller\n", wantChanged: true,
+			want: []Change{deposit, buy, api, product, {PType: AssignmentType, Values: []string{"admin", "seller"}}},
+		},
+		{
+			name:    "line removed",
+			stored:  []Change{deposit, buy, api},
+			content: "p, buyer, ^/v1/deposit$, POST, any\n", wantChanged: true,
+			want: []Change{deposit, api},
+		},
+		{
+			name:    "removed over the api",
+			stored:  []Change{deposit},
+			content: applied + "p, seller, ^/v1/product$, POST, any\n", wantChanged: true,
+			want: []Change{deposit, product},
+		},
+		{
+			name:    "added over the api",
+			stored:  []Change{deposit, buy, product},
+			content: applied + "p, seller, ^/v1/product$, POST, any\n",
+			want:    []Change{deposit, buy, product},
+		},
+		{name: "invalid method", stored: []Change{deposit, buy}, content: "p, buyer, ^/v1/deposit$, TRACE, any\n", wantErr: InvalidRuleErr, want: []Change{deposit, buy}},
+		{name: "invalid path", stored: []Change{deposit, buy}, content: "p, buyer, ^/v1/(deposit$, GET, any\n", wantErr: InvalidRuleErr, want: []Change{deposit, buy}},
+		{name: "missing field", stored: []Change{deposit, buy}, content: "p, buyer, ^/v1/deposit$, GET\n", wantErr: InvalidRuleErr, want: []Change{deposit, buy}},
+		{name: "role inherits itself", stored: []Change{deposit, buy}, content: applied + "g, buyer, buyer\n", wantErr: InvalidRuleErr, want: []Change{deposit, buy}},
+		{name: "no rules", stored: []Change{deposit, buy}, content: "# emptied by accident\n", wantErr: NoRulesErr, want: []Change{deposit, buy}},
+		{name: "store fails", stored: []Change{deposit, buy}, content: "p, seller, ^/v1/product$, POST, any\n", applyErr: failed, wantErr: failed, want: []Change{deposit, buy}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "auth_policy.csv")
+			if err := os.WriteFile(path, []byte(applied), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			s := newStore(tt.stored...)
+			s.err = tt.applyErr
+			f := &File{Model: modelFile, Path: path, Repository: s, Enforcer: s, Notifier: s}
+			if err := f.Applied(); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			changed, err := f.Reload(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reload() error = %v, want %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("Reload() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if got, want := s.keys(), newStore(tt.want...).keys(); !reflect.DeepEqual(got, want) {
+				t.Errorf("stored lines = %v, want %v", got, want)
+			}
+			if !tt.wantChanged {
+				if len(s.entries) != 0 || s.updates != 0 {
+					t.Errorf("Reload() recorded %d entries and notified %d times, want none", len(s.entries), s.updates)
+				}
+				return
+			}
+			if len(s.entries) != 1 || s.entries[0].Action != admin.ReloadPolicy || s.entries[0].Actor != ReloadActor {
+				t.Errorf("Reload() recorded %v, want one reload entry", s.entries)
+			}
+			if s.updates != 1 {
+				t.Errorf("notified %d times, want once", s.updates)
+			}
+
+			// the content is applied now, reloading it again changes nothing
+			if changed, err := f.Reload(context.Background()); changed || err != nil {
+				t.Errorf("second Reload() = %v, %v, want no change", changed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+var InvalidRuleErr = errors.New("invalid policy rule")
+
+const (
+	Any   = "any"
+	Owner = "owner"
+)
+
+// Rule is a casbin p line: Role may call Method on paths matching the Path regex,
+// restricted to owned resources when Ownership is Owner
+type Rule struct {
+	Role      security.Role `json:"role" validate:"required"`
+	Path      string        `json:"path" validate:"required"`
+	Method    string        `json:"method" validate:"required"`
+	Ownership string        `json:"ownership" validate:"oneof=any owner"`
+}
+
+// Assignment is a casbin g line: Role is granted everything Inherits is allowed to do
+type Assignment struct {
+	Role     security.Role `json:"role" validate:"required"`
+	Inherits security.Role `json:"inherits" validate:"required"`
+}
+
+func (r Rule) Validate() error {
+	if r.Role == "" || r.Path == "" {
+		return InvalidRuleErr
+	}
+	if _, err := regexp.Compile(r.Path); err != nil {
+		return InvalidRuleErr
+	}
+
+	switch strings.ToUpper(r.Method) {
+	case "*", "GET", "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return InvalidRuleErr
+	}
+
+	if r.Ownership != Any && r.Ownership != Owner {
+		return InvalidRuleErr
+	}
+
+	return nil
+}
+
+func (r Rule) Values() []string {
+	method := r.Method
+	if method != "*" {
+		method = strings.ToUpper(method)
+	}
+
+	return []string{string(r.Role), r.Path, method, r.Ownership}
+}
+
+func (a Assignment) Validate() error {
+	if a.Role == "" || a.Inherits == "" || a.Role == a.Inherits {
+		return InvalidRuleErr
+	}
+
+	return nil
+}
+
+func (a Assignment) Values() []string {
+	return []string{string(a.Role), string(a.Inherits)}
+}
@@ -0,0 +1,27 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/admin"
+)
+
+// ptypes of the stored lines
+const (
+	RuleType       = "p"
+	AssignmentType = "g"
+)
+
+// Change adds or removes one stored line, a Rule or an Assignment by its Values
+type Change struct {
+	PType  string
+	Values []string
+	Remove bool
+}
+
+//go:generate mockgen -destination=../../mocks/mock_policy_repository.go -mock_names=Repository=PolicyRepository -package=mocks github.com/artback/mvp/pkg/policy Repository
+type Repository interface {
+	// Apply stores every change and records entry in one transaction. Adding a stored line fails with a
+	// repository.DuplicateError, removing a missing one with a repository.EmptyError, and nothing is applied then.
+	Apply(ctx context.Context, entry admin.Entry, changes ...Change) error
+}
@@ -0,0 +1,25 @@
+package policy
+
+import "context"
+
+// Enforcer is the part of the casbin enforcer that serves the stored rules, LoadPolicy swaps them in at once
+type Enforcer interface {
+	GetPolicy() [][]string
+	GetGroupingPolicy() [][]string
+	LoadPolicy() error
+}
+
+// Notifier tells the other replicas to load the policy again
+type Notifier interface {
+	Update() error
+}
+
+//go:generate mockgen -destination=../../mocks/mock_policy_service.go -mock_names=Service=PolicyService -package=mocks github.com/artback/mvp/pkg/policy Service
+type Service interface {
+	Rules(ctx context.Context) ([]Rule, error)
+	AddRule(ctx context.Context, actor string, rule Rule, reason string) error
+	RemoveRule(ctx context.Context, actor string, rule Rule, reason string) error
+	Assignments(ctx context.Context) ([]Assignment, error)
+	AddAssignment(ctx context.Context, actor string, assignment Assignment, reason string) error
+	RemoveAssignment(ctx context.Context, actor string, assignment Assignment, reason string) error
+}
@@ -1,8 +1,8 @@
 package products
 
 type Product struct {
-	Name     string `json:"name"`
+	Name     string `json:"name" validate:"required,max=64"`
 	SellerID string `json:"sellerId"`
-	Price    int    `json:"price"`
-	Amount   int    `json:"amount"`
+	Price    int    `json:"price" validate:"min=1"`
+	Amount   int    `json:"amount" validate:"min=0"`
 }
@@ -0,0 +1,71 @@
+// Package reload applies changed settings to the running service, on SIGHUP or when asked over http
+package reload
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/logger"
+)
+
+// Func applies the settings as they are now, it leaves the running ones in place when the new ones are invalid
+type Func func(ctx context.Context) error
+
+// Reloader runs one reload at a time, however it was asked for
+type Reloader struct {
+	mu     sync.Mutex
+	reload Func
+}
+
+func New(reload Func) *Reloader {
+	return &Reloader{reload: reload}
+}
+
+func (r *Reloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reload(ctx); err != nil {
+		logger.From(ctx).WithError(err).Error("reload refused, the running settings stay in place")
+		return err
+	}
+
+	return nil
+}
+
+// OnHangup reloads on every SIGHUP until ctx is done, it runs as a worker of the service
+func (r *Reloader) OnHangup(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			_ = r.Reload(ctx)
+		}
+	}
+}
+
+// ServeHTTP reloads on POST, refused settings are answered with a reload_failed problem and the reason
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		problem.Write(w, req, problem.MethodNotAllowedErr)
+		return
+	}
+	if err := r.Reload(req.Context()); err != nil {
+		problem.Write(w, req, fmt.Errorf("%w: %v", problem.ReloadFailedErr, err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,121 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/problem"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// quiet keeps refused reloads out of the test output
+func quiet(ctx context.Context) context.Context {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+
+	return logger.NewContext(ctx, logrus.NewEntry(l))
+}
+
+func TestReloader_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		method      string
+		reloadErr   error
+		want        int
+		wantBody    string
+		wantReloads int32
+	}{
+		{name: "reloaded", method: http.MethodPost, want: http.StatusNoContent, wantReloads: 1},
+		{name: "refused", method: http.MethodPost, reloadErr: errors.New("invalid coins: no coins"), want: http.StatusUnprocessableEntity,
+			wantBody: `"code":"reload_failed","detail":"reload refused: invalid coins: no coins"`, wantReloads: 1},
+		{name: "not a post", method: http.MethodGet, want: http.StatusMethodNotAllowed, wantBody: `"code":"method_not_allowed"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var reloads int32
+			r := New(func(context.Context) error {
+				atomic.AddInt32(&reloads, 1)
+				return tt.reloadErr
+			})
+
+			req := httptest.NewRequest(tt.method, "/reload", nil).WithContext(quiet(context.Background()))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusNoContent && w.Header().Get("Content-Type") != problem.ContentType {
+				t.Errorf("ServeHTTP() content type = %q, want %q", w.Header().Get("Content-Type"), problem.ContentType)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("ServeHTTP() body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := atomic.LoadInt32(&reloads); got != tt.wantReloads {
+				t.Errorf("reloaded %d times, want %d", got, tt.wantReloads)
+			}
+		})
+	}
+}
+
+func TestReloader_OnHangup(t *testing.T) {
+	// the test keeps its own handler, a SIGHUP sent before OnHangup listens must not end the test binary
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, syscall.SIGHUP)
+	defer signal.Stop(caught)
+
+	reloaded := make(chan struct{}, 1)
+	r := New(func(context.Context) error {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(quiet(context.Background()))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.OnHangup(ctx)
+	}()
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for waiting := true; waiting; {
+		if err := self.Signal(syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-reloaded:
+			waiting = false
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatal("SIGHUP didn't reload")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("OnHangup didn't return once its context was done")
+	}
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/users"
+)
+
+type AdminRepository struct {
+	*sql.DB
+}
+
+func (a AdminRepository) ListUsers(ctx context.Context, filter admin.Filter) ([]admin.User, error) {
+	list, err := a.listUsers(ctx, filter)
+
+	return list, DomainError(err)
+}
+
+func (a AdminRepository) listUsers(ctx context.Context, filter admin.Filter) ([]admin.User, error) {
+	rows, err := a.QueryContext(ctx, `
+		SELECT username,role,deposit,locked,reset_required FROM users
+		WHERE ($1 = '' OR strpos(lower(username), lower($1)) > 0) AND ($2 = '' OR role = $2)
+		ORDER BY username LIMIT $3 OFFSET $4`,
+		filter.Query, filter.Role, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// to prevent empty slice to be null in json
+	list := make([]admin.User, 0)
+
+	for rows.Next() {
+		var user admin.User
+		if err := rows.Scan(&user.Username, &user.Role, &user.Deposit, &user.Locked, &user.ResetRequired); err != nil {
+			return nil, err
+		}
+
+		list = append(list, user)
+	}
+
+	return list, rows.Err()
+}
+
+func (a AdminRepository) SetRole(ctx context.Context, entry admin.Entry, role security.Role) error {
+	return DomainError(a.withAudit(ctx, &entry, func(tx *sql.Tx) error {
+		return execAffected(ctx, tx, `UPDATE users SET role = $1 WHERE username = $2`, role, entry.Target)
+	}))
+}
+
+func (a AdminRepository) SetLocked(ctx context.Context, entry admin.Entry, locked bool) error {
+	return DomainError(a.withAudit(ctx, &entry, func(tx *sql.Tx) error {
+		return execAffected(ctx, tx, `UPDATE users SET locked = $1 WHERE username = $2`, locked, entry.Target)
+	}))
+}
+
+func (a AdminRepository) RequirePasswordReset(ctx context.Context, entry admin.Entry) error {
+	return DomainError(a.withAudit(ctx, &entry, func(tx *sql.Tx) error {
+		return execAffected(ctx, tx, `UPDATE users SET reset_required = true WHERE username = $1`, entry.Target)
+	}))
+}
+
+func (a AdminRepository) AdjustDeposit(ctx context.Context, entry admin.Entry, amount int) error {
+	return DomainError(a.withAudit(ctx, &entry, func(tx *sql.Tx) error {
+		var deposit int
+		if err := tx.QueryRowContext(ctx,
+			`UPDATE users SET deposit = deposit + $1 WHERE username = $2 RETURNING deposit`, amount, entry.Target,
+		).Scan(&deposit); err != nil {
+			return err
+		}
+		if deposit < 0 {
+			return repository.InvalidError{Title: "deposit can not be negative"}
+		}
+
+		return nil
+	}))
+}
+
+func (a AdminRepository) RoleRequests(ctx context.Context) ([]users.RoleRequest, error) {
+	requests, err := a.roleRequests(ctx)
+
+	return requests, DomainError(err)
+}
+
+func (a AdminRepository) roleRequests(ctx context.Context) ([]users.RoleRequest, error) {
+	rows, err := a.QueryContext(ctx,
+		`SELECT id,username,role,status,created_at FROM role_requests WHERE status = $1 ORDER BY id`, users.Pending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]users.RoleRequest, 0)
+
+	for rows.Next() {
+		var req users.RoleRequest
+		if err := rows.Scan(&req.ID, &req.Username, &req.Role, &req.Status, &req.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+func (a AdminRepository) DecideRoleRequest(ctx context.Context, entry admin.Entry, id int, status users.RoleRequestStatus) (string, error) {
+	err := a.withAudit(ctx, &entry, func(tx *sql.Tx) error {
+		var role security.Role
+		if err := tx.QueryRowContext(ctx, `
+			UPDATE role_requests SET status = $1, decided_by = $2, decided_at = now()
+			WHERE id = $3 AND status = $4 RETURNING username,role`,
+			status, entry.Actor, id, users.Pending,
+		).Scan(&entry.Target, &role); err != nil {
+			return err
+		}
+		if status != users.Approved {
+			return nil
+		}
+
+		return execAffected(ctx, tx, `UPDATE users SET role = $1 WHERE username = $2`, role, entry.Target)
+	})
+
+	return entry.Target, DomainError(err)
+}
+
+func (a AdminRepository) AuditLog(ctx context.Context, target string) ([]admin.Entry, error) {
+	entries, err := a.auditLog(ctx, target)
+
+	return entries, DomainError(err)
+}
+
+func (a AdminRepository) auditLog(ctx context.Context, target string) ([]admin.Entry, error) {
+	rows, err := a.QueryContext(ctx, `
+		SELECT id,actor,action,target,reason,COALESCE(detail,''),created_at FROM audit_log
+		WHERE $1 = '' OR target = $1 ORDER BY id DESC`, target)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]admin.Entry, 0)
+
+	for rows.Next() {
+		var e admin.Entry
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.Target, &e.Reason, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// withAudit runs fn and records entry in one transaction, so an action is never applied without its audit row.
+// entry is read after fn has run, which lets fn fill in fields only known inside the transaction.
+func (a AdminRepository) withAudit(ctx context.Context, entry *admin.Entry, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := a.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO audit_log(actor,action,target,reason,detail) VALUES ($1,$2,$3,$4,NULLIF($5,''))`,
+		entry.Actor, entry.Action, entry.Target, entry.Reason, entry.Detail)
+
+	return err
+}
+
+// execAffected runs query and reports a repository.EmptyError when it changed no row
+func execAffected(ctx context.Context, db execer, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if affected == 0 {
+		return repository.EmptyError{}
+	}
+
+	return err
+}
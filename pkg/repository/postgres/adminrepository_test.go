@@ -0,0 +1,118 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/repository/postgres"
+	"github.com/artback/mvp/pkg/users"
+)
+
+func adminRepository() admin.Repository {
+	return postgres.AdminRepository{DB: db}
+}
+
+func TestAdminRepository_SetLocked(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   admin.Entry
+		wantErr bool
+	}{
+		{
+			name:  "lock existing user",
+			entry: admin.Entry{Actor: "root", Action: admin.Lock, Target: defaultBuyer.Username, Reason: "abuse"},
+		},
+		{
+			name:    "lock non existing user",
+			entry:   admin.Entry{Actor: "root", Action: admin.Lock, Target: "non existing", Reason: "abuse"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := adminRepository()
+			before, _ := repo.AuditLog(ctx, tt.entry.Target)
+			if err := repo.SetLocked(ctx, tt.entry, true); (err != nil) != tt.wantErr {
+				t.Errorf("SetLocked() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			after, err := repo.AuditLog(ctx, tt.entry.Target)
+			if err != nil {
+				t.Fatalf("AuditLog() error = %v", err)
+			}
+			recorded := len(after) - len(before)
+			if tt.wantErr && recorded != 0 || !tt.wantErr && recorded != 1 {
+				t.Errorf("AuditLog() recorded %d entries, wantErr %v", recorded, tt.wantErr)
+			}
+			_ = repo.SetLocked(ctx, admin.Entry{Actor: "root", Action: admin.Unlock, Target: defaultBuyer.Username, Reason: "cleanup"}, false)
+		})
+	}
+}
+
+func TestAdminRepository_AdjustDeposit(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int
+		wantErr bool
+	}{
+		{name: "increase deposit", amount: 50},
+		{name: "decrease below zero", amount: -100000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := admin.Entry{Actor: "root", Action: admin.AdjustDeposit, Target: defaultSeller.Username, Reason: "test"}
+			if err := adminRepository().AdjustDeposit(context.Background(), entry, tt.amount); (err != nil) != tt.wantErr {
+				t.Errorf("AdjustDeposit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminRepository_ListUsers(t *testing.T) {
+	ctx := context.Background()
+	_ = postgres.UserRepository{DB: db}.Insert(ctx, users.User{Username: "listedSeller", Password: "pass", Role: security.Seller})
+	got, err := adminRepository().ListUsers(ctx, admin.Filter{Query: "LISTED", Role: security.Seller, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Username != "listedSeller" {
+		t.Errorf("ListUsers() got = %v, want [listedSeller]", got)
+	}
+}
+
+func TestAdminRepository_DecideRoleRequest(t *testing.T) {
+	ctx := context.Background()
+	userRepo := postgres.UserRepository{DB: db}
+	_ = userRepo.Insert(ctx, users.User{Username: "futureSeller", Password: "pass", Role: security.Buyer})
+	if err := userRepo.RequestRole(ctx, "futureSeller", security.Seller); err != nil {
+		t.Fatalf("RequestRole() error = %v", err)
+	}
+
+	repo := adminRepository()
+	requests, err := repo.RoleRequests(ctx)
+	if err != nil || len(requests) == 0 {
+		t.Fatalf("RoleRequests() got = %v, error = %v", requests, err)
+	}
+	entry := admin.Entry{Actor: "root", Action: admin.ApproveRole, Reason: "verified"}
+	if _, err := repo.DecideRoleRequest(ctx, entry, requests[0].ID, users.Approved); err != nil {
+		t.Fatalf("DecideRoleRequest() error = %v", err)
+	}
+	if _, err := repo.DecideRoleRequest(ctx, entry, requests[0].ID, users.Approved); err == nil {
+		t.Errorf("DecideRoleRequest() on decided request, want error")
+	}
+
+	user, err := userRepo.Get(ctx, requests[0].Username)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if user.Role != requests[0].Role {
+		t.Errorf("Get() role = %v, want %v", user.Role, requests[0].Role)
+	}
+}
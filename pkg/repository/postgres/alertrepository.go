@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artback/mvp/pkg/alert"
+)
+
+// alertsListed bounds the alerts returned for one seller
+const alertsListed = 100
+
+type AlertRepository struct {
+	*sql.DB
+}
+
+func (a AlertRepository) SetThreshold(ctx context.Context, sellerID, product string, threshold int) error {
+	return DomainError(execAffected(ctx, a, `
+		UPDATE inventory i SET reorder_threshold = $1 FROM products p
+		WHERE i.product_name = p.name AND p.name = $2 AND p.seller_id = $3`,
+		threshold, product, sellerID))
+}
+
+func (a AlertRepository) Alerts(ctx context.Context, sellerID string, status alert.Status) ([]alert.Alert, error) {
+	alerts, err := a.alerts(ctx, sellerID, status)
+
+	return alerts, DomainError(err)
+}
+
+func (a AlertRepository) alerts(ctx context.Context, sellerID string, status alert.Status) ([]alert.Alert, error) {
+	rows, err := a.QueryContext(ctx, `
+		SELECT id,product_name,seller_id,kind,remaining,threshold,status,created_at,acknowledged_at FROM stock_alerts
+		WHERE seller_id = $1 AND ($2 = '' OR status = $2) ORDER BY id DESC LIMIT $3`,
+		sellerID, string(status), alertsListed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// to prevent empty slice to be null in json
+	alerts := make([]alert.Alert, 0)
+
+	for rows.Next() {
+		var al alert.Alert
+		if err := rows.Scan(&al.ID, &al.Product, &al.SellerID, &al.Kind, &al.Remaining, &al.Threshold,
+			&al.Status, &al.CreatedAt, &al.AcknowledgedAt); err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, al)
+	}
+
+	return alerts, rows.Err()
+}
+
+func (a AlertRepository) Acknowledge(ctx context.Context, sellerID string, id int) error {
+	return DomainError(execAffected(ctx, a, `
+		UPDATE stock_alerts SET status = $1, acknowledged_at = now() WHERE id = $2 AND seller_id = $3 AND status = $4`,
+		alert.Acknowledged, id, sellerID, alert.Open))
+}
+
+func (a AlertRepository) Unnotified(ctx context.Context, limit int) ([]alert.Notice, error) {
+	notices, err := a.unnotified(ctx, limit)
+
+	return notices, DomainError(err)
+}
+
+// unnotified marks the alerts as told before they are sent, SKIP LOCKED lets every replica send at the same time
+func (a AlertRepository) unnotified(ctx context.Context, limit int) ([]alert.Notice, error) {
+	rows, err := a.QueryContext(ctx, `
+		UPDATE stock_alerts s SET notified_at = now() FROM users u
+		WHERE s.id IN (
+			SELECT id FROM stock_alerts WHERE notified_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+		) AND u.username = s.seller_id
+		RETURNING s.id,s.product_name,s.seller_id,s.kind,s.remaining,s.threshold,s.status,s.created_at,COALESCE(u.contact,'')`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notices []alert.Notice
+
+	for rows.Next() {
+		var n alert.Notice
+		if err := rows.Scan(&n.ID, &n.Product, &n.SellerID, &n.Kind, &n.Remaining, &n.Threshold,
+			&n.Status, &n.CreatedAt, &n.Contact); err != nil {
+			return nil, err
+		}
+
+		notices = append(notices, n)
+	}
+
+	return notices, rows.Err()
+}
+
+func (a AlertRepository) Renotify(ctx context.Context, id int) error {
+	_, err := a.ExecContext(ctx, `UPDATE stock_alerts SET notified_at = NULL WHERE id = $1`, id)
+
+	return DomainError(err)
+}
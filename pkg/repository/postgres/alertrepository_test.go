@@ -0,0 +1,139 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+func TestAlertRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.AlertRepository{DB: db}
+	if _, err := repo.Exec("DELETE FROM stock_alerts"); err != nil {
+		t.Fatal(err)
+	}
+	vend := vendingReposity()
+	if err := repo.SetThreshold(ctx, defaultBuyer.Username, defaultProduct.Name, 99); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("SetThreshold() of another seller error = %v, want EmptyError", err)
+	}
+	if err := repo.SetThreshold(ctx, defaultSeller.Username, defaultProduct.Name, defaultProduct.Amount-1); err != nil {
+		t.Fatal(err)
+	}
+	if err := vend.SetDeposit(ctx, defaultBuyer.Username, 100); err != nil {
+		t.Fatal(err)
+	}
+	// the second purchase stays below the threshold and raises nothing new
+	for i := 0; i < 2; i++ {
+		if _, err := vend.BuyProduct(ctx, defaultBuyer.Username, products.Product{Name: defaultProduct.Name, Amount: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	open, err := repo.Alerts(ctx, defaultSeller.Username, alert.Open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 1 || open[0].Kind != alert.LowStock || open[0].Remaining != defaultProduct.Amount-1 {
+		t.Fatalf("Alerts() = %+v, want one low stock alert", open)
+	}
+
+	notices, err := repo.Unnotified(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notices) != 1 || notices[0].ID != open[0].ID {
+		t.Fatalf("Unnotified() = %+v, want the open alert", notices)
+	}
+	if again, err := repo.Unnotified(ctx, 10); err != nil || len(again) != 0 {
+		t.Errorf("Unnotified() again = %+v, %v, want nothing", again, err)
+	}
+	if err := repo.Renotify(ctx, open[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := repo.Unnotified(ctx, 10); err != nil || len(again) != 1 {
+		t.Errorf("Unnotified() after Renotify = %+v, %v, want the alert", again, err)
+	}
+
+	if err := repo.Acknowledge(ctx, defaultBuyer.Username, open[0].ID); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("Acknowledge() of another seller error = %v, want EmptyError", err)
+	}
+	if err := repo.Acknowledge(ctx, defaultSeller.Username, open[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Acknowledge(ctx, defaultSeller.Username, open[0].ID); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("Acknowledge() twice error = %v, want EmptyError", err)
+	}
+	acknowledged, err := repo.Alerts(ctx, defaultSeller.Username, alert.Acknowledged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acknowledged) != 1 || acknowledged[0].AcknowledgedAt == nil {
+		t.Errorf("Alerts() acknowledged = %+v, want the acknowledged alert", acknowledged)
+	}
+	if err := repo.SetThreshold(ctx, defaultSeller.Username, defaultProduct.Name, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAlertRepository_StockChanges checks that product updates and thresholds raise alerts like purchases do
+func TestAlertRepository_StockChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.AlertRepository{DB: db}
+	productRepo := postgres.ProductRepository{DB: db}
+	if _, err := repo.Exec("DELETE FROM stock_alerts"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = repo.SetThreshold(ctx, defaultSeller.Username, defaultProduct.Name, 0)
+		_ = productRepo.Update(ctx, defaultProduct)
+		_, _ = repo.Exec("DELETE FROM stock_alerts")
+	}()
+	update := func(amount int) func() error {
+		return func() error {
+			product := defaultProduct
+			product.Amount = amount
+			return productRepo.Update(ctx, product)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		change func() error
+		want   []alert.Kind
+	}{
+		{name: "restocked without threshold", change: update(5)},
+		{name: "threshold raised above the stock", change: func() error {
+			return repo.SetThreshold(ctx, defaultSeller.Username, defaultProduct.Name, 5)
+		}, want: []alert.Kind{alert.LowStock}},
+		{name: "updated while low", change: update(3), want: []alert.Kind{alert.LowStock}},
+		{name: "updated to sold out", change: update(0), want: []alert.Kind{alert.LowStock, alert.SoldOut}},
+	}
+
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		open, err := repo.Alerts(ctx, defaultSeller.Username, alert.Open)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kinds []alert.Kind
+		for _, a := range open {
+			kinds = append(kinds, a.Kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+		if !reflect.DeepEqual(kinds, step.want) {
+			t.Errorf("%s: open alerts = %v, want %v", step.name, kinds, step.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/lib/pq"
+)
+
+const credentialChannel = "user_credentials"
+
+// CredentialCache is kept in sync by a CredentialWatcher
+type CredentialCache interface {
+	users.Invalidator
+	// Purge forgets every user, used when notifications may have been missed
+	Purge()
+}
+
+// CredentialWatcher revokes the cached credentials of a changed user on every replica through postgres LISTEN/NOTIFY
+type CredentialWatcher struct {
+	*sql.DB
+	cache    CredentialCache
+	listener *pq.Listener
+}
+
+// NewCredentialWatcher listens on its own connection, database/sql pools can't hold a LISTEN session
+func NewCredentialWatcher(db *sql.DB, connectionString string, cache CredentialCache) (*CredentialWatcher, error) {
+	listener := pq.NewListener(connectionString, time.Second, time.Minute, nil)
+	if err := listener.Listen(credentialChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	w := &CredentialWatcher{DB: db, cache: cache, listener: listener}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *CredentialWatcher) run() {
+	// A nil notification follows a reconnect, any user may have changed meanwhile
+	for n := range w.listener.Notify {
+		if n == nil {
+			w.cache.Purge()
+			continue
+		}
+		w.cache.Invalidate(n.Extra)
+	}
+}
+
+// Invalidate drops the user from the local cache right away and tells the other replicas
+func (w *CredentialWatcher) Invalidate(username string) {
+	w.cache.Invalidate(username)
+	if _, err := w.Exec(`SELECT pg_notify($1, $2)`, credentialChannel, username); err != nil {
+		logger.Default().WithError(err).WithField("invalidated", username).Error("notify replicas")
+	}
+}
+
+func (w *CredentialWatcher) Close() {
+	_ = w.listener.Close()
+}
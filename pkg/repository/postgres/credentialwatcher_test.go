@@ -0,0 +1,48 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+// credentialCache records the usernames it is told to forget
+type credentialCache chan string
+
+func (c credentialCache) Invalidate(username string) {
+	c <- username
+}
+
+func (c credentialCache) Purge() {}
+
+func TestCredentialWatcher_Invalidate(t *testing.T) {
+	local, replica := make(credentialCache, 2), make(credentialCache, 2)
+	watcher, err := postgres.NewCredentialWatcher(db, pgConnection, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	other, err := postgres.NewCredentialWatcher(db, pgConnection, replica)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	watcher.Invalidate("mike")
+
+	if got := <-local; got != "mike" {
+		t.Errorf("Invalidate() local cache forgot %v, want mike", got)
+	}
+	select {
+	case got := <-replica:
+		if got != "mike" {
+			t.Errorf("Invalidate() replica forgot %v, want mike", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Invalidate() did not reach the other replica")
+	}
+}
@@ -2,12 +2,20 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/vending"
 	"github.com/lib/pq"
 )
 
+// SQLSTATE codes raised by the update_inventory trigger, the detail of the error holds its fields as json
+const (
+	outOfStockCode        = "MV001"
+	insufficientFundsCode = "MV002"
+)
+
 // DomainError translates lower sql errors to domain errors.
 func DomainError(err error) error {
 	pqErr, ok := err.(*pq.Error)
@@ -23,7 +31,17 @@ func DomainError(err error) error {
 	switch pqErr.Code {
 	case "23505", "23503":
 		return repository.DuplicateError{Err: pqErr, Constraint: pqErr.Constraint}
-	default:
-		return repository.InvalidError{Title: pqErr.Message}
+	case outOfStockCode:
+		e := vending.OutOfStockError{}
+		if json.Unmarshal([]byte(pqErr.Detail), &e) == nil {
+			return e
+		}
+	case insufficientFundsCode:
+		e := vending.InsufficientFundsError{}
+		if json.Unmarshal([]byte(pqErr.Detail), &e) == nil {
+			return e
+		}
 	}
+
+	return repository.InvalidError{Title: pqErr.Message}
 }
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/logger"
+	"github.com/lib/pq"
+)
+
+const eventChannel = "events"
+
+// EventWatcher relays the events of every replica to the local bus through postgres LISTEN/NOTIFY
+type EventWatcher struct {
+	*sql.DB
+	bus      *events.Bus
+	listener *pq.Listener
+}
+
+// NewEventWatcher listens on its own connection, database/sql pools can't hold a LISTEN session
+func NewEventWatcher(db *sql.DB, connectionString string, bus *events.Bus) (*EventWatcher, error) {
+	listener := pq.NewListener(connectionString, time.Second, time.Minute, nil)
+	if err := listener.Listen(eventChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	w := &EventWatcher{DB: db, bus: bus, listener: listener}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *EventWatcher) run() {
+	// A nil notification follows a reconnect, anything may have changed meanwhile
+	for n := range w.listener.Notify {
+		if n == nil {
+			w.bus.Deliver(events.Event{Type: events.Resync})
+			continue
+		}
+		var e events.Event
+		if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+			logger.Default().WithError(err).WithField("payload", n.Extra).Error("decode event")
+			continue
+		}
+		w.bus.Deliver(e)
+	}
+}
+
+// Publish notifies every replica, this one included, the event comes back through the listener
+func (w *EventWatcher) Publish(ctx context.Context, e events.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := w.ExecContext(ctx, `SELECT pg_notify($1, $2)`, eventChannel, string(payload)); err != nil {
+		return DomainError(err)
+	}
+
+	return nil
+}
+
+func (w *EventWatcher) Close() {
+	_ = w.listener.Close()
+}
@@ -0,0 +1,46 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+func TestEventWatcher_Publish(t *testing.T) {
+	local, replica := events.NewBus(nil), events.NewBus(nil)
+	watcher, err := postgres.NewEventWatcher(db, pgConnection, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	other, err := postgres.NewEventWatcher(db, pgConnection, replica)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	localEvents, replicaEvents := local.Subscribe(), replica.Subscribe()
+	defer localEvents.Close()
+	defer replicaEvents.Close()
+
+	want := events.Event{Type: events.Product, Key: "cola"}
+	if err := watcher.Publish(context.Background(), want); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]*events.Subscription{"local bus": localEvents, "replica": replicaEvents} {
+		select {
+		case <-s.Ready():
+			if got := s.Take(); len(got) != 1 || got[0] != want {
+				t.Errorf("Publish() %s received %v, want %v", name, got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("Publish() did not reach the %s", name)
+		}
+	}
+}
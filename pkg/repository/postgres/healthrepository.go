@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SchemaVersion is the version db/init.sql sets up, raise both together and add a migration to db/migrations
+// whenever the schema changes
+const SchemaVersion = 3
+
+type HealthRepository struct {
+	*sql.DB
+}
+
+func (h HealthRepository) Ping(ctx context.Context) error {
+	return DomainError(h.PingContext(ctx))
+}
+
+func (h HealthRepository) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := h.QueryRowContext(ctx, `SELECT coalesce(max(version), 0) FROM schema_version`).Scan(&version)
+
+	return version, DomainError(err)
+}
@@ -0,0 +1,26 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+func TestHealthRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.HealthRepository{DB: db}
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	version, err := repo.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != postgres.SchemaVersion {
+		t.Errorf("SchemaVersion() = %d, init.sql and postgres.SchemaVersion disagree on %d", version, postgres.SchemaVersion)
+	}
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/lib/pq"
+)
+
+type LockoutRepository struct {
+	*sql.DB
+}
+
+func (l LockoutRepository) Get(ctx context.Context, keys ...string) ([]lockout.State, error) {
+	states, err := l.get(ctx, keys)
+
+	return states, DomainError(err)
+}
+
+func (l LockoutRepository) get(ctx context.Context, keys []string) ([]lockout.State, error) {
+	rows, err := l.QueryContext(ctx,
+		`SELECT key,failures,last_failure FROM login_attempts WHERE key = ANY($1)`, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []lockout.State
+
+	for rows.Next() {
+		var state lockout.State
+		if err := rows.Scan(&state.Key, &state.Failures, &state.LastFailure); err != nil {
+			return nil, err
+		}
+
+		states = append(states, state)
+	}
+
+	return states, rows.Err()
+}
+
+func (l LockoutRepository) Fail(ctx context.Context, key string, now, since time.Time) (lockout.State, error) {
+	state := lockout.State{Key: key}
+	err := l.QueryRowContext(ctx, `
+		INSERT INTO login_attempts(key,failures,last_failure) VALUES ($1,1,$2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure = $2
+		RETURNING failures,last_failure`, key, now, since,
+	).Scan(&state.Failures, &state.LastFailure)
+
+	return state, DomainError(err)
+}
+
+func (l LockoutRepository) Reset(ctx context.Context, key string) error {
+	_, err := l.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+
+	return DomainError(err)
+}
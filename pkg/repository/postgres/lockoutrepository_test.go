@@ -0,0 +1,52 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+func TestLockoutRepository_Fail(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.LockoutRepository{DB: db}
+	key := lockout.UserKey("lockout")
+	defer repo.Reset(ctx, key)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, want := range []int{1, 2, 3} {
+		state, err := repo.Fail(ctx, key, now.Add(time.Duration(i)*time.Second), now.Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+		if state.Failures != want {
+			t.Errorf("Fail() failures = %d, want %d", state.Failures, want)
+		}
+	}
+
+	// failures before since start over
+	state, err := repo.Fail(ctx, key, now.Add(2*time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+	if state.Failures != 1 {
+		t.Errorf("Fail() failures = %d, want 1", state.Failures)
+	}
+
+	states, err := repo.Get(ctx, key, lockout.ClientKey("unknown"))
+	if err != nil || len(states) != 1 {
+		t.Fatalf("Get() = %v, %v, want one state", states, err)
+	}
+
+	if err := repo.Reset(ctx, key); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if states, _ := repo.Get(ctx, key); len(states) != 0 {
+		t.Errorf("Get() after Reset() = %v, want none", states)
+	}
+}
@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+// schemaQueries describe everything db/init.sql sets up, row by row
+var schemaQueries = []string{
+	`SELECT table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable || ' ' || coalesce(column_default, '')
+	FROM information_schema.columns WHERE table_schema = 'public' ORDER BY table_name, ordinal_position`,
+	`SELECT conrelid::regclass || ' ' || conname || ' ' || pg_get_constraintdef(oid)
+	FROM pg_constraint WHERE connamespace = 'public'::regnamespace ORDER BY conrelid::regclass::text, conname`,
+	`SELECT indexdef FROM pg_indexes WHERE schemaname = 'public' ORDER BY indexname`,
+	`SELECT pg_get_triggerdef(oid) FROM pg_trigger WHERE NOT tgisinternal ORDER BY tgname`,
+	`SELECT p.proname || ' ' || p.prosrc FROM pg_proc p WHERE p.pronamespace = 'public'::regnamespace ORDER BY p.proname`,
+}
+
+func schema(t *testing.T, conn *sql.DB) []string {
+	t.Helper()
+
+	var rows []string
+	for _, query := range schemaQueries {
+		result, err := conn.Query(query)
+		if err != nil {
+			t.Fatalf("schema: %v", err)
+		}
+		for result.Next() {
+			var row string
+			if err := result.Scan(&row); err != nil {
+				t.Fatalf("schema: %v", err)
+			}
+			rows = append(rows, row)
+		}
+		if err := result.Close(); err != nil {
+			t.Fatalf("schema: %v", err)
+		}
+	}
+
+	return rows
+}
+
+// database creates an empty database next to db
+func database(t *testing.T, name string) *sql.DB {
+	t.Helper()
+
+	if _, err := db.Exec(`CREATE DATABASE ` + name); err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(pgConnection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Path = name
+	conn, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func apply(ctx context.Context, conn *sql.DB, file string) error {
+	script, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, string(script))
+
+	return err
+}
+
+// TestMigrations takes a database set up from the first db/init.sql through every migration,
+// it has to end up with the schema db is set up with from the current db/init.sql
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	migrated := database(t, "migrated")
+
+	migrations, err := filepath.Glob("../../../db/migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range append([]string{"testdata/baseline.sql"}, migrations...) {
+		if err := apply(ctx, migrated, file); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+
+	version, err := postgres.HealthRepository{DB: migrated}.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("SchemaVersion() error = %v", err)
+	}
+	if version != postgres.SchemaVersion {
+		t.Errorf("SchemaVersion() = %d after the migrations, want %d", version, postgres.SchemaVersion)
+	}
+
+	got := make(map[string]bool)
+	for _, row := range schema(t, migrated) {
+		got[row] = true
+	}
+	for _, row := range schema(t, db) {
+		if !got[row] {
+			t.Errorf("migrated schema misses %s", row)
+		}
+		delete(got, row)
+	}
+	for row := range got {
+		t.Errorf("migrated schema has %s, db/init.sql doesn't", row)
+	}
+}
+
+func TestMigrations_SkippedVersion(t *testing.T) {
+	ctx := context.Background()
+	baseline := database(t, "baseline")
+	if err := apply(ctx, baseline, "testdata/baseline.sql"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := apply(ctx, baseline, "../../../db/migrations/002_purchase_error_codes.sql")
+	if err == nil || !strings.Contains(err.Error(), "apply 001_schema_version.sql first") {
+		t.Errorf("002 on the baseline error = %v, want it to ask for 001 first", err)
+	}
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/artback/mvp/pkg/webhook"
+)
+
+// writeOutbox records an event of the seller in tx and queues a delivery to every endpoint subscribed to its kind,
+// so an event exists exactly when the change it describes was committed
+func writeOutbox(ctx context.Context, tx *sql.Tx, sellerID string, kind webhook.Kind, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		WITH event AS (INSERT INTO outbox(seller_id,kind,data) VALUES ($1,$2,$3) RETURNING id)
+		INSERT INTO webhook_deliveries(endpoint_id,event_id)
+		SELECT e.id, event.id FROM event, webhook_endpoints e
+		WHERE e.seller_id = $1 AND (cardinality(e.events) = 0 OR $2 = ANY(e.events))`,
+		sellerID, string(kind), payload)
+
+	return err
+}
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+// ruleFields is the number of value columns in casbin_rule
+const ruleFields = 6
+
+// PolicyAdapter stores casbin policies in the casbin_rule table
+type PolicyAdapter struct {
+	*sql.DB
+}
+
+func (p PolicyAdapter) LoadPolicy(m model.Model) error {
+	rows, err := p.Query(`SELECT ptype,v0,v1,v2,v3,v4,v5 FROM casbin_rule ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := make([]string, ruleFields+1)
+		if err := rows.Scan(&line[0], &line[1], &line[2], &line[3], &line[4], &line[5], &line[6]); err != nil {
+			return err
+		}
+
+		// Unused trailing columns are stored empty
+		for len(line) > 1 && line[len(line)-1] == "" {
+			line = line[:len(line)-1]
+		}
+		persist.LoadPolicyArray(line, m)
+	}
+
+	return rows.Err()
+}
+
+// SavePolicy replaces every stored rule with the rules of m
+func (p PolicyAdapter) SavePolicy(m model.Model) (err error) {
+	tx, err := p.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM casbin_rule`); err != nil {
+		return err
+	}
+
+	return insertModel(tx, m)
+}
+
+// Seed stores the rules of m when no rule is stored yet and reports whether it did.
+// Replicas starting at once take turns on an advisory lock, only the first one seeds.
+func (p PolicyAdapter) Seed(m model.Model) (seeded bool, err error) {
+	tx, err := p.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('casbin_rule_seed'))`); err != nil {
+		return false, err
+	}
+	if err = tx.QueryRow(`SELECT NOT EXISTS (SELECT 1 FROM casbin_rule)`).Scan(&seeded); err != nil || !seeded {
+		return false, err
+	}
+
+	return true, insertModel(tx, m)
+}
+
+func insertModel(tx *sql.Tx, m model.Model) error {
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			for _, rule := range ast.Policy {
+				if err := insertRule(tx, ptype, rule); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p PolicyAdapter) AddPolicy(_ string, ptype string, rule []string) error {
+	return DomainError(insertRule(p.DB, ptype, rule))
+}
+
+func (p PolicyAdapter) RemovePolicy(_ string, ptype string, rule []string) error {
+	values, err := ruleValues(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.Exec(`DELETE FROM casbin_rule WHERE ptype = $1 AND v0 = $2 AND v1 = $3 AND v2 = $4 AND v3 = $5 AND v4 = $6 AND v5 = $7`,
+		append([]interface{}{ptype}, values...)...)
+
+	return DomainError(err)
+}
+
+func (p PolicyAdapter) RemoveFilteredPolicy(_ string, ptype string, fieldIndex int, fieldValues ...string) error {
+	if fieldIndex < 0 || fieldIndex+len(fieldValues) > ruleFields {
+		return fmt.Errorf("filter out of range: index %d with %d values", fieldIndex, len(fieldValues))
+	}
+
+	query := []string{"ptype = $1"}
+	args := []interface{}{ptype}
+	for i, value := range fieldValues {
+		// Empty filter values match anything
+		if value == "" {
+			continue
+		}
+
+		args = append(args, value)
+		query = append(query, fmt.Sprintf("v%d = $%d", fieldIndex+i, len(args)))
+	}
+
+	_, err := p.Exec(`DELETE FROM casbin_rule WHERE `+strings.Join(query, " AND "), args...)
+
+	return DomainError(err)
+}
+
+// execer is a *sql.DB or a *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertRule(db execer, ptype string, rule []string) error {
+	values, err := ruleValues(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT INTO casbin_rule(ptype,v0,v1,v2,v3,v4,v5) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		append([]interface{}{ptype}, values...)...)
+
+	return err
+}
+
+// ruleValues pads rule to one value per column
+func ruleValues(rule []string) ([]interface{}, error) {
+	if len(rule) > ruleFields {
+		return nil, fmt.Errorf("rule has %d fields, at most %d are supported", len(rule), ruleFields)
+	}
+
+	values := make([]interface{}, ruleFields)
+	for i := range values {
+		values[i] = ""
+		if i < len(rule) {
+			values[i] = rule[i]
+		}
+	}
+
+	return values, nil
+}
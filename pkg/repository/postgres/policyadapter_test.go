@@ -0,0 +1,106 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/repository/postgres"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+)
+
+func policyEnforcer(t *testing.T) *casbin.SyncedEnforcer {
+	e, err := casbin.NewSyncedEnforcer("../../../config/rbac_model.conf", postgres.PolicyAdapter{DB: db})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestPolicyAdapter_RoundTrip(t *testing.T) {
+	e := policyEnforcer(t)
+	if _, err := e.AddPolicy("buyer", "/v1/roundtrip", "GET", "any"); err != nil {
+		t.Fatalf("AddPolicy() error = %v", err)
+	}
+	if _, err := e.AddGroupingPolicy("admin", "buyer"); err != nil {
+		t.Fatalf("AddGroupingPolicy() error = %v", err)
+	}
+
+	reloaded := policyEnforcer(t)
+	if !reloaded.HasPolicy("buyer", "/v1/roundtrip", "GET", "any") {
+		t.Errorf("LoadPolicy() missing added policy, got %v", reloaded.GetPolicy())
+	}
+	if !reloaded.HasGroupingPolicy("admin", "buyer") {
+		t.Errorf("LoadPolicy() missing added role assignment, got %v", reloaded.GetGroupingPolicy())
+	}
+
+	if _, err := e.RemovePolicy("buyer", "/v1/roundtrip", "GET", "any"); err != nil {
+		t.Fatalf("RemovePolicy() error = %v", err)
+	}
+	if _, err := e.RemoveFilteredGroupingPolicy(0, "admin"); err != nil {
+		t.Fatalf("RemoveFilteredGroupingPolicy() error = %v", err)
+	}
+	if err := reloaded.LoadPolicy(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.HasPolicy("buyer", "/v1/roundtrip", "GET", "any") || reloaded.HasGroupingPolicy("admin", "buyer") {
+		t.Errorf("LoadPolicy() still has removed rules")
+	}
+}
+
+func TestPolicyAdapter_Seed(t *testing.T) {
+	e := policyEnforcer(t)
+	if _, err := e.AddPolicy("buyer", "/v1/seeded", "GET", "any"); err != nil {
+		t.Fatal(err)
+	}
+	defer e.RemovePolicy("buyer", "/v1/seeded", "GET", "any")
+
+	m, err := model.NewModelFromFile("../../../config/rbac_model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddPolicy("p", "p", []string{"seller", "/v1/not-seeded", "GET", "any"})
+
+	// replicas starting together all call Seed, only an empty table is seeded
+	seeded := make(chan bool, 3)
+	for i := 0; i < cap(seeded); i++ {
+		go func() {
+			ok, err := postgres.PolicyAdapter{DB: db}.Seed(m)
+			if err != nil {
+				t.Errorf("Seed() error = %v", err)
+			}
+			seeded <- ok
+		}()
+	}
+	for i := 0; i < cap(seeded); i++ {
+		if <-seeded {
+			t.Errorf("Seed() seeded a table that has rules")
+		}
+	}
+	if policyEnforcer(t).HasPolicy("seller", "/v1/not-seeded", "GET", "any") {
+		t.Errorf("Seed() stored its rules next to the existing ones")
+	}
+}
+
+func TestPolicyWatcher_Update(t *testing.T) {
+	watcher, err := postgres.NewPolicyWatcher(db, pgConnection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	notified := make(chan struct{}, 1)
+	_ = watcher.SetUpdateCallback(func(string) { notified <- struct{}{} })
+	if err := watcher.Update(); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Error("Update() did not reach the listener")
+	}
+}
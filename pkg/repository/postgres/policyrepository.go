@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/policy"
+)
+
+// PolicyRepository changes the casbin_rule table the PolicyAdapter loads the enforcer from
+type PolicyRepository struct {
+	*sql.DB
+}
+
+func (p PolicyRepository) Apply(ctx context.Context, entry admin.Entry, changes ...policy.Change) error {
+	return DomainError(AdminRepository{DB: p.DB}.withAudit(ctx, &entry, func(tx *sql.Tx) error {
+		for _, c := range changes {
+			values, err := ruleValues(c.Values)
+			if err != nil {
+				return err
+			}
+			args := append([]interface{}{c.PType}, values...)
+
+			if c.Remove {
+				err = execAffected(ctx, tx,
+					`DELETE FROM casbin_rule WHERE ptype = $1 AND v0 = $2 AND v1 = $3 AND v2 = $4 AND v3 = $5 AND v4 = $6 AND v5 = $7`,
+					args...)
+			} else {
+				_, err = tx.ExecContext(ctx, `INSERT INTO casbin_rule(ptype,v0,v1,v2,v3,v4,v5) VALUES ($1,$2,$3,$4,$5,$6,$7)`, args...)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+}
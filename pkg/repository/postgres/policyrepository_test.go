@@ -0,0 +1,52 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/repository/postgres"
+)
+
+func TestPolicyRepository_Apply(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.PolicyRepository{DB: db}
+	entry := admin.Entry{Actor: "root", Action: admin.AddPolicy, Target: "policy-apply", Reason: "test"}
+	rule := policy.Change{PType: policy.RuleType, Values: []string{"buyer", "/v1/apply", "GET", "any"}}
+	assignment := policy.Change{PType: policy.AssignmentType, Values: []string{"admin", "apply"}}
+
+	if err := repo.Apply(ctx, entry, rule); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// the duplicate rule fails the whole change, the assignment before it isn't stored either
+	if err := repo.Apply(ctx, entry, assignment, rule); !errors.As(err, &repository.DuplicateError{}) {
+		t.Errorf("Apply() duplicate error = %v, want DuplicateError", err)
+	}
+	if err := repo.Apply(ctx, entry, policy.Change{PType: policy.RuleType, Values: []string{"buyer", "/v1/unknown", "GET", "any"}, Remove: true}); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("Apply() unknown rule error = %v, want EmptyError", err)
+	}
+
+	e := policyEnforcer(t)
+	if !e.HasPolicy("buyer", "/v1/apply", "GET", "any") || e.HasGroupingPolicy("admin", "apply") {
+		t.Errorf("stored rules = %v %v, want the first rule only", e.GetPolicy(), e.GetGroupingPolicy())
+	}
+	entries, err := postgres.AdminRepository{DB: db}.AuditLog(ctx, entry.Target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("AuditLog() got %d entries, want only the applied change", len(entries))
+	}
+
+	rule.Remove = true
+	if err := repo.Apply(ctx, entry, rule); err != nil {
+		t.Fatalf("Apply() remove error = %v", err)
+	}
+}
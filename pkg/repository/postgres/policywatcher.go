@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const policyChannel = "casbin_policy"
+
+// PolicyWatcher tells every replica to reload its policy through postgres LISTEN/NOTIFY
+type PolicyWatcher struct {
+	*sql.DB
+	listener *pq.Listener
+
+	mu       sync.Mutex
+	callback func(string)
+}
+
+// NewPolicyWatcher listens on its own connection, database/sql pools can't hold a LISTEN session
+func NewPolicyWatcher(db *sql.DB, connectionString string) (*PolicyWatcher, error) {
+	listener := pq.NewListener(connectionString, time.Second, time.Minute, nil)
+	if err := listener.Listen(policyChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	w := &PolicyWatcher{DB: db, listener: listener}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *PolicyWatcher) run() {
+	// A nil notification follows a reconnect, updates may have been missed so it triggers a reload as well
+	for n := range w.listener.Notify {
+		var payload string
+		if n != nil {
+			payload = n.Extra
+		}
+
+		w.mu.Lock()
+		callback := w.callback
+		w.mu.Unlock()
+
+		if callback != nil {
+			callback(payload)
+		}
+	}
+}
+
+func (w *PolicyWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+
+	return nil
+}
+
+func (w *PolicyWatcher) Update() error {
+	_, err := w.Exec(`SELECT pg_notify($1, '')`, policyChannel)
+
+	return err
+}
+
+func (w *PolicyWatcher) Close() {
+	_ = w.listener.Close()
+}
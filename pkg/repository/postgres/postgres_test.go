@@ -24,6 +24,7 @@ import (
 
 var (
 	db             *sql.DB
+	pgConnection   string
 	defaultBuyer   = users.User{Username: "defaultBuyer", Password: "pass", Role: security.Buyer}
 	defaultSeller  = users.User{Username: "defaultSeller", Password: "pass", Role: security.Seller}
 	defaultProduct = products.Product{Name: "claratin", SellerID: defaultSeller.Username, Price: 5, Amount: 100}
@@ -101,6 +102,8 @@ func startPG() func() error {
 		pgURL.Host = net.JoinHostPort(resource.GetBoundIP("5432/tcp"), resource.GetPort("5432/tcp"))
 	}
 
+	pgConnection = pgURL.String()
+
 	pool.MaxWait = 10 * time.Second
 	err = pool.Retry(func() (err error) {
 		db, err = sql.Open("postgres", pgURL.String())
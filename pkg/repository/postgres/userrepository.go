@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"github.com/artback/mvp/pkg/api/middleware/security"
+	"time"
 
 	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/users"
@@ -21,20 +22,28 @@ func (u UserRepository) Get(ctx context.Context, username string) (*users.User,
 
 func (u UserRepository) get(ctx context.Context, username string) (*users.User, error) {
 	var (
-		password string
-		role     security.Role
-		deposit  int
+		password      string
+		role          security.Role
+		deposit       int
+		locked        bool
+		resetRequired bool
+		contact       string
 	)
 
-	if err := u.QueryRowContext(ctx, `SELECT password, role,deposit FROM users where username = $1`, username).Scan(&password, &role, &deposit); err != nil {
+	if err := u.QueryRowContext(ctx,
+		`SELECT password, role,deposit,locked,reset_required,COALESCE(contact,'') FROM users where username = $1`, username,
+	).Scan(&password, &role, &deposit, &locked, &resetRequired, &contact); err != nil {
 		return nil, err
 	}
 
 	return &users.User{
-		Username: username,
-		Password: password,
-		Role:     role,
-		Deposit:  deposit,
+		Username:      username,
+		Password:      password,
+		Role:          role,
+		Deposit:       deposit,
+		Locked:        locked,
+		ResetRequired: resetRequired,
+		Contact:       contact,
 	}, nil
 }
 
@@ -43,9 +52,39 @@ func (u UserRepository) Insert(ctx context.Context, user users.User) error {
 }
 
 func (u UserRepository) insert(ctx context.Context, user users.User) error {
-	_, err := u.ExecContext(ctx,
-		`INSERT INTO users(username,password,role) VALUES ($1,$2,$3)`,
-		user.Username, user.Password, user.Role,
+	return insertUser(ctx, u.DB, user)
+}
+
+func (u UserRepository) InsertRequestingRole(ctx context.Context, user users.User, role security.Role) error {
+	return DomainError(u.insertRequestingRole(ctx, user, role))
+}
+
+func (u UserRepository) insertRequestingRole(ctx context.Context, user users.User, role security.Role) (err error) {
+	tx, err := u.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	if err = insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO role_requests(username,role) VALUES ($1,$2)`, user.Username, role)
+
+	return err
+}
+
+func insertUser(ctx context.Context, db execer, user users.User) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO users(username,password,role,contact) VALUES ($1,$2,$3,NULLIF($4,''))`,
+		user.Username, user.Password, user.Role, user.Contact,
 	)
 
 	return err
@@ -57,8 +96,10 @@ func (u UserRepository) Update(ctx context.Context, user users.User) error {
 
 func (u UserRepository) update(ctx context.Context, user users.User) error {
 	result, err := u.ExecContext(ctx,
-		`update users set password = COALESCE(NULLIF($1,''),password), role = COALESCE(NULLIF($2,''),role) where username = $3`,
-		user.Password, user.Role, user.Username,
+		`WITH revoked AS (DELETE FROM password_resets WHERE username = $2 AND NULLIF($1,'') IS NOT NULL)
+         update users set password = COALESCE(NULLIF($1,''),password),
+             reset_required = reset_required AND NULLIF($1,'') IS NULL where username = $2`,
+		user.Password, user.Username,
 	)
 	if err != nil {
 		return err
@@ -93,3 +134,97 @@ func (u UserRepository) delete(ctx context.Context, username string) error {
 
 	return err
 }
+
+func (u UserRepository) SetRole(ctx context.Context, username string, role security.Role) error {
+	return DomainError(u.setRole(ctx, username, role))
+}
+
+func (u UserRepository) setRole(ctx context.Context, username string, role security.Role) error {
+	result, err := u.ExecContext(ctx, `update users set role = $1 where username = $2`, role, username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if affected == 0 {
+		return repository.EmptyError{}
+	}
+
+	return err
+}
+
+// RequestRole files a pending role request, a user can only have one pending request at a time
+func (u UserRepository) RequestRole(ctx context.Context, username string, role security.Role) error {
+	_, err := u.ExecContext(ctx, `INSERT INTO role_requests(username,role) VALUES ($1,$2)`, username, role)
+
+	return DomainError(err)
+}
+
+func (u UserRepository) ReplacePasswordHash(ctx context.Context, username, oldHash, newHash string) error {
+	_, err := u.ExecContext(ctx, `update users set password = $1 where username = $2 and password = $3`, newHash, username, oldHash)
+
+	return DomainError(err)
+}
+
+func (u UserRepository) Apply(ctx context.Context, username string, change users.Change) error {
+	return DomainError(u.apply(ctx, username, change))
+}
+
+func (u UserRepository) apply(ctx context.Context, username string, change users.Change) (err error) {
+	tx, err := u.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	if change.PasswordHash != "" {
+		if err = execAffected(ctx, tx, `
+			WITH revoked AS (DELETE FROM password_resets WHERE username = $2)
+			UPDATE users SET password = $1, reset_required = false WHERE username = $2`,
+			change.PasswordHash, username); err != nil {
+			return err
+		}
+	}
+	if change.Contact != nil {
+		err = execAffected(ctx, tx, `UPDATE users SET contact = NULLIF($1,'') WHERE username = $2`, *change.Contact, username)
+	}
+
+	return err
+}
+
+func (u UserRepository) CreateResetToken(ctx context.Context, username, tokenHash string, expires time.Time) error {
+	_, err := u.ExecContext(ctx, `
+		WITH replaced AS (DELETE FROM password_resets WHERE username = $1)
+		INSERT INTO password_resets(token_hash,username,expires_at) VALUES ($2,$1,$3)`, username, tokenHash, expires)
+
+	return DomainError(err)
+}
+
+func (u UserRepository) ResetTokenUser(ctx context.Context, tokenHash string) (string, error) {
+	var username string
+	err := u.QueryRowContext(ctx,
+		`SELECT username FROM password_resets WHERE token_hash = $1 AND expires_at > now()`, tokenHash,
+	).Scan(&username)
+
+	return username, DomainError(err)
+}
+
+func (u UserRepository) RedeemResetToken(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	var username string
+	err := u.QueryRowContext(ctx, `
+		WITH redeemed AS (
+			DELETE FROM password_resets WHERE token_hash = $1 AND expires_at > now() RETURNING username
+		)
+		UPDATE users SET password = $2, reset_required = false FROM redeemed
+		WHERE users.username = redeemed.username RETURNING users.username`, tokenHash, passwordHash,
+	).Scan(&username)
+
+	return username, DomainError(err)
+}
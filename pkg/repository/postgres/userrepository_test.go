@@ -5,11 +5,14 @@ package postgres_test
 
 import (
 	"context"
+	"errors"
 	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/repository/postgres"
 	"github.com/artback/mvp/pkg/users"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func userReposity(fn ...func(r users.Repository)) users.Repository {
@@ -178,3 +181,151 @@ func TestUserRepository_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository_RequestRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		setup    func(r users.Repository)
+		wantErr  bool
+	}{
+		{
+			name:     "request for existing user",
+			username: "applicant",
+			setup: func(r users.Repository) {
+				r.Insert(context.Background(), users.User{Username: "applicant", Password: "pass", Role: security.Buyer})
+			},
+		},
+		{
+			name:     "second pending request",
+			username: "applicant",
+			setup:    func(r users.Repository) {},
+			wantErr:  true,
+		},
+		{
+			name:     "request for non existing user",
+			username: "nonExistingApplicant",
+			setup:    func(r users.Repository) {},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := userReposity(tt.setup).RequestRole(context.Background(), tt.username, security.Seller); (err != nil) != tt.wantErr {
+				t.Errorf("RequestRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserRepository_InsertRequestingRole(t *testing.T) {
+	ctx := context.Background()
+	repo := userReposity()
+	user := users.User{Username: "signedUpSeller", Password: "pass", Role: security.Buyer}
+
+	if err := repo.InsertRequestingRole(ctx, user, security.Seller); err != nil {
+		t.Fatalf("InsertRequestingRole() error = %v", err)
+	}
+	if err := repo.InsertRequestingRole(ctx, user, security.Seller); err == nil {
+		t.Errorf("InsertRequestingRole() of an existing user, want error")
+	}
+	// the pending request was filed with the account
+	if err := repo.RequestRole(ctx, user.Username, security.Seller); err == nil {
+		t.Errorf("RequestRole() next to the filed request, want error")
+	}
+}
+
+func TestUserRepository_Apply(t *testing.T) {
+	ctx := context.Background()
+	repo := userReposity(func(r users.Repository) {
+		r.Insert(ctx, users.User{Username: "patched", Password: "old", Role: security.Buyer})
+	})
+	contact := "patched@example.com"
+
+	// the unknown user of the second change fails it as a whole
+	if err := repo.Apply(ctx, "patched", users.Change{PasswordHash: "new", Contact: &contact}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err := repo.Apply(ctx, "unknown", users.Change{PasswordHash: "newer", Contact: &contact}); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("Apply() of an unknown user error = %v, want EmptyError", err)
+	}
+
+	user, err := repo.Get(ctx, "patched")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if user.Password != "new" || user.Contact != contact {
+		t.Errorf("Apply() stored password %q and contact %q, want the first change only", user.Password, user.Contact)
+	}
+}
+
+func TestUserRepository_ReplacePasswordHash(t *testing.T) {
+	ctx := context.Background()
+	repo := userReposity(func(r users.Repository) {
+		r.Insert(ctx, users.User{Username: "rehashed", Password: "old", Role: security.Buyer})
+	})
+
+	tests := []struct {
+		name    string
+		oldHash string
+		newHash string
+		want    string
+	}{
+		{name: "unchanged password", oldHash: "old", newHash: "new", want: "new"},
+		{name: "password changed meanwhile", oldHash: "old", newHash: "newer", want: "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.ReplacePasswordHash(ctx, "rehashed", tt.oldHash, tt.newHash); err != nil {
+				t.Fatalf("ReplacePasswordHash() error = %v", err)
+			}
+			user, err := repo.Get(ctx, "rehashed")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if user.Password != tt.want {
+				t.Errorf("ReplacePasswordHash() password = %v, want %v", user.Password, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserRepository_RedeemResetToken(t *testing.T) {
+	ctx := context.Background()
+	repo := userReposity(func(r users.Repository) {
+		r.Insert(ctx, users.User{Username: "forgetful", Password: "old", Role: security.Buyer, Contact: "forgetful@example.com"})
+	})
+
+	if err := repo.CreateResetToken(ctx, "forgetful", "expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("CreateResetToken() error = %v", err)
+	}
+	if _, err := repo.ResetTokenUser(ctx, "expired"); err == nil {
+		t.Errorf("ResetTokenUser() of an expired token, want error")
+	}
+	if err := repo.CreateResetToken(ctx, "forgetful", "first", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreateResetToken() error = %v", err)
+	}
+	if err := repo.CreateResetToken(ctx, "forgetful", "second", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreateResetToken() error = %v", err)
+	}
+	if _, err := repo.ResetTokenUser(ctx, "first"); err == nil {
+		t.Errorf("ResetTokenUser() of a replaced token, want error")
+	}
+
+	username, err := repo.RedeemResetToken(ctx, "second", "new")
+	if err != nil || username != "forgetful" {
+		t.Fatalf("RedeemResetToken() = %v, %v, want forgetful", username, err)
+	}
+	if _, err := repo.RedeemResetToken(ctx, "second", "newer"); err == nil {
+		t.Errorf("RedeemResetToken() used twice, want error")
+	}
+	user, err := repo.Get(ctx, "forgetful")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if user.Password != "new" || user.Contact != "forgetful@example.com" {
+		t.Errorf("Get() = %v, want the redeemed password and the contact", user)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/vending"
+	"github.com/artback/mvp/pkg/webhook"
 )
 
 type VendingRepository struct {
@@ -20,10 +21,12 @@ func (v VendingRepository) GetAccount(ctx context.Context, username string) (*ve
 }
 
 func (v VendingRepository) getAccount(ctx context.Context, username string) (*vending.Account, error) {
-	tx, err := v.BeginTx(ctx, nil)
+	// both reads see the same state, the transaction only reads so it is always rolled back
+	tx, err := v.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = tx.Rollback() }()
 
 	// If one product is bought at two different prices they will be returned as separate products in the output
 	rows, err := tx.QueryContext(ctx,
@@ -52,7 +55,7 @@ func (v VendingRepository) getAccount(ctx context.Context, username string) (*ve
 		productRequest = append(productRequest, product)
 	}
 
-	if rows.Err() != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
@@ -89,10 +92,53 @@ func (v VendingRepository) incrementDeposit(ctx context.Context, username string
 	return err
 }
 
-func (v VendingRepository) BuyProduct(ctx context.Context, username string, product products.Product) error {
-	_, err := v.ExecContext(ctx, `INSERT INTO transactions(product_name, username, amount) VALUES ($1,$2,$3)`, product.Name, username, product.Amount)
+func (v VendingRepository) BuyProduct(ctx context.Context, username string, product products.Product) (*vending.Transaction, error) {
+	t, err := v.buyProduct(ctx, username, product)
+
+	return t, DomainError(err)
+}
+
+// buyProduct writes the purchase and the events telling its seller in one transaction
+func (v VendingRepository) buyProduct(ctx context.Context, username string, product products.Product) (_ *vending.Transaction, err error) {
+	tx, err := v.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	sold := webhook.SoldData{Product: product.Name, Amount: product.Amount}
+	// the update_inventory trigger checks stock and deposit and fills in the price
+	if err = tx.QueryRowContext(ctx,
+		`INSERT INTO transactions(product_name, username, amount) VALUES ($1,$2,$3) RETURNING id, price`,
+		product.Name, username, product.Amount,
+	).Scan(&sold.TransactionID, &sold.Price); err != nil {
+		return nil, err
+	}
+
+	var seller string
+	if err = tx.QueryRowContext(ctx, `
+		SELECT seller_id, amount FROM products INNER JOIN inventory i on products.name = i.product_name
+		WHERE name = $1`,
+		product.Name,
+	).Scan(&seller, &sold.Remaining); err != nil {
+		return nil, err
+	}
 
-	return DomainError(err)
+	// the check_stock trigger raises the stock alerts of the purchase when it commits, after this event
+	if err = writeOutbox(ctx, tx, seller, webhook.Sold, sold); err != nil {
+		return nil, err
+	}
+
+	return &vending.Transaction{
+		ID: sold.TransactionID, ProductName: product.Name, Username: username, Amount: product.Amount, Price: sold.Price,
+	}, nil
 }
 
 func (v VendingRepository) SetDeposit(ctx context.Context, username string, deposit int) error {
@@ -112,3 +158,20 @@ func (v VendingRepository) setDeposit(ctx context.Context, username string, depo
 
 	return err
 }
+
+func (v VendingRepository) GetTransaction(ctx context.Context, id int) (*vending.Transaction, error) {
+	t, err := v.getTransaction(ctx, id)
+
+	return t, DomainError(err)
+}
+
+func (v VendingRepository) getTransaction(ctx context.Context, id int) (*vending.Transaction, error) {
+	t := vending.Transaction{}
+	if err := v.QueryRowContext(ctx,
+		`SELECT id,product_name,username,amount,price FROM transactions WHERE id = $1`, id,
+	).Scan(&t.ID, &t.ProductName, &t.Username, &t.Amount, &t.Price); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -5,6 +5,7 @@ package postgres_test
 
 import (
 	"context"
+	"errors"
 	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/repository/postgres"
 	"github.com/artback/mvp/pkg/vending"
@@ -41,6 +42,7 @@ func TestVendingRepository_BuyProduct(t *testing.T) {
 		args    args
 		setup   func(r vending.Repository)
 		wantErr bool
+		want    error
 	}{
 		{
 			name: "buy existing product with existing user without deposit",
@@ -52,6 +54,21 @@ func TestVendingRepository_BuyProduct(t *testing.T) {
 				}
 			},
 			wantErr: true,
+			want:    vending.InsufficientFundsError{Cost: 500, Deposit: 0},
+		},
+		{
+			name: "buy more than the inventory",
+			args: args{username: defaultBuyer.Username, product: products.Product{
+				Name: defaultProduct.Name, Amount: 101,
+			}},
+			setup: func(r vending.Repository) {
+				err := r.SetDeposit(context.Background(), defaultBuyer.Username, 1000)
+				if err != nil {
+					t.Error(err)
+				}
+			},
+			wantErr: true,
+			want:    vending.OutOfStockError{Product: defaultProduct.Name, Requested: 101, Available: 100},
 		},
 		{
 			name: "buy existing product with existing user with deposit",
@@ -88,9 +105,13 @@ func TestVendingRepository_BuyProduct(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			if err := vendingReposity(tt.setup).BuyProduct(ctx, tt.args.username, tt.args.product); (err != nil) != tt.wantErr {
+			_, err := vendingReposity(tt.setup).BuyProduct(ctx, tt.args.username, tt.args.product)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("BuyProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("BuyProduct() error = %#v, want %#v", err, tt.want)
+			}
 		})
 	}
 }
@@ -134,12 +155,12 @@ func TestVendingRepository_GetAccount(t *testing.T) {
 				if err := r.SetDeposit(ctx, defaultBuyer.Username, 100); err != nil {
 					t.Error(err)
 				}
-				if err := r.BuyProduct(ctx, defaultBuyer.Username, products.Product{
+				if _, err := r.BuyProduct(ctx, defaultBuyer.Username, products.Product{
 					Name: defaultProduct.Name, Amount: 5,
 				}); err != nil {
 					t.Error(err)
 				}
-				if err := r.BuyProduct(ctx, defaultBuyer.Username, products.Product{
+				if _, err := r.BuyProduct(ctx, defaultBuyer.Username, products.Product{
 					Name: defaultProduct.Name, Amount: 5,
 				}); err != nil {
 					t.Error(err)
@@ -309,3 +330,31 @@ func TestVendingRepository_SetDeposit(t *testing.T) {
 		})
 	}
 }
+
+func TestVendingRepository_GetTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := vendingReposity(func(r vending.Repository) {
+		if err := r.SetDeposit(ctx, defaultBuyer.Username, 100); err != nil {
+			t.Error(err)
+		}
+		if _, err := r.BuyProduct(ctx, defaultBuyer.Username, products.Product{Name: defaultProduct.Name, Amount: 2}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	var id int
+	if err := db.QueryRow(`SELECT max(id) FROM transactions`).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetTransaction(ctx, id)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	want := &vending.Transaction{ID: id, ProductName: defaultProduct.Name, Username: defaultBuyer.Username, Amount: 2, Price: defaultProduct.Price}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTransaction() got = %v, want %v", got, want)
+	}
+	if _, err := repo.GetTransaction(ctx, -1); err == nil {
+		t.Errorf("GetTransaction() on missing id, want error")
+	}
+}
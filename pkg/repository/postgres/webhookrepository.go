@@ -0,0 +1,243 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/lib/pq"
+)
+
+// deliveriesListed bounds the history returned for one endpoint
+const deliveriesListed = 100
+
+type WebhookRepository struct {
+	*sql.DB
+}
+
+func (w WebhookRepository) AddEndpoint(ctx context.Context, endpoint webhook.Endpoint) (*webhook.Endpoint, error) {
+	err := w.QueryRowContext(ctx,
+		`INSERT INTO webhook_endpoints(seller_id,url,events,secret) VALUES ($1,$2,$3,$4) RETURNING id,created_at`,
+		endpoint.SellerID, endpoint.URL, pq.Array(kindStrings(endpoint.Events)), endpoint.Secret,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt)
+	if err != nil {
+		return nil, DomainError(err)
+	}
+
+	return &endpoint, nil
+}
+
+func (w WebhookRepository) Endpoints(ctx context.Context, sellerID string) ([]webhook.Endpoint, error) {
+	endpoints, err := w.endpoints(ctx, sellerID)
+
+	return endpoints, DomainError(err)
+}
+
+func (w WebhookRepository) endpoints(ctx context.Context, sellerID string) ([]webhook.Endpoint, error) {
+	rows, err := w.QueryContext(ctx,
+		`SELECT id,seller_id,url,events,created_at FROM webhook_endpoints WHERE seller_id = $1 ORDER BY id`, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// to prevent empty slice to be null in json
+	endpoints := make([]webhook.Endpoint, 0)
+
+	for rows.Next() {
+		var (
+			e      webhook.Endpoint
+			events pq.StringArray
+		)
+		if err := rows.Scan(&e.ID, &e.SellerID, &e.URL, &events, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		e.Events = make([]webhook.Kind, 0, len(events))
+		for _, kind := range events {
+			e.Events = append(e.Events, webhook.Kind(kind))
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	return endpoints, rows.Err()
+}
+
+func (w WebhookRepository) DeleteEndpoint(ctx context.Context, sellerID string, id int) error {
+	return DomainError(execAffected(ctx, w, `DELETE FROM webhook_endpoints WHERE id = $1 AND seller_id = $2`, id, sellerID))
+}
+
+func (w WebhookRepository) Deliveries(ctx context.Context, sellerID string, endpointID int, status webhook.Status) ([]webhook.Delivery, error) {
+	deliveries, err := w.deliveries(ctx, sellerID, endpointID, status)
+
+	return deliveries, DomainError(err)
+}
+
+func (w WebhookRepository) deliveries(ctx context.Context, sellerID string, endpointID int, status webhook.Status) ([]webhook.Delivery, error) {
+	// an endpoint of another seller is as missing as one that doesn't exist
+	var exists int
+	if err := w.QueryRowContext(ctx,
+		`SELECT 1 FROM webhook_endpoints WHERE id = $1 AND seller_id = $2`, endpointID, sellerID,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	rows, err := w.QueryContext(ctx, `
+		SELECT d.id,d.endpoint_id,d.status,d.next_attempt_at,o.id,o.seller_id,o.kind,o.data,o.created_at
+		FROM webhook_deliveries d INNER JOIN outbox o ON o.id = d.event_id
+		WHERE d.endpoint_id = $1 AND ($2 = '' OR d.status = $2) ORDER BY d.id DESC LIMIT $3`,
+		endpointID, string(status), deliveriesListed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		deliveries = make([]webhook.Delivery, 0)
+		ids        []int64
+	)
+
+	for rows.Next() {
+		var (
+			d    webhook.Delivery
+			next time.Time
+		)
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.Status, &next,
+			&d.Event.ID, &d.Event.SellerID, &d.Event.Kind, &d.Event.Data, &d.Event.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if d.Status == webhook.Pending {
+			d.NextAttempt = &next
+		}
+		d.Attempts = make([]webhook.Attempt, 0)
+		deliveries = append(deliveries, d)
+		ids = append(ids, int64(d.ID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, w.attempts(ctx, ids, deliveries)
+}
+
+// attempts adds the attempts of the deliveries with the given ids, oldest first
+func (w WebhookRepository) attempts(ctx context.Context, ids []int64, deliveries []webhook.Delivery) error {
+	rows, err := w.QueryContext(ctx, `
+		SELECT delivery_id,COALESCE(status_code,0),COALESCE(error,''),duration_ms,attempted_at
+		FROM webhook_attempts WHERE delivery_id = ANY($1) ORDER BY id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int, len(deliveries))
+	for i, d := range deliveries {
+		index[d.ID] = i
+	}
+
+	for rows.Next() {
+		var (
+			id int
+			a  webhook.Attempt
+		)
+		if err := rows.Scan(&id, &a.StatusCode, &a.Error, &a.Duration, &a.AttemptedAt); err != nil {
+			return err
+		}
+
+		d := &deliveries[index[id]]
+		d.Attempts = append(d.Attempts, a)
+	}
+
+	return rows.Err()
+}
+
+func (w WebhookRepository) Redeliver(ctx context.Context, sellerID string, id int) error {
+	return DomainError(execAffected(ctx, w, `
+		UPDATE webhook_deliveries d SET status = $1, attempts = 0, next_attempt_at = now()
+		FROM webhook_endpoints e
+		WHERE d.endpoint_id = e.id AND e.seller_id = $2 AND d.id = $3 AND d.status = $4`,
+		webhook.Pending, sellerID, id, webhook.Dead))
+}
+
+func (w WebhookRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]webhook.Job, error) {
+	jobs, err := w.claim(ctx, limit, lease)
+
+	return jobs, DomainError(err)
+}
+
+// claim moves the due deliveries to the end of the lease, a dispatcher that dies while sending leaves them due again.
+// SKIP LOCKED lets every replica claim at the same time without waiting for each other.
+func (w WebhookRepository) claim(ctx context.Context, limit int, lease time.Duration) ([]webhook.Job, error) {
+	rows, err := w.QueryContext(ctx, `
+		UPDATE webhook_deliveries d SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM webhook_endpoints e, outbox o
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries WHERE status = $3 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+		) AND e.id = d.endpoint_id AND o.id = d.event_id
+		RETURNING d.id,d.attempts,o.id,o.seller_id,o.kind,o.data,o.created_at,e.url,e.secret`,
+		limit, lease.Milliseconds(), webhook.Pending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []webhook.Job
+
+	for rows.Next() {
+		var j webhook.Job
+		if err := rows.Scan(&j.DeliveryID, &j.Attempts,
+			&j.Event.ID, &j.Event.SellerID, &j.Event.Kind, &j.Event.Data, &j.Event.CreatedAt, &j.URL, &j.Secret); err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (w WebhookRepository) Record(ctx context.Context, deliveryID int, attempt webhook.Attempt, status webhook.Status, next time.Time) error {
+	return DomainError(w.record(ctx, deliveryID, attempt, status, next))
+}
+
+func (w WebhookRepository) record(ctx context.Context, deliveryID int, attempt webhook.Attempt, status webhook.Status, next time.Time) (err error) {
+	tx, err := w.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_attempts(delivery_id,status_code,error,duration_ms,attempted_at)
+		VALUES ($1,NULLIF($2,0),NULLIF($3,''),$4,$5)`,
+		deliveryID, attempt.StatusCode, attempt.Error, attempt.Duration, attempt.AttemptedAt); err != nil {
+		return err
+	}
+
+	if next.IsZero() {
+		next = attempt.AttemptedAt
+	}
+
+	return execAffected(ctx, tx,
+		`UPDATE webhook_deliveries SET status = $1, attempts = attempts + 1, next_attempt_at = $2 WHERE id = $3`,
+		status, next, deliveryID)
+}
+
+func kindStrings(kinds []webhook.Kind) []string {
+	s := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		s = append(s, string(kind))
+	}
+
+	return s
+}
@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/repository/postgres"
+	"github.com/artback/mvp/pkg/webhook"
+)
+
+func TestWebhookRepository_Outbox(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.WebhookRepository{DB: db}
+	if _, err := repo.Exec("DELETE FROM webhook_endpoints; DELETE FROM outbox"); err != nil {
+		t.Fatal(err)
+	}
+	endpoint, err := repo.AddEndpoint(ctx, webhook.Endpoint{
+		SellerID: defaultSeller.Username, URL: "http://localhost/hook", Events: []webhook.Kind{webhook.Sold}, Secret: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vend := vendingReposity()
+	if err := vend.SetDeposit(ctx, defaultBuyer.Username, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vend.BuyProduct(ctx, defaultBuyer.Username, products.Product{Name: defaultProduct.Name, Amount: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := repo.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Event.Kind != webhook.Sold || jobs[0].URL != endpoint.URL || jobs[0].Secret != "secret" {
+		t.Fatalf("Claim() = %+v, want the sold event for %s", jobs, endpoint.URL)
+	}
+	var sold webhook.SoldData
+	if err := json.Unmarshal(jobs[0].Event.Data, &sold); err != nil || sold.Amount != 2 || sold.Price != defaultProduct.Price {
+		t.Errorf("Claim() event data = %s, want 2 sold at %d", jobs[0].Event.Data, defaultProduct.Price)
+	}
+	if again, err := repo.Claim(ctx, 10, time.Minute); err != nil || len(again) != 0 {
+		t.Errorf("Claim() during the lease = %+v, %v, want nothing", again, err)
+	}
+
+	attempt := webhook.Attempt{StatusCode: 500, Error: "unexpected status 500", Duration: 3, AttemptedAt: time.Now()}
+	if err := repo.Record(ctx, jobs[0].DeliveryID, attempt, webhook.Dead, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	dead, err := repo.Deliveries(ctx, defaultSeller.Username, endpoint.ID, webhook.Dead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || len(dead[0].Attempts) != 1 || dead[0].Attempts[0].StatusCode != 500 {
+		t.Errorf("Deliveries() = %+v, want the dead delivery with its attempt", dead)
+	}
+
+	if _, err := repo.Deliveries(ctx, defaultBuyer.Username, endpoint.ID, ""); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("Deliveries() of another seller error = %v, want EmptyError", err)
+	}
+	if err := repo.Redeliver(ctx, defaultSeller.Username, jobs[0].DeliveryID); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := repo.Claim(ctx, 10, time.Minute); err != nil || len(again) != 1 || again[0].Attempts != 0 {
+		t.Errorf("Claim() after Redeliver = %+v, %v, want the delivery with fresh attempts", again, err)
+	}
+	if err := repo.DeleteEndpoint(ctx, defaultSeller.Username, endpoint.ID); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+// the tests replace the global tracer provider, so none of them runs in parallel
+
+func TestMiddleware(t *testing.T) {
+	if _, err := Setup(context.Background(), Options{Exporter: None}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantStatus  codes.Code
+	}{
+		{name: "continues the trace of the caller", path: "/v1/product/cola", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantName: "GET /v1/product/{product_name}"},
+		{name: "server error", path: "/v1/product/broken", wantName: "GET /v1/product/{product_name}", wantStatus: codes.Error},
+		{name: "unknown path", path: "/wp-login.php", wantName: "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(Middleware)
+			router.Get("/v1/product/{product_name}", func(w http.ResponseWriter, r *http.Request) {
+				if chi.URLParam(r, "product_name") == "broken" {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			})
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			span := spans[len(spans)-1]
+			if span.Name() != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name(), tt.wantName)
+			}
+			if span.Status().Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status().Code, tt.wantStatus)
+			}
+			if tt.traceparent != "" && span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("span trace = %v, want the trace of the traceparent header", span.Parent().TraceID())
+			}
+			if tt.traceparent == "" && span.Parent().IsValid() {
+				t.Errorf("span parent = %v, want a new trace", span.Parent())
+			}
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "fails")
+	End(span, errors.New("db down"))
+	_, span = tracer.Start(context.Background(), "succeeds")
+	End(span, nil)
+
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("ended spans = %v, want 2", len(ended))
+	}
+	if ended[0].Status().Code != codes.Error || len(ended[0].Events()) != 1 {
+		t.Errorf("failed span status = %v with %v events, want an error with the recorded error", ended[0].Status(), len(ended[0].Events()))
+	}
+	if ended[1].Status().Code != codes.Unset {
+		t.Errorf("succeeded span status = %v, want unset", ended[1].Status())
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), Options{Service: "mvp", Exporter: File, Path: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "written")
+	span.SetAttributes(semconv.HTTPRouteKey.String("/v1/deposit"))
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), `"Name":"written"`) {
+		t.Errorf("file = %s, want the span", written)
+	}
+}
+
+func TestSetup_Unknown(t *testing.T) {
+	if _, err := Setup(context.Background(), Options{Exporter: "jaeger"}); err == nil {
+		t.Error("Setup() error = nil, want the unknown exporter refused")
+	}
+}
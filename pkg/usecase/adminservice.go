@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/tracing"
+	"github.com/artback/mvp/pkg/users"
+)
+
+const (
+	defaultUserLimit = 50
+	maxUserLimit     = 500
+)
+
+type AdminService struct {
+	admin.Repository
+	// Lockout is reset on unlock so temporary lockouts are lifted too, nil when logins aren't throttled
+	Lockout lockout.Service
+	// Invalidator is told about changed users, nil when nothing is cached
+	Invalidator users.Invalidator
+	// Events is told about adjusted deposits, nil when nobody listens
+	Events events.Publisher
+}
+
+// invalidate tells the Invalidator about username once the change succeeded and passes err through
+func (a AdminService) invalidate(username string, err error) error {
+	if err == nil && a.Invalidator != nil {
+		a.Invalidator.Invalidate(username)
+	}
+	return err
+}
+
+func (a AdminService) ListUsers(ctx context.Context, filter admin.Filter) (_ []admin.User, err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.ListUsers")
+	defer func() { tracing.End(span, err) }()
+
+	if filter.Role != "" && !filter.Role.Valid() {
+		return nil, users.InvalidRoleErr
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserLimit
+	}
+	if filter.Limit > maxUserLimit {
+		filter.Limit = maxUserLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return a.Repository.ListUsers(ctx, filter)
+}
+
+func (a AdminService) SetRole(ctx context.Context, actor, username string, role security.Role, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.SetRole")
+	defer func() { tracing.End(span, err) }()
+
+	if !role.Valid() {
+		return users.InvalidRoleErr
+	}
+	entry, err := newEntry(actor, admin.ChangeRole, username, reason)
+	if err != nil {
+		return err
+	}
+	entry.Detail = fmt.Sprintf("role=%s", role)
+
+	return a.invalidate(username, a.Repository.SetRole(ctx, entry, role))
+}
+
+func (a AdminService) Lock(ctx context.Context, actor, username, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.Lock")
+	defer func() { tracing.End(span, err) }()
+
+	entry, err := newEntry(actor, admin.Lock, username, reason)
+	if err != nil {
+		return err
+	}
+
+	return a.invalidate(username, a.Repository.SetLocked(ctx, entry, true))
+}
+
+func (a AdminService) Unlock(ctx context.Context, actor, username, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.Unlock")
+	defer func() { tracing.End(span, err) }()
+
+	entry, err := newEntry(actor, admin.Unlock, username, reason)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Repository.SetLocked(ctx, entry, false); err != nil {
+		return err
+	}
+	if a.Lockout == nil {
+		return nil
+	}
+
+	return a.Lockout.Reset(ctx, username)
+}
+
+func (a AdminService) ForcePasswordReset(ctx context.Context, actor, username, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.ForcePasswordReset")
+	defer func() { tracing.End(span, err) }()
+
+	entry, err := newEntry(actor, admin.ForcePasswordReset, username, reason)
+	if err != nil {
+		return err
+	}
+
+	return a.invalidate(username, a.Repository.RequirePasswordReset(ctx, entry))
+}
+
+func (a AdminService) AdjustDeposit(ctx context.Context, actor, username string, amount int, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.AdjustDeposit")
+	defer func() { tracing.End(span, err) }()
+
+	entry, err := newEntry(actor, admin.AdjustDeposit, username, reason)
+	if err != nil {
+		return err
+	}
+	entry.Detail = fmt.Sprintf("amount=%+d", amount)
+
+	if err := a.Repository.AdjustDeposit(ctx, entry, amount); err != nil {
+		return err
+	}
+	events.Publish(ctx, a.Events, events.Event{Type: events.Deposit, Key: username})
+
+	return nil
+}
+
+func (a AdminService) ApproveRoleRequest(ctx context.Context, actor string, id int, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.ApproveRoleRequest")
+	defer func() { tracing.End(span, err) }()
+
+	return a.decideRoleRequest(ctx, actor, admin.ApproveRole, id, users.Approved, reason)
+}
+
+func (a AdminService) RejectRoleRequest(ctx context.Context, actor string, id int, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.RejectRoleRequest")
+	defer func() { tracing.End(span, err) }()
+
+	return a.decideRoleRequest(ctx, actor, admin.RejectRole, id, users.Rejected, reason)
+}
+
+func (a AdminService) decideRoleRequest(ctx context.Context, actor string, action admin.Action, id int, status users.RoleRequestStatus, reason string) error {
+	// The target is only known once the repository has loaded the request
+	entry, err := newEntry(actor, action, "", reason)
+	if err != nil {
+		return err
+	}
+	entry.Detail = fmt.Sprintf("request=%d", id)
+
+	username, err := a.Repository.DecideRoleRequest(ctx, entry, id, status)
+
+	return a.invalidate(username, err)
+}
+
+// newEntry validates the parts every admin action shares
+func newEntry(actor string, action admin.Action, target, reason string) (admin.Entry, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return admin.Entry{}, admin.MissingReasonErr
+	}
+	if actor == target {
+		return admin.Entry{}, admin.SelfActionErr
+	}
+
+	return admin.Entry{Actor: actor, Action: action, Target: target, Reason: reason}, nil
+}
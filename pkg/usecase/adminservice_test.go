@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/users"
+	"github.com/golang/mock/gomock"
+)
+
+func TestAdminService_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		filter  admin.Filter
+		want    admin.Filter
+		times   int
+		wantErr error
+	}{
+		{
+			name:   "default limit",
+			filter: admin.Filter{Query: "mi"},
+			want:   admin.Filter{Query: "mi", Limit: defaultUserLimit},
+			times:  1,
+		},
+		{
+			name:   "limit capped",
+			filter: admin.Filter{Limit: 10000, Offset: -1},
+			want:   admin.Filter{Limit: maxUserLimit},
+			times:  1,
+		},
+		{
+			name:    "unknown role",
+			filter:  admin.Filter{Role: "superuser"},
+			wantErr: users.InvalidRoleErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewAdminRepository(mockCtrl)
+			r.EXPECT().ListUsers(gomock.Any(), tt.want).Return([]admin.User{}, nil).Times(tt.times)
+			a := AdminService{Repository: r}
+			_, err := a.ListUsers(context.Background(), tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListUsers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminService_SetRole(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		actor    string
+		username string
+		role     security.Role
+		reason   string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    admin.Entry
+		times   int
+		wantErr error
+	}{
+		{
+			name:  "successful",
+			args:  args{actor: "root", username: "mike", role: security.Seller, reason: " approved "},
+			want:  admin.Entry{Actor: "root", Action: admin.ChangeRole, Target: "mike", Reason: "approved", Detail: "role=seller"},
+			times: 1,
+		},
+		{
+			name:    "missing reason",
+			args:    args{actor: "root", username: "mike", role: security.Seller},
+			wantErr: admin.MissingReasonErr,
+		},
+		{
+			name:    "unknown role",
+			args:    args{actor: "root", username: "mike", role: "root", reason: "because"},
+			wantErr: users.InvalidRoleErr,
+		},
+		{
+			name:    "own account",
+			args:    args{actor: "root", username: "root", role: security.Buyer, reason: "because"},
+			wantErr: admin.SelfActionErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewAdminRepository(mockCtrl)
+			r.EXPECT().SetRole(gomock.Any(), tt.want, tt.args.role).Return(nil).Times(tt.times)
+			a := AdminService{Repository: r}
+			err := a.SetRole(context.Background(), tt.args.actor, tt.args.username, tt.args.role, tt.args.reason)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminService_AdjustDeposit(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	r := mocks.NewAdminRepository(mockCtrl)
+	want := admin.Entry{Actor: "root", Action: admin.AdjustDeposit, Target: "mike", Reason: "refund", Detail: "amount=-50"}
+	var got admin.Entry
+	r.EXPECT().AdjustDeposit(gomock.Any(), gomock.Any(), -50).DoAndReturn(
+		func(_ context.Context, entry admin.Entry, _ int) error {
+			got = entry
+			return nil
+		}).Times(1)
+	e := mocks.NewEventPublisher(mockCtrl)
+	e.EXPECT().Publish(gomock.Any(), events.Event{Type: events.Deposit, Key: "mike"}).Return(nil).Times(1)
+	a := AdminService{Repository: r, Events: e}
+	if err := a.AdjustDeposit(context.Background(), "root", "mike", -50, "refund"); err != nil {
+		t.Fatalf("AdjustDeposit() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AdjustDeposit() entry = %v, want %v", got, want)
+	}
+}
+
+func TestAdminService_Unlock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		resetTimes int
+	}{
+		{name: "lifts temporary lockout", resetTimes: 1},
+		{name: "unknown user", err: errors.New("something happened")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewAdminRepository(mockCtrl)
+			r.EXPECT().SetLocked(gomock.Any(), gomock.Any(), false).Return(tt.err)
+			l := mocks.NewLockoutService(mockCtrl)
+			l.EXPECT().Reset(gomock.Any(), "mike").Return(nil).Times(tt.resetTimes)
+			a := AdminService{Repository: r, Lockout: l}
+			if err := a.Unlock(context.Background(), "root", "mike", "verified"); !errors.Is(err, tt.err) {
+				t.Errorf("Unlock() error = %v, wantErr %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/artback/mvp/pkg/tracing"
+)
+
+type AlertService struct {
+	alert.Repository
+}
+
+func (a AlertService) SetThreshold(ctx context.Context, sellerID, product string, threshold int) (err error) {
+	ctx, span := tracer.Start(ctx, "AlertService.SetThreshold")
+	defer func() { tracing.End(span, err) }()
+
+	if threshold < 0 {
+		return alert.InvalidThresholdErr
+	}
+
+	return a.Repository.SetThreshold(ctx, sellerID, product, threshold)
+}
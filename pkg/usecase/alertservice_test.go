@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/alert"
+	"github.com/golang/mock/gomock"
+)
+
+func TestAlertService_SetThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		threshold int
+		times     int
+		wantErr   error
+	}{
+		{name: "threshold", threshold: 5, times: 1},
+		{name: "zero disables low stock alerts", threshold: 0, times: 1},
+		{name: "negative", threshold: -1, wantErr: alert.InvalidThresholdErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewAlertRepository(mockCtrl)
+			r.EXPECT().SetThreshold(gomock.Any(), "sam", "cola", tt.threshold).Return(nil).Times(tt.times)
+
+			err := AlertService{Repository: r}.SetThreshold(context.Background(), "sam", "cola", tt.threshold)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetThreshold() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/artback/mvp/pkg/health"
+	"github.com/artback/mvp/pkg/tracing"
+)
+
+// readyTimeout bounds the checks of a probe, a database that takes longer isn't ready either
+const readyTimeout = 2 * time.Second
+
+type HealthService struct {
+	health.Repository
+	Policy health.Policy
+	// Schema is the oldest schema version the service works with
+	Schema int
+	Drain  *health.Drain
+}
+
+func (h HealthService) Ready(ctx context.Context) (report health.Report) {
+	ctx, span := tracer.Start(ctx, "HealthService.Ready")
+	defer func() {
+		var err error
+		if report.Status == health.Down {
+			err = health.NotReadyErr
+		}
+		tracing.End(span, err)
+	}()
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	var draining error
+	if h.Drain != nil && h.Drain.Draining() {
+		draining = health.DrainingErr
+	}
+	report.Add("shutdown", draining)
+	report.Add("database", h.Repository.Ping(ctx))
+	report.Add("schema", h.schema(ctx))
+	var policy error
+	if len(h.Policy.GetPolicy()) == 0 {
+		policy = health.NoPolicyErr
+	}
+	report.Add("policy", policy)
+
+	return report
+}
+
+func (h HealthService) schema(ctx context.Context) error {
+	version, err := h.Repository.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < h.Schema {
+		return health.SchemaError{Have: version, Want: h.Schema}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/health"
+	"github.com/golang/mock/gomock"
+)
+
+type stubPolicy [][]string
+
+func (s stubPolicy) GetPolicy() [][]string {
+	return s
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	t.Parallel()
+
+	loaded := stubPolicy{{"buyer", "/v1/deposit", "GET", "owner"}}
+	tests := []struct {
+		name     string
+		draining bool
+		pingErr  error
+		version  int
+		policy   stubPolicy
+		want     health.Status
+		down     []string
+	}{
+		{name: "ready", version: 1, policy: loaded, want: health.Up},
+		{name: "newer schema", version: 2, policy: loaded, want: health.Up},
+		{name: "draining", draining: true, version: 1, policy: loaded, want: health.Down, down: []string{"shutdown"}},
+		{name: "database down", pingErr: errors.New("connection refused"), version: 1, policy: loaded, want: health.Down, down: []string{"database"}},
+		{name: "old schema", version: 0, policy: loaded, want: health.Down, down: []string{"schema"}},
+		{name: "no policy", version: 1, want: health.Down, down: []string{"policy"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewHealthRepository(mockCtrl)
+			r.EXPECT().Ping(gomock.Any()).Return(tt.pingErr)
+			r.EXPECT().SchemaVersion(gomock.Any()).Return(tt.version, nil)
+			drain := &health.Drain{}
+			if tt.draining {
+				drain.Start()
+			}
+
+			report := HealthService{Repository: r, Policy: tt.policy, Schema: 1, Drain: drain}.Ready(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Ready() status = %v, want %v", report.Status, tt.want)
+			}
+			var down []string
+			for _, check := range report.Checks {
+				if check.Status == health.Down {
+					down = append(down, check.Name)
+				}
+			}
+			if !reflect.DeepEqual(down, tt.down) {
+				t.Errorf("Ready() failing checks = %v, want %v", down, tt.down)
+			}
+		})
+	}
+}
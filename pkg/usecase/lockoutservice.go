@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/tracing"
+)
+
+// LockoutService throttles failed logins per username and per client ip
+type LockoutService struct {
+	lockout.Repository
+	lockout.Policy
+	// Scope keeps the keys of other throttles apart from the logins ones, empty for logins
+	Scope string
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+func (l LockoutService) now() time.Time {
+	if l.Now == nil {
+		return time.Now()
+	}
+	return l.Now()
+}
+
+func (l LockoutService) userKey(username string) string {
+	return l.Scope + lockout.UserKey(username)
+}
+
+func (l LockoutService) clientKey(ip string) string {
+	return l.Scope + lockout.ClientKey(ip)
+}
+
+func (l LockoutService) Check(ctx context.Context, username, ip string) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "LockoutService.Check")
+	defer func() { tracing.End(span, err) }()
+
+	states, err := l.Repository.Get(ctx, l.userKey(username), l.clientKey(ip))
+	if err != nil {
+		return false, err
+	}
+
+	now := l.now()
+	var (
+		failed bool
+		retry  *security.RetryError
+	)
+	for _, state := range states {
+		if l.Expired(state, now) {
+			continue
+		}
+		isUser := state.Key == l.userKey(username)
+		failed = failed || isUser
+
+		// Only a username gets locked, a throttled client may still be shared by legitimate users
+		cause := security.ThrottledErr
+		retryAt := state.LastFailure.Add(l.Delay(state.Failures))
+		if isUser {
+			retryAt = l.RetryAt(state)
+			if l.Locked(state) {
+				cause = security.LockedErr
+			}
+		}
+		if !retryAt.After(now) {
+			continue
+		}
+
+		if retry == nil || cause == security.LockedErr || retry.Err != security.LockedErr && retryAt.Sub(now) > retry.RetryAfter {
+			retry = &security.RetryError{Err: cause, RetryAfter: retryAt.Sub(now)}
+		}
+	}
+
+	if retry != nil {
+		return failed, *retry
+	}
+
+	return failed, nil
+}
+
+// Fail records a failed attempt, an empty username only counts against the client
+func (l LockoutService) Fail(ctx context.Context, username, ip string) (err error) {
+	ctx, span := tracer.Start(ctx, "LockoutService.Fail")
+	defer func() { tracing.End(span, err) }()
+
+	now := l.now()
+	since := now.Add(-l.Window)
+
+	if username != "" {
+		if _, err := l.Repository.Fail(ctx, l.userKey(username), now, since); err != nil {
+			return err
+		}
+	}
+
+	_, err = l.Repository.Fail(ctx, l.clientKey(ip), now, since)
+
+	return err
+}
+
+// Reset clears the failures of username, client failures are only forgotten after the window
+func (l LockoutService) Reset(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "LockoutService.Reset")
+	defer func() { tracing.End(span, err) }()
+
+	return l.Repository.Reset(ctx, l.userKey(username))
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/golang/mock/gomock"
+)
+
+func TestLockoutService_Check(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := lockout.Policy{MaxFailures: 3, BaseDelay: time.Second, MaxDelay: 4 * time.Second, LockoutFor: time.Minute, Window: time.Hour}
+
+	tests := []struct {
+		name       string
+		states     []lockout.State
+		wantFailed bool
+		wantErr    error
+		wantRetry  time.Duration
+	}{
+		{name: "no failures"},
+		{
+			name:       "delay passed",
+			states:     []lockout.State{{Key: lockout.UserKey("mike"), Failures: 1, LastFailure: now.Add(-2 * time.Second)}},
+			wantFailed: true,
+		},
+		{
+			name:       "delay doubles",
+			states:     []lockout.State{{Key: lockout.UserKey("mike"), Failures: 2, LastFailure: now.Add(-time.Second)}},
+			wantFailed: true,
+			wantErr:    security.ThrottledErr,
+			wantRetry:  time.Second,
+		},
+		{
+			name:       "locked username",
+			states:     []lockout.State{{Key: lockout.UserKey("mike"), Failures: 3, LastFailure: now.Add(-10 * time.Second)}},
+			wantFailed: true,
+			wantErr:    security.LockedErr,
+			wantRetry:  50 * time.Second,
+		},
+		{
+			name:      "throttled client is never locked",
+			states:    []lockout.State{{Key: lockout.ClientKey("10.0.0.1"), Failures: 10, LastFailure: now}},
+			wantErr:   security.ThrottledErr,
+			wantRetry: 4 * time.Second,
+		},
+		{
+			name:   "failures outside window forgotten",
+			states: []lockout.State{{Key: lockout.UserKey("mike"), Failures: 3, LastFailure: now.Add(-2 * time.Hour)}},
+		},
+		{
+			name: "lock wins over throttle",
+			states: []lockout.State{
+				{Key: lockout.ClientKey("10.0.0.1"), Failures: 10, LastFailure: now},
+				{Key: lockout.UserKey("mike"), Failures: 3, LastFailure: now.Add(-50 * time.Second)},
+			},
+			wantFailed: true,
+			wantErr:    security.LockedErr,
+			wantRetry:  10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewLockoutRepository(mockCtrl)
+			r.EXPECT().Get(gomock.Any(), lockout.UserKey("mike"), lockout.ClientKey("10.0.0.1")).Return(tt.states, nil)
+			l := LockoutService{Repository: r, Policy: policy, Now: func() time.Time { return now }}
+			failed, err := l.Check(context.Background(), "mike", "10.0.0.1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if failed != tt.wantFailed {
+				t.Errorf("Check() failed = %v, want %v", failed, tt.wantFailed)
+			}
+			var retry security.RetryError
+			if errors.As(err, &retry) && retry.RetryAfter != tt.wantRetry {
+				t.Errorf("Check() retry after = %v, want %v", retry.RetryAfter, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestLockoutService_Fail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		scope     string
+		username  string
+		userTimes int
+	}{
+		{name: "known username", username: "mike", userTimes: 1},
+		{name: "unknown username counts against client only"},
+		{name: "scoped keys", scope: lockout.ResetScope, username: "mike", userTimes: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewLockoutRepository(mockCtrl)
+			since := now.Add(-time.Hour)
+			r.EXPECT().Fail(gomock.Any(), tt.scope+lockout.UserKey(tt.username), now, since).Return(lockout.State{}, nil).Times(tt.userTimes)
+			r.EXPECT().Fail(gomock.Any(), tt.scope+lockout.ClientKey("10.0.0.1"), now, since).Return(lockout.State{}, nil)
+			l := LockoutService{Repository: r, Policy: lockout.Policy{Window: time.Hour}, Scope: tt.scope, Now: func() time.Time { return now }}
+			if err := l.Fail(context.Background(), tt.username, "10.0.0.1"); err != nil {
+				t.Errorf("Fail() error = %v", err)
+			}
+		})
+	}
+}
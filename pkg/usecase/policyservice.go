@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/tracing"
+)
+
+// policyTarget is the audit target of every policy change
+const policyTarget = "policy"
+
+// PolicyService manages the rules of the enforcer. A change is stored together with its audit entry,
+// then the enforcer and the other replicas load the stored rules again.
+type PolicyService struct {
+	policy.Enforcer
+	Repository policy.Repository
+	// Notifier is nil without other replicas
+	Notifier policy.Notifier
+}
+
+func (p PolicyService) Rules(_ context.Context) ([]policy.Rule, error) {
+	rules := make([]policy.Rule, 0)
+	for _, line := range p.GetPolicy() {
+		if len(line) != 4 {
+			continue
+		}
+
+		rules = append(rules, policy.Rule{Role: security.Role(line[0]), Path: line[1], Method: line[2], Ownership: line[3]})
+	}
+
+	return rules, nil
+}
+
+func (p PolicyService) AddRule(ctx context.Context, actor string, rule policy.Rule, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.AddRule")
+	defer func() { tracing.End(span, err) }()
+
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	return p.change(ctx, actor, admin.AddPolicy, policy.Change{PType: policy.RuleType, Values: rule.Values()}, reason)
+}
+
+func (p PolicyService) RemoveRule(ctx context.Context, actor string, rule policy.Rule, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.RemoveRule")
+	defer func() { tracing.End(span, err) }()
+
+	return p.change(ctx, actor, admin.RemovePolicy, policy.Change{PType: policy.RuleType, Values: rule.Values(), Remove: true}, reason)
+}
+
+func (p PolicyService) Assignments(_ context.Context) ([]policy.Assignment, error) {
+	assignments := make([]policy.Assignment, 0)
+	for _, line := range p.GetGroupingPolicy() {
+		if len(line) != 2 {
+			continue
+		}
+
+		assignments = append(assignments, policy.Assignment{Role: security.Role(line[0]), Inherits: security.Role(line[1])})
+	}
+
+	return assignments, nil
+}
+
+func (p PolicyService) AddAssignment(ctx context.Context, actor string, assignment policy.Assignment, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.AddAssignment")
+	defer func() { tracing.End(span, err) }()
+
+	if err := assignment.Validate(); err != nil {
+		return err
+	}
+
+	return p.change(ctx, actor, admin.AddAssignment, policy.Change{PType: policy.AssignmentType, Values: assignment.Values()}, reason)
+}
+
+func (p PolicyService) RemoveAssignment(ctx context.Context, actor string, assignment policy.Assignment, reason string) (err error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.RemoveAssignment")
+	defer func() { tracing.End(span, err) }()
+
+	return p.change(ctx, actor, admin.RemoveAssignment,
+		policy.Change{PType: policy.AssignmentType, Values: assignment.Values(), Remove: true}, reason)
+}
+
+// change stores one rule change with its audit entry, the repository refuses duplicates and unknown rules
+func (p PolicyService) change(ctx context.Context, actor string, action admin.Action, change policy.Change, reason string) error {
+	entry, err := newEntry(actor, action, policyTarget, reason)
+	if err != nil {
+		return err
+	}
+	entry.Detail = strings.Join(change.Values, ",")
+
+	if err := p.Repository.Apply(ctx, entry, change); err != nil {
+		return err
+	}
+	if err := p.LoadPolicy(); err != nil {
+		return err
+	}
+	if p.Notifier != nil {
+		return p.Notifier.Update()
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/admin"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/policy"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/golang/mock/gomock"
+)
+
+// store keeps the rules in memory, it is the adapter the enforcer loads from and the repository changes go to
+type store struct {
+	mu      sync.Mutex
+	lines   [][]string
+	entries []admin.Entry
+}
+
+func (s *store) LoadPolicy(m model.Model) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, line := range s.lines {
+		persist.LoadPolicyArray(line, m)
+	}
+	return nil
+}
+
+func (s *store) SavePolicy(model.Model) error                              { return nil }
+func (s *store) AddPolicy(string, string, []string) error                  { return nil }
+func (s *store) RemovePolicy(string, string, []string) error               { return nil }
+func (s *store) RemoveFilteredPolicy(string, string, int, ...string) error { return nil }
+
+func (s *store) Apply(_ context.Context, entry admin.Entry, changes ...policy.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := append([][]string(nil), s.lines...)
+	for _, c := range changes {
+		line := append([]string{c.PType}, c.Values...)
+		i := s.index(lines, line)
+		switch {
+		case c.Remove && i < 0:
+			return repository.EmptyError{}
+		case c.Remove:
+			lines = append(lines[:i:i], lines[i+1:]...)
+		case i >= 0:
+			return repository.DuplicateError{Constraint: "casbin_rule_unique"}
+		default:
+			lines = append(lines, line)
+		}
+	}
+	s.lines = lines
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *store) index(lines [][]string, line []string) int {
+	for i, l := range lines {
+		if strings.Join(l, ",") == strings.Join(line, ",") {
+			return i
+		}
+	}
+	return -1
+}
+
+func policyService(t *testing.T, existing ...[]string) (PolicyService, *store, *casbin.SyncedEnforcer) {
+	s := &store{lines: existing}
+	e, err := casbin.NewSyncedEnforcer("../../config/rbac_model.conf", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return PolicyService{Enforcer: e, Repository: s}, s, e
+}
+
+func TestPolicyService_AddRule(t *testing.T) {
+	t.Parallel()
+
+	valid := policy.Rule{Role: security.Buyer, Path: "/v1/product/*", Method: "get", Ownership: policy.Any}
+
+	tests := []struct {
+		name     string
+		existing [][]string
+		rule     policy.Rule
+		reason   string
+		recorded int
+		wantErr  error
+	}{
+		{name: "successful", rule: valid, reason: "buyers browse", recorded: 1},
+		{name: "duplicate", existing: [][]string{{"p", "buyer", "/v1/product/*", "GET", "any"}}, rule: valid, reason: "again", wantErr: repository.DuplicateError{Constraint: "casbin_rule_unique"}},
+		{name: "missing reason", rule: valid, wantErr: admin.MissingReasonErr},
+		{name: "patch", rule: policy.Rule{Role: security.Buyer, Path: "/v1/user/*", Method: "PATCH", Ownership: policy.Owner}, reason: "merge patches", recorded: 1},
+		{name: "invalid method", rule: policy.Rule{Role: security.Buyer, Path: "/v1", Method: "TRACE", Ownership: policy.Any}, reason: "x", wantErr: policy.InvalidRuleErr},
+		{name: "invalid path", rule: policy.Rule{Role: security.Buyer, Path: "/v1/(", Method: "GET", Ownership: policy.Any}, reason: "x", wantErr: policy.InvalidRuleErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, s, _ := policyService(t, tt.existing...)
+			err := p.AddRule(context.Background(), "root", tt.rule, tt.reason)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(s.entries) != tt.recorded {
+				t.Errorf("AddRule() recorded %d entries, want %d", len(s.entries), tt.recorded)
+			}
+			if got := len(p.GetPolicy()); got != len(tt.existing)+tt.recorded {
+				t.Errorf("AddRule() left %d rules in the enforcer, want %d", got, len(tt.existing)+tt.recorded)
+			}
+		})
+	}
+}
+
+func TestPolicyService_Assignments(t *testing.T) {
+	t.Parallel()
+
+	p, _, e := policyService(t)
+	ctx := context.Background()
+
+	if err := p.AddRule(ctx, "root", policy.Rule{Role: security.Buyer, Path: "/v1/deposit", Method: "GET", Ownership: policy.Owner}, "base"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddAssignment(ctx, "root", policy.Assignment{Role: security.Admin, Inherits: security.Buyer}, "admins buy too"); err != nil {
+		t.Fatalf("AddAssignment() error = %v", err)
+	}
+
+	ok, err := e.Enforce(security.Subject{Name: "root", Role: "admin"}, security.Object{Path: "/v1/deposit", Owner: "root"}, "GET")
+	if err != nil || !ok {
+		t.Errorf("Enforce() with inherited role = %v, %v, want true", ok, err)
+	}
+
+	if err := p.RemoveAssignment(ctx, "root", policy.Assignment{Role: security.Admin, Inherits: security.Seller}, "cleanup"); !errors.As(err, &repository.EmptyError{}) {
+		t.Errorf("RemoveAssignment() unknown assignment error = %v, want EmptyError", err)
+	}
+
+	got, _ := p.Assignments(ctx)
+	if len(got) != 1 || got[0].Inherits != security.Buyer {
+		t.Errorf("Assignments() got = %v", got)
+	}
+}
+
+// notifier counts the updates sent to the other replicas
+type notifier int
+
+func (n *notifier) Update() error {
+	*n++
+	return nil
+}
+
+func TestPolicyService_RemoveRule(t *testing.T) {
+	t.Parallel()
+
+	rule := policy.Rule{Role: security.Buyer, Path: "/v1/deposit", Method: "GET", Ownership: policy.Owner}
+	auditFailed := errors.New("audit_log is not writable")
+
+	tests := []struct {
+		name      string
+		applyErr  error
+		wantRules int
+		wantErr   error
+	}{
+		{name: "successful", wantRules: 0},
+		{name: "audit fails", applyErr: auditFailed, wantRules: 1, wantErr: auditFailed},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			p, s, _ := policyService(t, append([]string{policy.RuleType}, rule.Values()...))
+			r := mocks.NewPolicyRepository(mockCtrl)
+			r.EXPECT().Apply(gomock.Any(), gomock.Any(), policy.Change{PType: policy.RuleType, Values: rule.Values(), Remove: true}).
+				DoAndReturn(func(ctx context.Context, entry admin.Entry, changes ...policy.Change) error {
+					if tt.applyErr != nil {
+						return tt.applyErr
+					}
+					return s.Apply(ctx, entry, changes...)
+				})
+			n := new(notifier)
+			p.Repository, p.Notifier = r, n
+
+			if err := p.RemoveRule(context.Background(), "root", rule, "no longer needed"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(p.GetPolicy()); got != tt.wantRules {
+				t.Errorf("RemoveRule() left %d rules in the enforcer, want %d", got, tt.wantRules)
+			}
+			if want := notifier(1 - tt.wantRules); *n != want {
+				t.Errorf("RemoveRule() notified %d times, want %d", *n, want)
+			}
+		})
+	}
+}
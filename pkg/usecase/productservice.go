@@ -1,7 +1,51 @@
 package usecase
 
-import "github.com/artback/mvp/pkg/products"
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/tracing"
+)
 
 type ProductService struct {
 	products.Repository
+	// Events is told about every changed product, nil when nobody listens
+	Events events.Publisher
+}
+
+func (p ProductService) Insert(ctx context.Context, product products.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Insert")
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.Repository.Insert(ctx, product); err != nil {
+		return err
+	}
+	events.Publish(ctx, p.Events, events.Event{Type: events.Product, Key: product.Name})
+
+	return nil
+}
+
+func (p ProductService) Update(ctx context.Context, product products.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.Repository.Update(ctx, product); err != nil {
+		return err
+	}
+	events.Publish(ctx, p.Events, events.Event{Type: events.Product, Key: product.Name})
+
+	return nil
+}
+
+func (p ProductService) Delete(ctx context.Context, username, name string) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.Repository.Delete(ctx, username, name); err != nil {
+		return err
+	}
+	events.Publish(ctx, p.Events, events.Event{Type: events.Product, Key: name})
+
+	return nil
 }
@@ -0,0 +1,6 @@
+package usecase
+
+import "go.opentelemetry.io/otel"
+
+// tracer opens a span for every use case, named after the service and method
+var tracer = otel.Tracer("github.com/artback/mvp/pkg/usecase")
@@ -2,7 +2,15 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/artback/mvp/pkg/api/middleware/security"
+	"github.com/artback/mvp/pkg/lockout"
+	"github.com/artback/mvp/pkg/notify"
 	"github.com/artback/mvp/pkg/pass"
+	"github.com/artback/mvp/pkg/repository"
+	"github.com/artback/mvp/pkg/tracing"
+	"time"
 
 	"github.com/artback/mvp/pkg/change"
 	"github.com/artback/mvp/pkg/coin"
@@ -10,36 +18,252 @@ import (
 )
 
 type UserService struct {
-	coin.Coins
+	// Coins are read once per call, a replacement never shows up halfway through one
+	Coins coin.Source
 	users.Repository
+	Hasher pass.Hasher
+	Policy pass.Policy
+	// Notifier delivers password reset tokens, which stay valid for ResetTTL
+	Notifier notify.Notifier
+	ResetTTL time.Duration
+	// Lockout is reset once a password was reset, nil when logins aren't throttled
+	Lockout lockout.Service
+	// Invalidator is told about changed users, nil when nothing is cached
+	Invalidator users.Invalidator
 }
 
-func (u UserService) GetResponse(ctx context.Context, username string) (*users.Response, error) {
+func (u UserService) invalidate(username string) {
+	if u.Invalidator != nil {
+		u.Invalidator.Invalidate(username)
+	}
+}
+
+func (u UserService) GetResponse(ctx context.Context, username string) (_ *users.Response, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetResponse")
+	defer func() { tracing.End(span, err) }()
+
 	user, err := u.Get(ctx, username)
 	if err != nil {
 		return nil, err
 	}
 
 	return &users.Response{
-		Deposit:  change.New(u.Coins, user.Deposit),
+		Deposit:  change.New(u.Coins.Coins(), user.Deposit),
 		Username: user.Username,
 		Role:     user.Role,
+		Contact:  user.Contact,
 	}, nil
 }
 
-func (u UserService) Insert(ctx context.Context, user users.User) error {
-	hashedPwd, err := pass.HashAndSalt(user.Password)
+// Insert creates every account as a buyer, asking for the seller role files a request an admin has to approve
+func (u UserService) Insert(ctx context.Context, user users.User) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.Insert")
+	defer func() { tracing.End(span, err) }()
+
+	requested := user.Role
+	switch {
+	case requested == "":
+		requested = security.Buyer
+	case !requested.Valid():
+		return users.InvalidRoleErr
+	case requested != security.Buyer && users.SelfService(security.Buyer, requested) == users.Forbidden:
+		return users.RoleNotAllowedErr
+	}
+
+	if err := u.Policy.Validate(user.Username, user.Password); err != nil {
+		return err
+	}
+	hashedPwd, err := u.Hasher.Hash(user.Password)
 	if err != nil {
 		return err
 	}
 	user.Password = hashedPwd
-	return u.Repository.Insert(ctx, user)
+	user.Role = security.Buyer
+	if requested == security.Buyer {
+		return u.Repository.Insert(ctx, user)
+	}
+
+	// the account and its request exist together or not at all
+	return u.Repository.InsertRequestingRole(ctx, user, requested)
 }
-func (u UserService) Update(ctx context.Context, user users.User) error {
-	hashedPwd, err := pass.HashAndSalt(user.Password)
+
+// Patch checks the whole patch before changing anything, a new password needs the current one.
+// The password and contact are stored in one transaction, either both change or none.
+func (u UserService) Patch(ctx context.Context, username string, patch users.Patch) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.Patch")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := u.Repository.Get(ctx, username)
 	if err != nil {
 		return err
 	}
-	user.Password = hashedPwd
-	return u.Repository.Update(ctx, user)
+	// The authorizer lets a user with a forced reset through to here, only the new password gets it further
+	if user.ResetRequired && patch.Password == nil {
+		return security.ResetRequiredErr
+	}
+
+	var hashedPwd string
+	if patch.Password != nil {
+		if hashedPwd, err = u.newPassword(*user, patch); err != nil {
+			return err
+		}
+	}
+
+	change := users.Change{PasswordHash: hashedPwd, Contact: patch.Contact}
+	if change == (users.Change{}) {
+		return nil
+	}
+	if err := u.Repository.Apply(ctx, username, change); err != nil {
+		return err
+	}
+	// Other credentials of the user, like verified passwords cached by any replica, are revoked
+	if change.PasswordHash != "" {
+		u.invalidate(username)
+	}
+
+	return nil
+}
+
+// newPassword hashes the new password of patch once the current password matched
+func (u UserService) newPassword(user users.User, patch users.Patch) (string, error) {
+	if patch.CurrentPassword == nil {
+		return "", users.CurrentPasswordRequiredErr
+	}
+	if !pass.Compare(user.Password, *patch.CurrentPassword) {
+		return "", users.CurrentPasswordWrongErr
+	}
+	if err := u.Policy.Validate(user.Username, *patch.Password); err != nil {
+		return "", err
+	}
+
+	return u.Hasher.Hash(*patch.Password)
+}
+
+func (u UserService) Delete(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	if err := u.Repository.Delete(ctx, username); err != nil {
+		return err
+	}
+	u.invalidate(username)
+	return nil
+}
+
+// ChangeRole applies the self-service role policy, returning how the change was handled
+func (u UserService) ChangeRole(ctx context.Context, username string, role security.Role) (_ users.Transition, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangeRole")
+	defer func() { tracing.End(span, err) }()
+
+	if !role.Valid() {
+		return users.Forbidden, users.InvalidRoleErr
+	}
+
+	user, err := u.Repository.Get(ctx, username)
+	if err != nil {
+		return users.Forbidden, err
+	}
+
+	transition := users.SelfService(user.Role, role)
+
+	return transition, u.applyRole(ctx, username, role, transition)
+}
+
+func (u UserService) applyRole(ctx context.Context, username string, role security.Role, transition users.Transition) error {
+	switch transition {
+	case users.Immediate:
+		if err := u.Repository.SetRole(ctx, username, role); err != nil {
+			return err
+		}
+		u.invalidate(username)
+		return nil
+	case users.NeedsApproval:
+		return u.Repository.RequestRole(ctx, username, role)
+	default:
+		return users.RoleNotAllowedErr
+	}
+}
+
+func (u UserService) RehashPassword(ctx context.Context, user users.User, password string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.RehashPassword")
+	defer func() { tracing.End(span, err) }()
+
+	if !u.Hasher.Outdated(user.Password) {
+		return nil
+	}
+	hashedPwd, err := u.Hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return u.Repository.ReplacePasswordHash(ctx, user.Username, user.Password, hashedPwd)
+}
+
+func (u UserService) RequestPasswordReset(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.RequestPasswordReset")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := u.Repository.Get(ctx, username)
+	if errors.As(err, &repository.EmptyError{}) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Without a contact there is nowhere to send the token, the caller still isn't told
+	if user.Contact == "" {
+		return nil
+	}
+
+	token, hash, err := users.NewResetToken()
+	if err != nil {
+		return err
+	}
+	expires := time.Now().Add(u.ResetTTL)
+	if err := u.Repository.CreateResetToken(ctx, username, hash, expires); err != nil {
+		return err
+	}
+
+	return u.Notifier.Notify(ctx, notify.Message{
+		To:      user.Contact,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("A password reset was requested for %s. Use this token before %s to set a new password:\n\n%s\n\n"+
+			"If you didn't ask for it, ignore this message.", username, expires.Format(time.RFC1123), token),
+	})
+}
+
+// ResetPassword uses up the token, the user's cached credentials and login failures are dropped
+func (u UserService) ResetPassword(ctx context.Context, token, password string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ResetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	hash := users.HashResetToken(token)
+	username, err := u.Repository.ResetTokenUser(ctx, hash)
+	if errors.As(err, &repository.EmptyError{}) {
+		return users.InvalidResetTokenErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := u.Policy.Validate(username, password); err != nil {
+		return err
+	}
+	hashedPwd, err := u.Hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if _, err := u.Repository.RedeemResetToken(ctx, hash, hashedPwd); err != nil {
+		if errors.As(err, &repository.EmptyError{}) {
+			return users.InvalidResetTokenErr
+		}
+		return err
+	}
+	u.invalidate(username)
+
+	if u.Lockout == nil {
+		return nil
+	}
+
+	return u.Lockout.Reset(ctx, username)
 }
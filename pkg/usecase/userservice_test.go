@@ -2,11 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/api/middleware/security"
 	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/notify"
+	"github.com/artback/mvp/pkg/pass"
 	"github.com/artback/mvp/pkg/repository"
 	"github.com/artback/mvp/pkg/users"
 	"github.com/golang/mock/gomock"
@@ -26,7 +32,6 @@ func TestUserService_GetResponse(t *testing.T) {
 	}
 
 	type args struct {
-		ctx      context.Context
 		username string
 	}
 
@@ -79,7 +84,7 @@ func TestUserService_GetResponse(t *testing.T) {
 				Repository: rep,
 			}
 
-			got, err := u.GetResponse(tt.args.ctx, tt.args.username)
+			got, err := u.GetResponse(context.Background(), tt.args.username)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetResponse() error = %v, wantErr %v", err, tt.wantErr)
 
@@ -91,3 +96,324 @@ func TestUserService_GetResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_Insert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		role         security.Role
+		password     string
+		policy       pass.Policy
+		insertTimes  int
+		requestTimes int
+		wantErr      error
+	}{
+		{name: "default role", insertTimes: 1},
+		{name: "buyer", role: security.Buyer, insertTimes: 1},
+		{name: "seller needs approval", role: security.Seller, requestTimes: 1},
+		{name: "admin not allowed", role: security.Admin, wantErr: users.RoleNotAllowedErr},
+		{name: "unknown role", role: "root", wantErr: users.InvalidRoleErr},
+		{name: "weak password", password: "pass", policy: pass.Policy{MinLength: 8}, wantErr: pass.TooShortErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			rep := mocks.NewUserRepository(mockCtrl)
+			rep.EXPECT().Insert(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user users.User) error {
+				if user.Role != security.Buyer {
+					t.Errorf("Insert() role = %v, want %v", user.Role, security.Buyer)
+				}
+				return nil
+			}).Times(tt.insertTimes)
+			rep.EXPECT().InsertRequestingRole(gomock.Any(), gomock.Any(), tt.role).DoAndReturn(
+				func(_ context.Context, user users.User, _ security.Role) error {
+					if user.Role != security.Buyer {
+						t.Errorf("InsertRequestingRole() role = %v, want %v", user.Role, security.Buyer)
+					}
+					return nil
+				}).Times(tt.requestTimes)
+			u := UserService{Repository: rep, Policy: tt.policy, Hasher: pass.Hasher{Scheme: pass.Bcrypt{Cost: 4}}}
+			password := tt.password
+			if password == "" {
+				password = "correct horse"
+			}
+			err := u.Insert(context.Background(), users.User{Username: "mike", Password: password, Role: tt.role})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Insert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		current      security.Role
+		role         security.Role
+		setTimes     int
+		requestTimes int
+		want         users.Transition
+		wantErr      error
+	}{
+		{name: "buyer applies for seller", current: security.Buyer, role: security.Seller, requestTimes: 1, want: users.NeedsApproval},
+		{name: "seller steps down", current: security.Seller, role: security.Buyer, setTimes: 1, want: users.Immediate},
+		{name: "buyer to admin", current: security.Buyer, role: security.Admin, wantErr: users.RoleNotAllowedErr},
+		{name: "unknown role", current: security.Buyer, role: "root", wantErr: users.InvalidRoleErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			rep := mocks.NewUserRepository(mockCtrl)
+			rep.EXPECT().Get(gomock.Any(), "mike").Return(&users.User{Username: "mike", Role: tt.current}, nil).AnyTimes()
+			rep.EXPECT().SetRole(gomock.Any(), "mike", tt.role).Return(nil).Times(tt.setTimes)
+			rep.EXPECT().RequestRole(gomock.Any(), "mike", tt.role).Return(nil).Times(tt.requestTimes)
+			u := UserService{Repository: rep}
+			got, err := u.ChangeRole(context.Background(), "mike", tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChangeRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ChangeRole() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type invalidated []string
+
+func (i *invalidated) Invalidate(username string) {
+	*i = append(*i, username)
+}
+
+func TestUserService_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	rep := mocks.NewUserRepository(mockCtrl)
+	hash, _ := pass.Hasher{Scheme: pass.Bcrypt{Cost: 4}}.Hash("old password")
+	rep.EXPECT().Get(gomock.Any(), "mike").Return(&users.User{Username: "mike", Password: hash}, nil)
+	rep.EXPECT().Apply(gomock.Any(), "mike", gomock.Any()).Return(nil)
+	rep.EXPECT().Delete(gomock.Any(), "anna").Return(repository.EmptyError{})
+	rep.EXPECT().Delete(gomock.Any(), "mike").Return(nil)
+	var got invalidated
+	u := UserService{Repository: rep, Invalidator: &got, Hasher: pass.Hasher{Scheme: pass.Bcrypt{Cost: 4}}}
+	password, current := "new password", "old password"
+	_ = u.Patch(context.Background(), "mike", users.Patch{Password: &password, CurrentPassword: &current})
+	_ = u.Delete(context.Background(), "anna")
+	_ = u.Delete(context.Background(), "mike")
+	if want := (invalidated{"mike", "mike"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalidate() called with %v, want %v", got, want)
+	}
+}
+
+func TestUserService_RehashPassword(t *testing.T) {
+	t.Parallel()
+
+	current := pass.Bcrypt{Cost: 5}
+	oldHash, _ := pass.Hasher{Scheme: pass.Bcrypt{Cost: 4}}.Hash("password")
+	currentHash, _ := pass.Hasher{Scheme: current}.Hash("password")
+
+	tests := []struct {
+		name  string
+		hash  string
+		times int
+	}{
+		{name: "outdated cost", hash: oldHash, times: 1},
+		{name: "current", hash: currentHash},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			rep := mocks.NewUserRepository(mockCtrl)
+			rep.EXPECT().ReplacePasswordHash(gomock.Any(), "mike", tt.hash, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _, newHash string) error {
+					if !pass.Compare(newHash, "password") || (pass.Hasher{Scheme: current}).Outdated(newHash) {
+						t.Errorf("ReplacePasswordHash() new hash %q isn't a current hash of the password", newHash)
+					}
+					return nil
+				}).Times(tt.times)
+			u := UserService{Repository: rep, Hasher: pass.Hasher{Scheme: current}}
+			if err := u.RehashPassword(context.Background(), users.User{Username: "mike", Password: tt.hash}, "password"); err != nil {
+				t.Errorf("RehashPassword() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUserService_Patch(t *testing.T) {
+	t.Parallel()
+
+	hasher := pass.Hasher{Scheme: pass.Bcrypt{Cost: 4}}
+	hash, _ := hasher.Hash("old password")
+	password, current, wrong, weak, contact := "new password", "old password", "guess", "short", "mike@example.com"
+	failed := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		resetRequired bool
+		patch         users.Patch
+		applyTimes    int
+		applyErr      error
+		// wantPassword is whether the single Apply stores a new password
+		wantPassword bool
+		wantErr      error
+	}{
+		{name: "empty patch changes nothing"},
+		{name: "password", patch: users.Patch{Password: &password, CurrentPassword: &current}, applyTimes: 1, wantPassword: true},
+		{name: "password without current", patch: users.Patch{Password: &password}, wantErr: users.CurrentPasswordRequiredErr},
+		{name: "password with wrong current", patch: users.Patch{Password: &password, CurrentPassword: &wrong}, wantErr: users.CurrentPasswordWrongErr},
+		{name: "weak password", patch: users.Patch{Password: &weak, CurrentPassword: &current}, wantErr: pass.TooShortErr},
+		{name: "contact", patch: users.Patch{Contact: &contact}, applyTimes: 1},
+		{
+			name: "password and contact", patch: users.Patch{Password: &password, CurrentPassword: &current, Contact: &contact},
+			applyTimes: 1, wantPassword: true,
+		},
+		{
+			name: "failed store fails the whole patch", patch: users.Patch{Password: &password, CurrentPassword: &current, Contact: &contact},
+			applyTimes: 1, applyErr: failed, wantPassword: true, wantErr: failed,
+		},
+		{name: "reset required refuses contact", resetRequired: true, patch: users.Patch{Contact: &contact}, wantErr: security.ResetRequiredErr},
+		{
+			name: "reset required takes password", resetRequired: true, patch: users.Patch{Password: &password, CurrentPassword: &current, Contact: &contact},
+			applyTimes: 1, wantPassword: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			rep := mocks.NewUserRepository(mockCtrl)
+			rep.EXPECT().Get(gomock.Any(), "mike").Return(&users.User{Username: "mike", Password: hash, Role: security.Buyer, ResetRequired: tt.resetRequired}, nil)
+			rep.EXPECT().Apply(gomock.Any(), "mike", gomock.Any()).DoAndReturn(func(_ context.Context, _ string, change users.Change) error {
+				if (change.PasswordHash != "") != tt.wantPassword || (tt.wantPassword && !pass.Compare(change.PasswordHash, password)) {
+					t.Errorf("Apply() password hash = %q, want a hash of the new password: %v", change.PasswordHash, tt.wantPassword)
+				}
+				if change.Contact != tt.patch.Contact {
+					t.Errorf("Apply() change = %+v, want the contact of the patch", change)
+				}
+				return tt.applyErr
+			}).Times(tt.applyTimes)
+			u := UserService{Repository: rep, Hasher: hasher, Policy: pass.Policy{MinLength: 8}}
+			if err := u.Patch(context.Background(), "mike", tt.patch); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Patch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_RequestPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		user    *users.User
+		err     error
+		sent    int
+		wantErr bool
+	}{
+		{name: "sends token to contact", user: &users.User{Username: "mike", Contact: "mike@example.com"}, sent: 1},
+		{name: "unknown user looks the same", err: repository.EmptyError{}},
+		{name: "user without contact looks the same", user: &users.User{Username: "mike"}},
+		{name: "error repository", err: errors.New("something happened"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			rep := mocks.NewUserRepository(mockCtrl)
+			rep.EXPECT().Get(gomock.Any(), "mike").Return(tt.user, tt.err)
+			var stored string
+			rep.EXPECT().CreateResetToken(gomock.Any(), "mike", gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, hash string, _ time.Time) error {
+					stored = hash
+					return nil
+				}).Times(tt.sent)
+			n := mocks.NewNotifier(mockCtrl)
+			n.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, msg notify.Message) error {
+				token := msg.Body[strings.LastIndex(msg.Body, ":\n\n")+3:]
+				token = token[:strings.Index(token, "\n")]
+				if msg.To != "mike@example.com" || users.HashResetToken(token) != stored {
+					t.Errorf("Notify() message = %v doesn't carry the stored token", msg)
+				}
+				return nil
+			}).Times(tt.sent)
+			u := UserService{Repository: rep, Notifier: n, ResetTTL: time.Minute}
+			if err := u.RequestPasswordReset(context.Background(), "mike"); (err != nil) != tt.wantErr {
+				t.Errorf("RequestPasswordReset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		password    string
+		userErr     error
+		redeemTimes int
+		redeemErr   error
+		wantErr     error
+	}{
+		{name: "successful", password: "new password", redeemTimes: 1},
+		{name: "unknown or expired token", password: "new password", userErr: repository.EmptyError{}, wantErr: users.InvalidResetTokenErr},
+		{name: "token used meanwhile", password: "new password", redeemTimes: 1, redeemErr: repository.EmptyError{}, wantErr: users.InvalidResetTokenErr},
+		{name: "weak password keeps token", password: "short", wantErr: pass.TooShortErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			hash := users.HashResetToken("token")
+			rep := mocks.NewUserRepository(mockCtrl)
+			rep.EXPECT().ResetTokenUser(gomock.Any(), hash).Return("mike", tt.userErr)
+			rep.EXPECT().RedeemResetToken(gomock.Any(), hash, gomock.Any()).Return("mike", tt.redeemErr).Times(tt.redeemTimes)
+			l := mocks.NewLockoutService(mockCtrl)
+			success := 0
+			if tt.wantErr == nil {
+				success = 1
+			}
+			l.EXPECT().Reset(gomock.Any(), "mike").Return(nil).Times(success)
+			var got invalidated
+			u := UserService{
+				Repository:  rep,
+				Hasher:      pass.Hasher{Scheme: pass.Bcrypt{Cost: 4}},
+				Policy:      pass.Policy{MinLength: 8},
+				Lockout:     l,
+				Invalidator: &got,
+			}
+			if err := u.ResetPassword(context.Background(), "token", tt.password); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResetPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != success {
+				t.Errorf("ResetPassword() invalidated %v", got)
+			}
+		})
+	}
+}
@@ -5,23 +5,78 @@ import (
 
 	"github.com/artback/mvp/pkg/change"
 	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/events"
+	"github.com/artback/mvp/pkg/products"
+	"github.com/artback/mvp/pkg/tracing"
 	"github.com/artback/mvp/pkg/vending"
 )
 
 type VendingService struct {
 	vending.Repository
-	coin.Coins
+	// Coins are read once per call, a replacement never shows up halfway through one
+	Coins coin.Source
+	// Events is told about changed deposits and the stock a purchase took, nil when nobody listens
+	Events events.Publisher
+	// Recorder is told about deposited coins and sold products, nil when nothing is measured
+	Recorder vending.Recorder
 }
 
-func (v VendingService) GetAccount(ctx context.Context, username string) (*vending.Response, error) {
+func (v VendingService) GetAccount(ctx context.Context, username string) (_ *vending.Response, err error) {
+	ctx, span := tracer.Start(ctx, "VendingService.GetAccount")
+	defer func() { tracing.End(span, err) }()
+
 	account, err := v.Repository.GetAccount(ctx, username)
 	if err != nil {
 		return nil, err
 	}
 
 	return &vending.Response{
-		Deposit:  change.New(v.Coins, account.Deposit),
+		Deposit:  change.New(v.Coins.Coins(), account.Deposit),
 		Products: account.Products,
 		Spent:    account.Spent,
 	}, nil
 }
+
+func (v VendingService) Deposit(ctx context.Context, username string, coins change.Deposit) (err error) {
+	ctx, span := tracer.Start(ctx, "VendingService.Deposit")
+	defer func() { tracing.End(span, err) }()
+
+	if err := v.Repository.IncrementDeposit(ctx, username, coins.ToAmount()); err != nil {
+		return err
+	}
+	if v.Recorder != nil {
+		v.Recorder.Deposited(coins)
+	}
+	events.Publish(ctx, v.Events, events.Event{Type: events.Deposit, Key: username})
+
+	return nil
+}
+
+func (v VendingService) SetDeposit(ctx context.Context, username string, deposit int) (err error) {
+	ctx, span := tracer.Start(ctx, "VendingService.SetDeposit")
+	defer func() { tracing.End(span, err) }()
+
+	if err := v.Repository.SetDeposit(ctx, username, deposit); err != nil {
+		return err
+	}
+	events.Publish(ctx, v.Events, events.Event{Type: events.Deposit, Key: username})
+
+	return nil
+}
+
+func (v VendingService) BuyProduct(ctx context.Context, username string, product products.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "VendingService.BuyProduct")
+	defer func() { tracing.End(span, err) }()
+
+	transaction, err := v.Repository.BuyProduct(ctx, username, product)
+	if err != nil {
+		return err
+	}
+	if v.Recorder != nil {
+		v.Recorder.Sold(*transaction)
+	}
+	events.Publish(ctx, v.Events, events.Event{Type: events.Product, Key: product.Name})
+	events.Publish(ctx, v.Events, events.Event{Type: events.Deposit, Key: username})
+
+	return nil
+}
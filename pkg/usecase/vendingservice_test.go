@@ -9,6 +9,7 @@ import (
 	"github.com/artback/mvp/mocks"
 	"github.com/artback/mvp/pkg/change"
 	"github.com/artback/mvp/pkg/coin"
+	"github.com/artback/mvp/pkg/events"
 	"github.com/artback/mvp/pkg/products"
 	"github.com/artback/mvp/pkg/vending"
 	"github.com/golang/mock/gomock"
@@ -93,3 +94,84 @@ func TestVendingService_GetAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestVendingService_BuyProduct(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		transaction *vending.Transaction
+		err         error
+		publish     []events.Event
+	}{
+		{
+			name:        "successful,publishes stock and deposit and records the sale",
+			transaction: &vending.Transaction{ID: 1, ProductName: "cola", Username: "mike", Amount: 1, Price: 25},
+			publish:     []events.Event{{Type: events.Product, Key: "cola"}, {Type: events.Deposit, Key: "mike"}},
+		},
+		{
+			name: "unsuccessful,publishes nothing",
+			err:  vending.InsufficientFundsError{Cost: 25, Deposit: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			product := products.Product{Name: "cola", Amount: 1}
+			r := mocks.NewVendingRepsitory(mockCtrl)
+			r.EXPECT().BuyProduct(gomock.Any(), "mike", product).Return(tt.transaction, tt.err).Times(1)
+			e := mocks.NewEventPublisher(mockCtrl)
+			for _, event := range tt.publish {
+				e.EXPECT().Publish(gomock.Any(), event).Return(nil).Times(1)
+			}
+			rec := mocks.NewVendingRecorder(mockCtrl)
+			if tt.transaction != nil {
+				rec.EXPECT().Sold(*tt.transaction).Times(1)
+			}
+			v := VendingService{Repository: r, Events: e, Recorder: rec}
+			if err := v.BuyProduct(context.Background(), "mike", product); !errors.Is(err, tt.err) {
+				t.Errorf("BuyProduct() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVendingService_Deposit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		recorder bool
+		err      error
+		times    int
+	}{
+		{name: "successful,records the coins", recorder: true, times: 1},
+		{name: "successful,without recorder"},
+		{name: "unsuccessful,records nothing", recorder: true, err: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			coins := change.Deposit{5: 2, 100: 1}
+			r := mocks.NewVendingRepsitory(mockCtrl)
+			r.EXPECT().IncrementDeposit(gomock.Any(), "mike", 110).Return(tt.err).Times(1)
+			v := VendingService{Repository: r}
+			if tt.recorder {
+				rec := mocks.NewVendingRecorder(mockCtrl)
+				rec.EXPECT().Deposited(coins).Times(tt.times)
+				v.Recorder = rec
+			}
+			if err := v.Deposit(context.Background(), "mike", coins); !errors.Is(err, tt.err) {
+				t.Errorf("Deposit() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/artback/mvp/pkg/tracing"
+	"github.com/artback/mvp/pkg/webhook"
+)
+
+type WebhookService struct {
+	webhook.Repository
+}
+
+func (w WebhookService) Register(ctx context.Context, sellerID, rawURL string, events []webhook.Kind) (_ *webhook.Endpoint, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Register")
+	defer func() { tracing.End(span, err) }()
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, webhook.InvalidURLErr
+	}
+	if err := webhook.CheckHost(u.Hostname()); err != nil {
+		return nil, err
+	}
+
+	kinds, seen := make([]webhook.Kind, 0, len(events)), make(map[webhook.Kind]bool, len(events))
+	for _, kind := range events {
+		if !known(kind) {
+			return nil, fmt.Errorf("%w: %s", webhook.UnknownKindErr, kind)
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return w.AddEndpoint(ctx, webhook.Endpoint{SellerID: sellerID, URL: u.String(), Events: kinds, Secret: secret})
+}
+
+func known(kind webhook.Kind) bool {
+	for _, k := range webhook.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/golang/mock/gomock"
+)
+
+func TestWebhookService_Register(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		url        string
+		events     []webhook.Kind
+		times      int
+		wantEvents []webhook.Kind
+		wantErr    error
+	}{
+		{name: "every kind", url: "https://seller.example/hook", times: 1, wantEvents: []webhook.Kind{}},
+		{
+			name: "duplicate kinds are dropped", url: "http://seller.example/hook", times: 1,
+			events:     []webhook.Kind{webhook.Sold, webhook.SoldOut, webhook.Sold},
+			wantEvents: []webhook.Kind{webhook.Sold, webhook.SoldOut},
+		},
+		{name: "relative url", url: "/hook", wantErr: webhook.InvalidURLErr},
+		{name: "other scheme", url: "ftp://seller.example/hook", wantErr: webhook.InvalidURLErr},
+		{name: "localhost", url: "http://localhost:7072/reload", wantErr: webhook.ForbiddenHostErr},
+		{name: "private address", url: "http://10.0.0.5/hook", wantErr: webhook.ForbiddenHostErr},
+		{name: "metadata address", url: "http://169.254.169.254/latest/meta-data/", wantErr: webhook.ForbiddenHostErr},
+		{name: "unknown kind", url: "https://seller.example/hook", events: []webhook.Kind{"product.stolen"}, wantErr: webhook.UnknownKindErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewWebhookRepository(mockCtrl)
+			var stored webhook.Endpoint
+			r.EXPECT().AddEndpoint(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, e webhook.Endpoint) (*webhook.Endpoint, error) {
+					stored = e
+					return &e, nil
+				}).Times(tt.times)
+
+			_, err := WebhookService{Repository: r}.Register(context.Background(), "sam", tt.url, tt.events)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if stored.SellerID != "sam" || stored.URL != tt.url || !reflect.DeepEqual(stored.Events, tt.wantEvents) {
+				t.Errorf("Register() stored %+v, want %s for sam with %v", stored, tt.url, tt.wantEvents)
+			}
+			if !strings.HasPrefix(stored.Secret, "whsec_") {
+				t.Errorf("Register() secret = %q, want a generated secret", stored.Secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package users
+
+import "errors"
+
+var (
+	CurrentPasswordRequiredErr = errors.New("current password is required to change the password")
+	CurrentPasswordWrongErr    = errors.New("current password is wrong")
+)
+
+// Patch is a JSON merge patch of a user's own account, a nil field is left unchanged.
+// The role isn't part of it, it only changes through ChangeRole.
+type Patch struct {
+	Password *string `json:"password,omitempty"`
+	// CurrentPassword has to accompany a new Password
+	CurrentPassword *string `json:"current_password,omitempty"`
+	// Contact is cleared by an empty string, the merge patch null
+	Contact *string `json:"contact,omitempty" validate:"max=254"`
+}
+
+// Change is what a checked Patch stores, empty fields are left unchanged
+type Change struct {
+	// PasswordHash replaces the password, pending password resets stop working with it
+	PasswordHash string
+	Contact      *string
+}
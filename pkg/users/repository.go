@@ -2,12 +2,29 @@ package users
 
 import (
 	"context"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
 )
 
 //go:generate mockgen -destination=../../mocks/mock_users_repository.go -mock_names=Repository=UserRepository -package=mocks github.com/artback/mvp/pkg/users Repository
 type Repository interface {
 	Get(ctx context.Context, username string) (*User, error)
 	Insert(ctx context.Context, user User) error
+	// InsertRequestingRole inserts user and files a request for role in one transaction
+	InsertRequestingRole(ctx context.Context, user User, role security.Role) error
 	Update(ctx context.Context, user User) error
 	Delete(ctx context.Context, username string) error
+	SetRole(ctx context.Context, username string, role security.Role) error
+	RequestRole(ctx context.Context, username string, role security.Role) error
+	// ReplacePasswordHash swaps the hash of an unchanged password, it does nothing if the password changed since oldHash was read
+	ReplacePasswordHash(ctx context.Context, username, oldHash, newHash string) error
+	// Apply stores every field of change in one transaction, nothing is stored when one of them fails
+	Apply(ctx context.Context, username string, change Change) error
+	// CreateResetToken stores the hash of a password reset token, earlier tokens of the user stop working
+	CreateResetToken(ctx context.Context, username, tokenHash string, expires time.Time) error
+	// ResetTokenUser returns the user of an unexpired token
+	ResetTokenUser(ctx context.Context, tokenHash string) (string, error)
+	// RedeemResetToken sets the password and uses up the token, unless it expired or was used meanwhile
+	RedeemResetToken(ctx context.Context, tokenHash, passwordHash string) (string, error)
 }
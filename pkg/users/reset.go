@@ -0,0 +1,29 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+var InvalidResetTokenErr = errors.New("password reset token is invalid or expired")
+
+// NewResetToken returns a random token for the user and the hash that is stored in its place
+func NewResetToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashResetToken(token), nil
+}
+
+// HashResetToken needs no salt or stretching, the token is random and long enough not to be guessed
+func HashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
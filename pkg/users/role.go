@@ -0,0 +1,51 @@
+package users
+
+import (
+	"errors"
+	"time"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
+
+var (
+	InvalidRoleErr    = errors.New("invalid role")
+	RoleNotAllowedErr = errors.New("role can not be self assigned")
+)
+
+// Transition tells how a user may move itself from one role to another
+type Transition int
+
+const (
+	Forbidden Transition = iota
+	Immediate
+	NeedsApproval
+)
+
+// transitions is the self-service role policy, anything not listed is forbidden.
+// Admins assign roles through the admin endpoints and are not bound by it.
+var transitions = map[security.Role]map[security.Role]Transition{
+	security.Buyer:  {security.Seller: NeedsApproval},
+	security.Seller: {security.Buyer: Immediate},
+}
+
+// SelfService returns how a user with role from may change itself to role to
+func SelfService(from, to security.Role) Transition {
+	return transitions[from][to]
+}
+
+type RoleRequestStatus string
+
+const (
+	Pending  RoleRequestStatus = "pending"
+	Approved RoleRequestStatus = "approved"
+	Rejected RoleRequestStatus = "rejected"
+)
+
+// RoleRequest is a role change waiting for an admin decision
+type RoleRequest struct {
+	ID        int               `json:"id"`
+	Username  string            `json:"username"`
+	Role      security.Role     `json:"role"`
+	Status    RoleRequestStatus `json:"status"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
@@ -1,12 +1,29 @@
 package users
 
-import "context"
+import (
+	"context"
+
+	"github.com/artback/mvp/pkg/api/middleware/security"
+)
 
 //go:generate mockgen -destination=../../mocks/mock_users_service.go -mock_names=Service=UserService -package=mocks github.com/artback/mvp/pkg/users Service
 type Service interface {
 	GetResponse(ctx context.Context, username string) (*Response, error)
 	Get(ctx context.Context, username string) (*User, error)
 	Insert(ctx context.Context, user User) error
-	Update(ctx context.Context, user User) error
+	// Patch applies a merge patch to the account of username
+	Patch(ctx context.Context, username string, patch Patch) error
 	Delete(ctx context.Context, username string) error
+	ChangeRole(ctx context.Context, username string, role security.Role) (Transition, error)
+	// RehashPassword upgrades the stored hash of a verified password if it was made with an outdated scheme
+	RehashPassword(ctx context.Context, user User, password string) error
+	// RequestPasswordReset sends a reset token to the contact of username, it doesn't tell whether the user exists
+	RequestPasswordReset(ctx context.Context, username string) error
+	// ResetPassword sets the password of the user a reset token was sent to
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+// Invalidator forgets what is cached about a user once its password, role or account changed
+type Invalidator interface {
+	Invalidate(username string)
 }
@@ -6,14 +6,20 @@ import (
 )
 
 type User struct {
-	Username string        `json:"username" binding:"required"`
-	Password string        `json:"password" binding:"required"`
-	Role     security.Role `json:"role"  binding:"required"`
-	Deposit  int           `json:"deposit" binding:"required"`
+	Username string        `json:"username" validate:"required,max=64"`
+	Password string        `json:"password" validate:"required"`
+	Role     security.Role `json:"role"`
+	Deposit  int           `json:"deposit"`
+	// Contact is where password resets are delivered, optional
+	Contact string `json:"contact,omitempty" validate:"max=254"`
+	// Locked and ResetRequired are managed by admins and never read from requests
+	Locked        bool `json:"-"`
+	ResetRequired bool `json:"-"`
 }
 
 type Response struct {
-	Username string         `json:"username" binding:"required"`
-	Role     security.Role  `json:"role"  binding:"required"`
-	Deposit  change.Deposit `json:"deposit" binding:"required"`
+	Username string         `json:"username"`
+	Role     security.Role  `json:"role"`
+	Deposit  change.Deposit `json:"deposit"`
+	Contact  string         `json:"contact,omitempty"`
 }
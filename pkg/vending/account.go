@@ -15,3 +15,12 @@ type Response struct {
 	Products []products.Product `json:"products,omitempty"`
 	Spent    int                `json:"spent"`
 }
+
+// Transaction is a single purchase, Price is the unit price at the time of buying
+type Transaction struct {
+	ID          int    `json:"id"`
+	ProductName string `json:"productName"`
+	Username    string `json:"username"`
+	Amount      int    `json:"amount"`
+	Price       int    `json:"price"`
+}
@@ -0,0 +1,29 @@
+package vending
+
+import "fmt"
+
+// InsufficientFundsError is returned when a purchase costs more than the deposit of the buyer
+type InsufficientFundsError struct {
+	Cost    int `json:"cost"`
+	Deposit int `json:"deposit"`
+}
+
+// Shortfall is what the buyer has to deposit before the purchase succeeds
+func (i InsufficientFundsError) Shortfall() int {
+	return i.Cost - i.Deposit
+}
+
+func (i InsufficientFundsError) Error() string {
+	return fmt.Sprintf("cost %d is higher than deposit %d", i.Cost, i.Deposit)
+}
+
+// OutOfStockError is returned when more of a product is bought than is left in the machine
+type OutOfStockError struct {
+	Product   string `json:"product"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
+
+func (o OutOfStockError) Error() string {
+	return fmt.Sprintf("%d of %s requested, %d available", o.Requested, o.Product, o.Available)
+}
@@ -0,0 +1,13 @@
+package vending
+
+import "github.com/artback/mvp/pkg/change"
+
+//go:generate mockgen -destination=../../mocks/mock_vending_recorder.go -mock_names=Recorder=VendingRecorder -package=mocks github.com/artback/mvp/pkg/vending Recorder
+
+// Recorder is told about the money going through the machine, e.g. to export it as metrics
+type Recorder interface {
+	// Deposited counts the coins a buyer put in
+	Deposited(coins change.Deposit)
+	// Sold counts a purchase, at the price it was sold at
+	Sold(t Transaction)
+}
@@ -10,6 +10,8 @@ import (
 type Repository interface {
 	IncrementDeposit(ctx context.Context, username string, deposit int) error
 	GetAccount(ctx context.Context, username string) (*Account, error)
-	BuyProduct(ctx context.Context, username string, product products.Product) error
+	// BuyProduct returns the transaction of the purchase, with the price it was sold at
+	BuyProduct(ctx context.Context, username string, product products.Product) (*Transaction, error)
 	SetDeposit(ctx context.Context, username string, deposit int) error
+	GetTransaction(ctx context.Context, id int) (*Transaction, error)
 }
@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/artback/mvp/pkg/backoff"
+	"github.com/artback/mvp/pkg/logger"
+)
+
+// Retry decides when a failed delivery is tried again.
+// The wait doubles after every failure, starting at BaseDelay and capped at MaxDelay,
+// a delivery that failed MaxAttempts times is dead.
+type Retry struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetry = Retry{
+	MaxAttempts: 8,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    6 * time.Hour,
+}
+
+// Delay is the wait after the given number of failed attempts
+func (r Retry) Delay(attempts int) time.Duration {
+	return backoff.Exponential(r.BaseDelay, r.MaxDelay, attempts)
+}
+
+// Dispatcher delivers the deliveries written to the outbox. Every replica can run one,
+// claims keep them from sending the same delivery twice at once.
+type Dispatcher struct {
+	Repository Repository
+	// Client should have a timeout well below Lease, or a slow receiver is sent the delivery again
+	Client   *http.Client
+	Retry    Retry
+	Batch    int
+	Interval time.Duration
+	Lease    time.Duration
+}
+
+// Run dispatches every Interval until ctx is done, a full batch is followed right away by the next one
+func (d Dispatcher) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		sent, err := d.Dispatch(ctx)
+		if err != nil {
+			logger.From(ctx).WithError(err).Error("dispatch webhooks")
+		}
+		if sent == d.Batch && err == nil {
+			timer.Reset(0)
+			continue
+		}
+		timer.Reset(d.Interval)
+	}
+}
+
+// Dispatch sends one batch of due deliveries at once and returns how many it claimed
+func (d Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	jobs, err := d.Repository.Claim(ctx, d.Batch, d.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			d.record(ctx, job, d.send(ctx, job))
+		}(job)
+	}
+	wg.Wait()
+
+	return len(jobs), nil
+}
+
+// send returns the attempt through a named result, the deferred duration has to land in what the caller gets
+func (d Dispatcher) send(ctx context.Context, job Job) (attempt Attempt) {
+	start := time.Now()
+	attempt = Attempt{AttemptedAt: start}
+	defer func() {
+		attempt.Duration = time.Since(start).Milliseconds()
+	}()
+
+	body, err := json.Marshal(job.Event)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mvp-webhooks")
+	req.Header.Set(IDHeader, strconv.Itoa(job.Event.ID))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(start.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(job.Secret, start, body))
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer res.Body.Close()
+	// drained so the connection is reused, receivers have nothing to tell beyond the status
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	attempt.StatusCode = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", res.StatusCode)
+	}
+
+	return attempt
+}
+
+func (d Dispatcher) record(ctx context.Context, job Job, attempt Attempt) {
+	status, next := Delivered, time.Time{}
+	if attempt.Error != "" {
+		attempts := job.Attempts + 1
+		status, next = Pending, attempt.AttemptedAt.Add(d.Retry.Delay(attempts))
+		if attempts >= d.Retry.MaxAttempts {
+			status = Dead
+		}
+	}
+
+	if err := d.Repository.Record(ctx, job.DeliveryID, attempt, status, next); err != nil {
+		logger.From(ctx).WithError(err).WithField("delivery_id", job.DeliveryID).Error("record webhook delivery")
+	}
+}
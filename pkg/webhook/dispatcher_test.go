@@ -0,0 +1,113 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/mocks"
+	"github.com/artback/mvp/pkg/webhook"
+	"github.com/golang/mock/gomock"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	retry := webhook.Retry{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+	tests := []struct {
+		name     string
+		status   int
+		attempts int
+		// unreachable sends to a closed receiver
+		unreachable bool
+		// wait is how long the receiver takes to answer
+		wait      time.Duration
+		want      webhook.Status
+		wantDelay time.Duration
+		wantCode  int
+	}{
+		{name: "delivered", status: http.StatusNoContent, want: webhook.Delivered, wantCode: http.StatusNoContent},
+		{name: "first failure is retried", status: http.StatusInternalServerError, want: webhook.Pending, wantDelay: time.Minute, wantCode: http.StatusInternalServerError},
+		{name: "retries back off", status: http.StatusBadGateway, attempts: 1, want: webhook.Pending, wantDelay: 2 * time.Minute, wantCode: http.StatusBadGateway},
+		{name: "last failure is dead", status: http.StatusInternalServerError, attempts: 2, want: webhook.Dead, wantCode: http.StatusInternalServerError},
+		{name: "unreachable receiver is retried", unreachable: true, want: webhook.Pending, wantDelay: time.Minute},
+		{name: "slow receiver is timed", status: http.StatusOK, wait: 50 * time.Millisecond, want: webhook.Delivered, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			event := webhook.Event{ID: 7, Kind: webhook.Sold, Data: json.RawMessage(`{"product":"cola","amount":1}`), CreatedAt: time.Now()}
+			received := make(chan *http.Request, 1)
+			bodies := make(chan []byte, 1)
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				received <- r
+				bodies <- body
+				time.Sleep(tt.wait)
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+			if tt.unreachable {
+				receiver.Close()
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			r := mocks.NewWebhookRepository(mockCtrl)
+			r.EXPECT().Claim(gomock.Any(), 10, time.Minute).Return([]webhook.Job{
+				{DeliveryID: 3, Attempts: tt.attempts, Event: event, URL: receiver.URL, Secret: "secret"},
+			}, nil)
+			r.EXPECT().Record(gomock.Any(), 3, gomock.Any(), tt.want, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ int, attempt webhook.Attempt, _ webhook.Status, next time.Time) error {
+					if attempt.StatusCode != tt.wantCode || (attempt.Error == "") != (tt.want == webhook.Delivered) {
+						t.Errorf("Record() attempt = %+v, want status code %d", attempt, tt.wantCode)
+					}
+					if attempt.Duration < tt.wait.Milliseconds() {
+						t.Errorf("Record() attempt took %dms, want at least %v", attempt.Duration, tt.wait)
+					}
+					if tt.want == webhook.Pending && next.Sub(attempt.AttemptedAt) != tt.wantDelay {
+						t.Errorf("Record() next attempt after %v, want %v", next.Sub(attempt.AttemptedAt), tt.wantDelay)
+					}
+					return nil
+				})
+
+			d := webhook.Dispatcher{Repository: r, Client: &http.Client{Timeout: time.Second}, Retry: retry, Batch: 10, Lease: time.Minute}
+			if sent, err := d.Dispatch(context.Background()); err != nil || sent != 1 {
+				t.Fatalf("Dispatch() = %v, %v, want 1 delivery", sent, err)
+			}
+			if tt.unreachable {
+				return
+			}
+
+			req, body := <-received, <-bodies
+			if !webhook.Verify("secret", req.Header.Get(webhook.SignatureHeader), req.Header.Get(webhook.TimestampHeader), body, time.Minute) {
+				t.Errorf("delivery signature %q does not verify", req.Header.Get(webhook.SignatureHeader))
+			}
+			if req.Header.Get(webhook.IDHeader) != "7" {
+				t.Errorf("delivery id header = %q, want the event id 7", req.Header.Get(webhook.IDHeader))
+			}
+			var got webhook.Event
+			if err := json.Unmarshal(body, &got); err != nil || got.ID != 7 || got.Kind != webhook.Sold {
+				t.Errorf("delivery body = %s, want the event", body)
+			}
+		})
+	}
+}
+
+func TestRetry_Delay(t *testing.T) {
+	t.Parallel()
+
+	retry := webhook.Retry{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := retry.Delay(attempts); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ForbiddenHostErr refuses urls that would make the service call into its own network
+var ForbiddenHostErr = fmt.Errorf("%w: the host is local, private or link-local", InvalidURLErr)
+
+// reserved are the ranges net.IP has no method for: "this network" and carrier-grade NAT, where some clouds
+// serve their metadata
+var reserved = []*net.IPNet{
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+}
+
+// metadataHosts are the names cloud metadata services answer on
+var metadataHosts = map[string]bool{"metadata": true, "metadata.google.internal": true}
+
+// Forbidden reports whether ip is loopback, private, link-local, which includes the metadata address
+// 169.254.169.254, or otherwise not a public unicast address
+func Forbidden(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || !ip.IsGlobalUnicast() {
+		return true
+	}
+	for _, n := range reserved {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckHost refuses host when it is a forbidden address or a local or metadata name.
+// Names are resolved only when dialing, NewClient checks the addresses they resolve to then.
+func CheckHost(host string) error {
+	name := strings.TrimSuffix(strings.ToLower(host), ".")
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") || metadataHosts[name] {
+		return ForbiddenHostErr
+	}
+	if ip := net.ParseIP(name); ip != nil && Forbidden(ip) {
+		return ForbiddenHostErr
+	}
+
+	return nil
+}
+
+// NewClient returns a client that refuses to connect to forbidden addresses, whatever a name resolves to
+// or a redirect points at. It never uses a proxy, the proxy would connect on its behalf.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// dialControl runs after name resolution, right before connecting to address
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || Forbidden(ip) {
+		return fmt.Errorf("%w: %s", ForbiddenHostErr, host)
+	}
+
+	return nil
+}
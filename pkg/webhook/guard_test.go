@@ -0,0 +1,77 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/artback/mvp/pkg/webhook"
+)
+
+func TestCheckHost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		host    string
+		wantErr error
+	}{
+		{host: "seller.example"},
+		{host: "93.184.216.34"},
+		{host: "2606:2800:220:1:248:1893:25c8:1946"},
+		{host: "localhost", wantErr: webhook.ForbiddenHostErr},
+		{host: "api.LOCALHOST.", wantErr: webhook.ForbiddenHostErr},
+		{host: "metadata.google.internal", wantErr: webhook.ForbiddenHostErr},
+		{host: "127.0.0.1", wantErr: webhook.ForbiddenHostErr},
+		{host: "::1", wantErr: webhook.ForbiddenHostErr},
+		{host: "0.0.0.0", wantErr: webhook.ForbiddenHostErr},
+		{host: "10.1.2.3", wantErr: webhook.ForbiddenHostErr},
+		{host: "172.16.0.1", wantErr: webhook.ForbiddenHostErr},
+		{host: "192.168.1.1", wantErr: webhook.ForbiddenHostErr},
+		{host: "169.254.169.254", wantErr: webhook.ForbiddenHostErr},
+		{host: "100.100.100.200", wantErr: webhook.ForbiddenHostErr},
+		{host: "fd00:ec2::254", wantErr: webhook.ForbiddenHostErr},
+		{host: "fe80::1", wantErr: webhook.ForbiddenHostErr},
+		{host: "::ffff:127.0.0.1", wantErr: webhook.ForbiddenHostErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.host, func(t *testing.T) {
+			t.Parallel()
+			if err := webhook.CheckHost(tt.host); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckHost() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer receiver.Close()
+	_, port, _ := net.SplitHostPort(receiver.Listener.Addr().String())
+
+	// a name resolving to loopback passes registration, the dialer still refuses it
+	for _, url := range []string{receiver.URL, "http://localhost:" + port} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := webhook.NewClient(time.Second).Do(req)
+		if err == nil {
+			res.Body.Close()
+		}
+		if !errors.Is(err, webhook.ForbiddenHostErr) {
+			t.Errorf("Do(%s) error = %v, want ForbiddenHostErr", url, err)
+		}
+	}
+	if called {
+		t.Error("the loopback receiver was called")
+	}
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/mock_webhook_repository.go -mock_names=Repository=WebhookRepository -package=mocks github.com/artback/mvp/pkg/webhook Repository
+type Repository interface {
+	AddEndpoint(ctx context.Context, endpoint Endpoint) (*Endpoint, error)
+	Endpoints(ctx context.Context, sellerID string) ([]Endpoint, error)
+	DeleteEndpoint(ctx context.Context, sellerID string, id int) error
+	// Deliveries of an endpoint of the seller, newest first, an empty status lists every status
+	Deliveries(ctx context.Context, sellerID string, endpointID int, status Status) ([]Delivery, error)
+	// Redeliver makes a dead delivery of the seller pending again with fresh attempts
+	Redeliver(ctx context.Context, sellerID string, id int) error
+	// Claim hands out up to limit due deliveries and hides them from other claims for lease
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+	// Record stores an attempt with the resulting status, a pending delivery is due again at next
+	Record(ctx context.Context, deliveryID int, attempt Attempt, status Status, next time.Time) error
+}
@@ -0,0 +1,12 @@
+package webhook
+
+import "context"
+
+//go:generate mockgen -destination=../../mocks/mock_webhook_service.go -mock_names=Service=WebhookService -package=mocks github.com/artback/mvp/pkg/webhook Service
+type Service interface {
+	Register(ctx context.Context, sellerID, url string, events []Kind) (*Endpoint, error)
+	Endpoints(ctx context.Context, sellerID string) ([]Endpoint, error)
+	DeleteEndpoint(ctx context.Context, sellerID string, id int) error
+	Deliveries(ctx context.Context, sellerID string, endpointID int, status Status) ([]Delivery, error)
+	Redeliver(ctx context.Context, sellerID string, id int) error
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp time.Time
+		body      []byte
+		want      bool
+	}{
+		{name: "valid", secret: "secret", timestamp: now, body: body, want: true},
+		{name: "wrong secret", secret: "other", timestamp: now, body: body},
+		{name: "changed body", secret: "secret", timestamp: now, body: []byte(`{"id":2}`)},
+		{name: "changed timestamp", secret: "secret", timestamp: now.Add(time.Second), body: body},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Verify(tt.secret, signature, strconv.FormatInt(tt.timestamp.Unix(), 10), tt.body, time.Minute); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify_Replayed(t *testing.T) {
+	t.Parallel()
+
+	old := time.Now().Add(-time.Hour)
+	body := []byte(`{"id":1}`)
+	if Verify("secret", Sign("secret", old, body), strconv.FormatInt(old.Unix(), 10), body, 5*time.Minute) {
+		t.Error("Verify() accepted a delivery older than the tolerance")
+	}
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Kind names what happened, endpoints subscribe to kinds
+type Kind string
+
+const (
+	// Sold is written for every purchase of a product of the seller
+	Sold Kind = "product.sold"
+	// SoldOut and LowStock are written with the stock alerts of the same kinds, by the check_stock trigger
+	// of db/init.sql
+	SoldOut  Kind = "product.sold_out"
+	LowStock Kind = "product.low_stock"
+)
+
+// Kinds are all kinds an endpoint can subscribe to.
+// There is no refunded kind on purpose: the api can't refund a sale, and /reset only pays back the unspent deposit
+// of a buyer, which concerns no seller. A refund flow adds its kind here and writes it in its own transaction.
+var Kinds = []Kind{Sold, SoldOut, LowStock}
+
+var (
+	InvalidURLErr  = errors.New("url must be an absolute http or https url")
+	UnknownKindErr = errors.New("unknown event kind")
+)
+
+// Endpoint receives the events of one seller, Events empty means every kind.
+// Secret signs the deliveries, it is only shown when the endpoint is registered.
+type Endpoint struct {
+	ID        int       `json:"id"`
+	SellerID  string    `json:"sellerId"`
+	URL       string    `json:"url"`
+	Events    []Kind    `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Wants reports whether the endpoint subscribed to kind
+func (e Endpoint) Wants(kind Kind) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, k := range e.Events {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is a row of the outbox, written in the transaction of the change it describes
+type Event struct {
+	ID        int             `json:"id"`
+	SellerID  string          `json:"-"`
+	Kind      Kind            `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// SoldData is the data of a Sold event
+type SoldData struct {
+	Product       string `json:"product"`
+	Amount        int    `json:"amount"`
+	Price         int    `json:"price"`
+	Remaining     int    `json:"remaining"`
+	TransactionID int    `json:"transactionId"`
+}
+
+// SoldOutData is the data of a SoldOut event
+type SoldOutData struct {
+	Product string `json:"product"`
+}
+
+// LowStockData is the data of a LowStock event
+type LowStockData struct {
+	Product   string `json:"product"`
+	Remaining int    `json:"remaining"`
+	Threshold int    `json:"threshold"`
+}
+
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Delivered Status = "delivered"
+	// Dead deliveries ran out of attempts, they stay listed for the seller to inspect
+	Dead Status = "dead"
+)
+
+// Delivery is one event on its way to one endpoint
+type Delivery struct {
+	ID          int        `json:"id"`
+	EndpointID  int        `json:"endpointId"`
+	Event       Event      `json:"event"`
+	Status      Status     `json:"status"`
+	Attempts    []Attempt  `json:"attempts"`
+	NextAttempt *time.Time `json:"nextAttemptAt,omitempty"`
+}
+
+// Attempt is one try to deliver, StatusCode is 0 when no response arrived
+type Attempt struct {
+	StatusCode  int       `json:"statusCode,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Duration    int64     `json:"durationMs"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+// Job is a claimed delivery with what the dispatcher needs to send it
+type Job struct {
+	DeliveryID int
+	Attempts   int
+	Event      Event
+	URL        string
+	Secret     string
+}